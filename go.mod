@@ -19,11 +19,13 @@ require (
 	github.com/likexian/whois-parser v1.24.19
 	github.com/praetorian-inc/goffloader v0.0.0-20240726182937-53a9ba88982e
 	github.com/spf13/cobra v1.8.1
+	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.19.0
 	golang.org/x/net v0.28.0
 	golang.org/x/sync v0.8.0
 	gopkg.in/ini.v1 v1.67.0
 	gopkg.in/ns1/ns1-go.v2 v2.12.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -62,7 +64,6 @@ require (
 	github.com/sourcegraph/conc v0.3.0 // indirect
 	github.com/spf13/afero v1.11.0 // indirect
 	github.com/spf13/cast v1.6.0 // indirect
-	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/subosito/gotenv v1.6.0 // indirect
 	github.com/temoto/robotstxt v1.1.1 // indirect
 	go.uber.org/atomic v1.9.0 // indirect
@@ -72,5 +73,4 @@ require (
 	golang.org/x/text v0.17.0 // indirect
 	google.golang.org/appengine v1.6.8 // indirect
 	google.golang.org/protobuf v1.33.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -20,8 +20,10 @@ var Client *sdk.Chariot
 func init() {
 	accountCmd.AddCommand(
 		addCmd,
+		auditCmd,
 		deleteCmd,
 		listCmd,
+		pruneCmd,
 	)
 }
 
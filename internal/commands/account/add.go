@@ -1,41 +1,97 @@
 package account
 
 import (
+	"fmt"
 	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
 
 	"github.com/spf13/cobra"
 )
 
+var emailRegexp = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+
 var addCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add a collaborator to your account",
 	Long: `Invite a collaborator to your account. This allows them to assume
 access into your account and perform actions on your behalf.
 
+The --role is recorded on the link as a label for audits, such as read-only for
+an auditor. It is not enforced: every collaborator can act with full access.
+Access can be made temporary with --expires, given as a duration such as 12h,
+30d or 6w, or as a date such as 2025-01-31. Expired collaborators are removed by
+chariot account prune.
+
 Example Usages:
   chariot account add --email research@praetorian.com
+  chariot account add --email auditor@acme.com --role read-only --expires 30d
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		email, _ := cmd.Flags().GetString("email")
-		emailRegexp := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+		role, _ := cmd.Flags().GetString("role")
+		expiresIn, _ := cmd.Flags().GetString("expires")
+
 		if !emailRegexp.MatchString(email) {
 			cmd.PrintErrf("Failed to add account: invalid email address, %s\n", email)
 			return
 		}
+		if !slices.Contains(model.Roles, role) {
+			cmd.PrintErrf("Failed to add account: invalid role %s, expected one of %s\n", role, strings.Join(model.Roles, ", "))
+			return
+		}
+
+		var expires time.Time
+		if expiresIn != "" {
+			var err error
+			expires, err = parseExpiry(expiresIn, time.Now())
+			if err != nil {
+				cmd.PrintErrf("Failed to add account: %v\n", err)
+				return
+			}
+		}
 
-		account := model.NewAccount(Client.Username, email, "", nil)
+		account := model.NewCollaborator(Client.Username, email, role, expires)
 		err := Client.Accounts.Add(account)
 		if err != nil {
 			cmd.PrintErrf("Failed to add account: %v\n", err)
 			return
 		}
-		cmd.Printf("Linked %s to %s successfully\n", email, Client.Username)
+		if expires.IsZero() {
+			cmd.Printf("Linked %s to %s successfully\n", email, Client.Username)
+			return
+		}
+		cmd.Printf("Linked %s to %s successfully until %s\n", email, Client.Username, expires.UTC().Format(time.RFC3339))
 	},
 }
 
+// parseExpiry accepts Go durations, whole days or weeks (30d, 6w) and dates
+func parseExpiry(value string, now time.Time) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			if !t.After(now) {
+				return time.Time{}, fmt.Errorf("expiry %s is in the past", value)
+			}
+			return t, nil
+		}
+	}
+
+	duration, err := model.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiry %q, expected a duration such as 30d or a date", value)
+	}
+	if duration <= 0 {
+		return time.Time{}, fmt.Errorf("expiry %s must be in the future", value)
+	}
+	return now.Add(duration), nil
+}
+
 func init() {
 	addCmd.Flags().String("email", "", "Email of the account (required)")
+	addCmd.Flags().String("role", model.RoleAdmin, "Role label recorded for the collaborator, not enforced - can be admin or read-only")
+	addCmd.Flags().String("expires", "", "Remove access after this duration (e.g., 30d) or on this date")
 	addCmd.MarkFlagRequired("email")
 }
@@ -0,0 +1,95 @@
+package account
+
+import (
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Review the collaborators of your account",
+	Long: `List every collaborator of your account with their role label, when their link
+was last updated, and when their access expires.
+
+Example Usages:
+  chariot account audit
+  chariot account audit --expired`,
+	Run: func(cmd *cobra.Command, args []string) {
+		expiredOnly, _ := cmd.Flags().GetBool("expired")
+
+		collaborators, err := listCollaborators()
+		if err != nil {
+			cmd.PrintErrf("Failed to list accounts: %v\n", err)
+			return
+		}
+
+		now := time.Now()
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "MEMBER\tROLE\tUPDATED\tEXPIRES")
+		for _, account := range collaborators {
+			if expiredOnly && !account.Expired(now) {
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", account.Member, account.Role(), updated(account, now), expiry(account, now))
+		}
+		w.Flush()
+	},
+}
+
+// listCollaborators returns the members of the current account, least recently
+// updated first
+func listCollaborators() ([]model.Account, error) {
+	accounts, err := Client.Accounts.List()
+	if err != nil {
+		return nil, err
+	}
+	collaborators := make([]model.Account, 0)
+	for _, account := range accounts {
+		if account.Name == Client.Username && emailRegexp.MatchString(account.Member) {
+			collaborators = append(collaborators, account)
+		}
+	}
+	sort.Slice(collaborators, func(i, j int) bool { return collaborators[i].Updated < collaborators[j].Updated })
+	return collaborators, nil
+}
+
+func updated(account model.Account, now time.Time) string {
+	updated, err := time.Parse(time.RFC3339, account.Updated)
+	if err != nil {
+		return "unknown"
+	}
+	return age(now.Sub(updated)) + " ago"
+}
+
+func expiry(account model.Account, now time.Time) string {
+	expires, ok := account.Expires()
+	switch {
+	case !ok:
+		return "never"
+	case account.Expired(now):
+		return "expired " + age(now.Sub(expires)) + " ago"
+	default:
+		return "in " + age(expires.Sub(now))
+	}
+}
+
+func age(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+}
+
+func init() {
+	auditCmd.Flags().Bool("expired", false, "Only list collaborators whose access has expired")
+}
@@ -0,0 +1,59 @@
+package account
+
+import (
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+
+	"github.com/spf13/cobra"
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove collaborators whose access has expired",
+	Long: `Unlink every collaborator added with --expires whose access has expired.
+
+Example Usages:
+  chariot account prune --dry-run
+  chariot account prune`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		collaborators, err := listCollaborators()
+		if err != nil {
+			cmd.PrintErrf("Failed to list accounts: %v\n", err)
+			return
+		}
+
+		now := time.Now()
+		expired := make([]model.Account, 0)
+		for _, account := range collaborators {
+			if account.Expired(now) {
+				expired = append(expired, account)
+			}
+		}
+
+		if len(expired) == 0 {
+			cmd.Println("No expired collaborators")
+			return
+		}
+		if dryRun {
+			for _, account := range expired {
+				cmd.Printf("Would unlink %s (%s)\n", account.Member, expiry(account, now))
+			}
+			return
+		}
+
+		for _, result := range Client.Accounts.DeleteMany(expired).Results {
+			if result.Err != nil {
+				cmd.PrintErrf("Failed to unlink %s: %v\n", result.Item.Member, result.Err)
+				continue
+			}
+			cmd.Printf("Unlinked %s from %s successfully\n", result.Item.Member, Client.Username)
+		}
+	},
+}
+
+func init() {
+	pruneCmd.Flags().Bool("dry-run", false, "Show the collaborators that would be removed")
+}
@@ -0,0 +1,81 @@
+package apply
+
+import (
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/surface"
+
+	"github.com/spf13/cobra"
+)
+
+var Client *sdk.Chariot
+
+func Cmd(client *sdk.Chariot) *cobra.Command {
+	Client = client
+	return applyCmd
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply a surface file to your account",
+	Long: `Make the changes shown by chariot plan, so that your account matches the seed
+assets, collaborators and integrations declared in the surface file. Applying
+the same file again makes no changes. The declared items are recorded in the
+state file, and --prune deletes the recorded items that were removed from the
+surface file. See chariot plan --help for the format.
+
+Example Usages:
+  chariot apply -f surface.yaml
+  chariot apply -f surface.yaml --prune`,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, _ := cmd.Flags().GetString("file")
+		prune, _ := cmd.Flags().GetBool("prune")
+		statePath, _ := cmd.Flags().GetString("state")
+		if statePath == "" {
+			statePath = surface.StatePath(path)
+		}
+
+		file, err := surface.Load(path)
+		if err != nil {
+			cmd.PrintErrf("Failed to load surface file: %v\n", err)
+			return
+		}
+		state, err := surface.LoadState(statePath)
+		if err != nil {
+			cmd.PrintErrf("Failed to load state file: %v\n", err)
+			return
+		}
+
+		plan, err := surface.Build(Client, file, state, prune)
+		if err != nil {
+			cmd.PrintErrf("Failed to plan changes: %v\n", err)
+			return
+		}
+
+		errs := plan.Apply(Client)
+		if err := surface.NewState(file, state, plan, errs).Save(statePath); err != nil {
+			cmd.PrintErrf("Failed to save state file: %v\n", err)
+		}
+
+		if len(plan.Changes) == 0 {
+			cmd.Println("No changes. Your account matches the surface file.")
+			return
+		}
+
+		failed := 0
+		for i, err := range errs {
+			if err != nil {
+				failed++
+				cmd.PrintErrf("Failed to apply %s: %v\n", plan.Changes[i], err)
+				continue
+			}
+			cmd.Println(plan.Changes[i])
+		}
+		cmd.Printf("Applied %d of %d changes\n", len(plan.Changes)-failed, len(plan.Changes))
+	},
+}
+
+func init() {
+	applyCmd.Flags().StringP("file", "f", "surface.yaml", "Surface file declaring assets, collaborators and integrations")
+	applyCmd.Flags().Bool("prune", false, "Remove items recorded in the state file that are no longer in the surface file")
+	applyCmd.Flags().String("state", "", "State file of the items apply manages (default is <file>.state.json)")
+}
@@ -23,6 +23,7 @@ func init() {
 		updateCmd,
 		deleteCmd,
 		listCmd,
+		graphCmd,
 	)
 }
 
@@ -1,6 +1,7 @@
 package asset
 
 import (
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
 	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
 
 	"github.com/spf13/cobra"
@@ -9,30 +10,50 @@ import (
 var deleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete an asset",
-	Long: `Specify the key of the asset to delete with --key. This will flag the asset as
-deleted in the Chariot database and prevent further scanning.
+	Long: `Specify the key of the asset to delete with --key, or a file with one key per
+line with --keys-file (- reads from stdin). This will flag the asset as deleted
+in the Chariot database and prevent further scanning.
 
 Example Usages:
-  chariot asset delete --key #asset#example.com#1.2.3.4`,
+  chariot asset delete --key #asset#example.com#1.2.3.4
+  chariot asset delete --keys-file stale-assets.txt`,
 	Run: func(cmd *cobra.Command, args []string) {
 		key, _ := cmd.Flags().GetString("key")
+		keysFile, _ := cmd.Flags().GetString("keys-file")
 
-		asset, err := model.GetAssetFromKey(key)
-		if err != nil {
-			cmd.PrintErrf("Failed to get asset from key: %v\n", err)
-			return
+		keys := []string{key}
+		if keysFile != "" {
+			var err error
+			keys, err = sdk.ReadKeysFile(keysFile)
+			if err != nil {
+				cmd.PrintErrf("Failed to read keys: %v\n", err)
+				return
+			}
 		}
 
-		err = Client.Assets.Delete(*asset)
-		if err != nil {
-			cmd.PrintErrf("Failed to delete asset: %v\n", err)
-			return
+		assets := make([]model.Asset, 0, len(keys))
+		for _, key := range keys {
+			asset, err := model.GetAssetFromKey(key)
+			if err != nil {
+				cmd.PrintErrf("Failed to get asset from key: %v\n", err)
+				return
+			}
+			assets = append(assets, *asset)
+		}
+
+		for _, result := range Client.Assets.DeleteMany(assets).Results {
+			if result.Err != nil {
+				cmd.PrintErrf("Failed to delete asset %s: %v\n", result.Item.Key, result.Err)
+				continue
+			}
+			cmd.Printf("Asset %s deleted successfully\n", result.Item.Key)
 		}
-		cmd.Printf("Asset %s deleted successfully\n", key)
 	},
 }
 
 func init() {
 	deleteCmd.Flags().String("key", "", "Key of the asset to remove")
-	deleteCmd.MarkFlagRequired("key")
+	deleteCmd.Flags().String("keys-file", "", "File with one asset key per line to remove, - for stdin")
+	deleteCmd.MarkFlagsOneRequired("key", "keys-file")
+	deleteCmd.MarkFlagsMutuallyExclusive("key", "keys-file")
 }
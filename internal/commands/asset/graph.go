@@ -0,0 +1,56 @@
+package asset
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Show how an asset relates to its IPs, ports and risks",
+	Long: `Build a relationship graph from your assets, attributes and risks and print
+the subtree rooted at the given asset. Domains link to the IPs they resolve to,
+IPs to their open ports, ports to the protocols running on them and protocols to
+the risks found there.
+
+Supported formats are tree (default), dot (Graphviz) and json.
+
+Example Usages:
+  chariot asset graph --key "#asset#app.acme.com#app.acme.com"
+  chariot asset graph --key app.acme.com --format dot | dot -Tpng -o acme.png
+  chariot asset graph --key app.acme.com --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		key, _ := cmd.Flags().GetString("key")
+		format, _ := cmd.Flags().GetString("format")
+
+		graph, err := Client.Graph()
+		if err != nil {
+			cmd.PrintErrf("Failed to build graph: %v\n", err)
+			return
+		}
+
+		root, ok := graph.Find(key)
+		if !ok {
+			cmd.PrintErrf("Failed to find %s in the graph\n", key)
+			return
+		}
+
+		switch format {
+		case "tree":
+			graph.Tree(cmd.OutOrStdout(), root)
+		case "dot":
+			graph.DOT(cmd.OutOrStdout(), root)
+		case "json":
+			if err := graph.JSON(cmd.OutOrStdout(), root); err != nil {
+				cmd.PrintErrf("Failed to encode graph: %v\n", err)
+			}
+		default:
+			cmd.PrintErrf("Unsupported format: %s\n", format)
+		}
+	},
+}
+
+func init() {
+	graphCmd.Flags().String("key", "", "Key or DNS name of the asset to graph (required)")
+	graphCmd.Flags().String("format", "tree", "Output format - can be tree, dot, or json")
+	graphCmd.MarkFlagRequired("key")
+}
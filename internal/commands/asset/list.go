@@ -1,8 +1,8 @@
 package asset
 
 import (
-	"encoding/json"
-
+	"github.com/praetorian-inc/chariot-client/internal/commands/fanout"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
 	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
 
 	"github.com/spf13/cobra"
@@ -13,37 +13,55 @@ var listCmd = &cobra.Command{
 	Short: "List all assets",
 	Long: `Return the list of all assets in the Chariot database.
 
+With --all-accounts or --accounts, every row is prefixed with its account and
+per-account counts are printed to stderr.
+
 Example Usages:
 	chariot asset list
 	chariot asset list --details
-	chariot asset list --filter "key=example.com"`,
+	chariot asset list --filter "key=example.com"
+	chariot asset list --all-accounts`,
 
 	Run: func(cmd *cobra.Command, args []string) {
 		showDetails, _ := cmd.Flags().GetBool("details")
 		filter, _ := cmd.Flags().GetString("filter")
 
-		assets, err := Client.Assets.List()
+		accounts, err := fanout.Accounts(cmd, Client)
 		if err != nil {
-			cmd.Printf("Failed to list assets: %v\n", err)
+			cmd.Printf("Failed to list accounts: %v\n", err)
 			return
 		}
 
-		if filter != "" {
-			assets = model.FilterAssetsByKey(assets, filter)
-		}
+		var counts fanout.Counts
+		results := sdk.FanOut(Client, accounts, func(client *sdk.Chariot) ([]model.Asset, error) {
+			return client.Assets.List()
+		})
+		for _, result := range results {
+			if result.Err != nil {
+				cmd.Printf("Failed to list assets%s: %v\n", fanout.For(result.Account), result.Err)
+				continue
+			}
+
+			assets := result.Value
+			if filter != "" {
+				assets = model.FilterAssetsByKey(assets, filter)
+			}
 
-		for _, asset := range assets {
-			if showDetails {
-				json, _ := json.Marshal(asset)
-				cmd.Printf("%s\n", json)
-			} else {
-				cmd.Printf("%s\n", asset.Key)
+			for _, asset := range assets {
+				if showDetails {
+					cmd.Printf("%s\n", fanout.JSON(result.Account, asset))
+				} else {
+					cmd.Printf("%s%s\n", fanout.Prefix(result.Account), asset.Key)
+				}
 			}
+			counts.Add(result.Account, len(assets))
 		}
+		counts.Print(cmd, "assets")
 	},
 }
 
 func init() {
 	listCmd.Flags().Bool("details", false, "Show detailed information about each asset")
 	listCmd.Flags().String("filter", "", "Filter the assets list (e.g., --filter 'key=example.com')")
+	fanout.AddFlags(listCmd)
 }
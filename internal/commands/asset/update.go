@@ -3,6 +3,7 @@ package asset
 import (
 	"strings"
 
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
 	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
 
 	"github.com/spf13/cobra"
@@ -12,45 +13,67 @@ var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Update an asset's priority",
 	Long: `Update an asset's priority to comprehensive, standard, discover, or frozen.
+Use --keys-file to update every asset listed in a file, one key per line.
 For example:
-  chariot update asset --priority comprehensive --key #asset#example.com#1.2.3.4`,
+  chariot update asset --priority comprehensive --key #asset#example.com#1.2.3.4
+  chariot update asset --priority frozen --keys-file assets.txt`,
 
 	Run: func(cmd *cobra.Command, args []string) {
 		key := cmd.Flag("key").Value.String()
+		keysFile := cmd.Flag("keys-file").Value.String()
 		priority := cmd.Flag("priority").Value.String()
 
-		asset, err := model.GetAssetFromKey(key)
-		if err != nil {
-			cmd.PrintErrf("Failed to get asset from key: %v\n", err)
-			return
-		}
-
+		var status string
 		switch strings.ToLower(priority) {
 		case "comprehensive":
-			asset.Status = model.ActiveHigh
+			status = model.ActiveHigh
 		case "standard":
-			asset.Status = model.Active
+			status = model.Active
 		case "discover":
-			asset.Status = model.ActiveLow
+			status = model.ActiveLow
 		case "frozen":
-			asset.Status = model.Frozen
+			status = model.Frozen
 		default:
 			cmd.Help()
 			return
 		}
 
-		err = Client.Assets.Update(*asset)
-		if err != nil {
-			cmd.PrintErrf("Failed to update asset: %v\n", err)
-			return
+		keys := []string{key}
+		if keysFile != "" {
+			var err error
+			keys, err = sdk.ReadKeysFile(keysFile)
+			if err != nil {
+				cmd.PrintErrf("Failed to read keys: %v\n", err)
+				return
+			}
+		}
+
+		assets := make([]model.Asset, 0, len(keys))
+		for _, key := range keys {
+			asset, err := model.GetAssetFromKey(key)
+			if err != nil {
+				cmd.PrintErrf("Failed to get asset from key: %v\n", err)
+				return
+			}
+			asset.Status = status
+			assets = append(assets, *asset)
+		}
+
+		for _, result := range Client.Assets.UpdateMany(assets).Results {
+			if result.Err != nil {
+				cmd.PrintErrf("Failed to update asset %s: %v\n", result.Item.Key, result.Err)
+				continue
+			}
+			cmd.Printf("Asset %s updated successfully\n", result.Item.Key)
 		}
-		cmd.Printf("Asset %s updated successfully\n", key)
 	},
 }
 
 func init() {
 	updateCmd.Flags().String("priority", "", "Priority of the asset - can be comprehensive, standard, discover, or frozen (required)")
-	updateCmd.Flags().String("key", "", "Key of the asset (required)")
+	updateCmd.Flags().String("key", "", "Key of the asset")
+	updateCmd.Flags().String("keys-file", "", "File with one asset key per line, - for stdin")
 	updateCmd.MarkFlagRequired("priority")
-	updateCmd.MarkFlagRequired("key")
+	updateCmd.MarkFlagsOneRequired("key", "keys-file")
+	updateCmd.MarkFlagsMutuallyExclusive("key", "keys-file")
 }
@@ -1,6 +1,7 @@
 package attribute
 
 import (
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
 	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
 
 	"github.com/spf13/cobra"
@@ -9,29 +10,49 @@ import (
 var deleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete an attribute",
-	Long: `Specify the key of the attribute to delete with --key.
+	Long: `Specify the key of the attribute to delete with --key, or a file with one key per
+line with --keys-file (- reads from stdin).
 
 Example Usages:
-  chariot attribute delete --key #attribute#technology#HTML Forms#asset#example.com#12.34.56.78`,
+  chariot attribute delete --key #attribute#technology#HTML Forms#asset#example.com#12.34.56.78
+  chariot attribute delete --keys-file attributes.txt`,
 	Run: func(cmd *cobra.Command, args []string) {
 		key, _ := cmd.Flags().GetString("key")
+		keysFile, _ := cmd.Flags().GetString("keys-file")
 
-		attribute, err := model.GetAttributeFromKey(key)
-		if err != nil {
-			cmd.PrintErrf("Failed to get attribute from key: %v\n", err)
-			return
+		keys := []string{key}
+		if keysFile != "" {
+			var err error
+			keys, err = sdk.ReadKeysFile(keysFile)
+			if err != nil {
+				cmd.PrintErrf("Failed to read keys: %v\n", err)
+				return
+			}
 		}
 
-		err = Client.Attributes.Delete(*attribute)
-		if err != nil {
-			cmd.PrintErrf("Failed to delete attribute: %v\n", err)
-			return
+		attributes := make([]model.Attribute, 0, len(keys))
+		for _, key := range keys {
+			attribute, err := model.GetAttributeFromKey(key)
+			if err != nil {
+				cmd.PrintErrf("Failed to get attribute from key: %v\n", err)
+				return
+			}
+			attributes = append(attributes, *attribute)
+		}
+
+		for _, result := range Client.Attributes.DeleteMany(attributes).Results {
+			if result.Err != nil {
+				cmd.PrintErrf("Failed to delete attribute %s: %v\n", result.Item.Key, result.Err)
+				continue
+			}
+			cmd.Printf("Attribute %s deleted successfully\n", result.Item.Key)
 		}
-		cmd.Printf("Attribute %s deleted successfully\n", key)
 	},
 }
 
 func init() {
 	deleteCmd.Flags().String("key", "", "Key of the attribute to remove")
-	deleteCmd.MarkFlagRequired("key")
+	deleteCmd.Flags().String("keys-file", "", "File with one attribute key per line to remove, - for stdin")
+	deleteCmd.MarkFlagsOneRequired("key", "keys-file")
+	deleteCmd.MarkFlagsMutuallyExclusive("key", "keys-file")
 }
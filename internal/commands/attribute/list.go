@@ -1,7 +1,9 @@
 package attribute
 
 import (
-	"encoding/json"
+	"github.com/praetorian-inc/chariot-client/internal/commands/fanout"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
 
 	"github.com/spf13/cobra"
 )
@@ -13,28 +15,42 @@ var listCmd = &cobra.Command{
 
 Example Usages:
 	chariot attribute list
-	chariot attribute list --details`,
+	chariot attribute list --details
+	chariot attribute list --accounts a@acme.com,b@acme.com`,
 	Run: func(cmd *cobra.Command, args []string) {
 		showDetails, _ := cmd.Flags().GetBool("details")
 
-		attributes, err := Client.Attributes.List()
+		accounts, err := fanout.Accounts(cmd, Client)
 		if err != nil {
-			cmd.Printf("Failed to list attributes: %v\n", err)
+			cmd.Printf("Failed to list accounts: %v\n", err)
 			return
 		}
 
-		for _, attribute := range attributes {
-			if showDetails {
-				json, _ := json.Marshal(attribute)
-				cmd.Printf("%s\n", json)
-			} else {
-				cmd.Printf("%s\n", attribute.Key)
+		var counts fanout.Counts
+		results := sdk.FanOut(Client, accounts, func(client *sdk.Chariot) ([]model.Attribute, error) {
+			return client.Attributes.List()
+		})
+		for _, result := range results {
+			if result.Err != nil {
+				cmd.Printf("Failed to list attributes%s: %v\n", fanout.For(result.Account), result.Err)
+				continue
 			}
+
+			for _, attribute := range result.Value {
+				if showDetails {
+					cmd.Printf("%s\n", fanout.JSON(result.Account, attribute))
+				} else {
+					cmd.Printf("%s%s\n", fanout.Prefix(result.Account), attribute.Key)
+				}
+			}
+			counts.Add(result.Account, len(result.Value))
 		}
+		counts.Print(cmd, "attributes")
 	},
 }
 
 func init() {
 	listCmd.Flags().Bool("details", false, "Show detailed information about each asset")
 	// listCmd.Flags().String("filter", "", "Filter the assets list (e.g., --filter 'key=example.com')")
+	fanout.AddFlags(listCmd)
 }
@@ -0,0 +1,168 @@
+package digest
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/digest"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/sla"
+
+	"github.com/spf13/cobra"
+)
+
+// PasswordEnv holds the SMTP password, so it stays out of shell history
+const PasswordEnv = "CHARIOT_SMTP_PASSWORD"
+
+var Client *sdk.Chariot
+
+func Cmd(client *sdk.Chariot) *cobra.Command {
+	Client = client
+	return digestCmd
+}
+
+var digestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Email a summary of new, overdue and remediated risks",
+	Long: `Build a digest of your account and email it as HTML with a plaintext
+alternative. The digest lists the risks created during the period, the open
+risks older than their remediation SLA and counts remediated risks per
+severity. The SLA defaults to 7 days for critical risks, 30 for high, 90 for
+medium and 180 for low; see chariot risk sla for the --policy file format.
+
+With --last-run, the period starts when the file was last written instead of
+--since, and the file is touched after each successful send, so a scheduled
+digest never skips or repeats risks.
+
+The SMTP password is read from the ` + PasswordEnv + ` environment variable.
+With the default --smtp-tls starttls, nothing is sent to a server that does not
+offer STARTTLS.
+Use --dry-run to print the plaintext digest instead of sending it.
+
+Example Usages:
+	chariot digest --since 7d --to security@acme.com --from chariot@acme.com --smtp-host smtp.acme.com
+	chariot digest --since 24h --to alice@acme.com --to bob@acme.com --from chariot@acme.com --smtp-host smtp.acme.com --smtp-user chariot
+	chariot digest --last-run ~/.chariot-digest --to security@acme.com --from chariot@acme.com --smtp-host smtp.acme.com
+	chariot digest --since 30d --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		since, _ := cmd.Flags().GetString("since")
+		lastRun, _ := cmd.Flags().GetString("last-run")
+		policyFile, _ := cmd.Flags().GetString("policy")
+		to, _ := cmd.Flags().GetStringSlice("to")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		period, err := model.ParseDuration(since)
+		if err != nil || period <= 0 {
+			cmd.PrintErrf("Invalid --since %q, expected a duration such as 7d or 12h\n", since)
+			return
+		}
+		now := time.Now().UTC()
+		start := now.Add(-period)
+		if lastRun != "" {
+			if info, err := os.Stat(lastRun); err == nil {
+				start = info.ModTime().UTC()
+			}
+		}
+
+		policy := sla.DefaultPolicy
+		if policyFile != "" {
+			if policy, err = sla.LoadPolicy(policyFile); err != nil {
+				cmd.PrintErrf("Failed to load %s: %v\n", policyFile, err)
+				return
+			}
+		}
+
+		var config digest.SMTPConfig
+		if !dryRun {
+			if len(to) == 0 {
+				cmd.PrintErrf("At least one --to recipient is required\n")
+				return
+			}
+			config, err = smtpConfig(cmd)
+			if err != nil {
+				cmd.PrintErrf("Invalid SMTP settings: %v\n", err)
+				return
+			}
+		}
+
+		d, err := digest.Build(Client, digest.Options{Since: start, Now: now, Policy: &policy})
+		if err != nil {
+			cmd.PrintErrf("Failed to build digest: %v\n", err)
+			return
+		}
+
+		if dryRun {
+			if err := d.Render(cmd.OutOrStdout(), digest.Text); err != nil {
+				cmd.PrintErrf("Failed to render digest: %v\n", err)
+			}
+			return
+		}
+
+		if err := digest.Send(config, to, d); err != nil {
+			cmd.PrintErrf("Failed to send digest: %v\n", err)
+			return
+		}
+		if lastRun != "" {
+			if err := touch(lastRun, now); err != nil {
+				cmd.PrintErrf("Failed to record last run in %s: %v\n", lastRun, err)
+			}
+		}
+		cmd.Printf("Digest sent to %s: %d new, %d past SLA, %d remediated\n", strings.Join(to, ", "), len(d.New), len(d.Breaching), d.Remediated)
+	},
+}
+
+func smtpConfig(cmd *cobra.Command) (digest.SMTPConfig, error) {
+	from, _ := cmd.Flags().GetString("from")
+	host, _ := cmd.Flags().GetString("smtp-host")
+	port, _ := cmd.Flags().GetString("smtp-port")
+	user, _ := cmd.Flags().GetString("smtp-user")
+	mode, _ := cmd.Flags().GetString("smtp-tls")
+
+	config := digest.SMTPConfig{
+		Username: user,
+		Password: os.Getenv(PasswordEnv),
+		From:     from,
+		TLS:      mode,
+	}
+	if host == "" {
+		return config, fmt.Errorf("--smtp-host is required")
+	}
+	if from == "" {
+		return config, fmt.Errorf("--from is required")
+	}
+	if port == "" {
+		port = "587"
+		if mode == digest.ImplicitTLS {
+			port = "465"
+		}
+	}
+	config.Address = net.JoinHostPort(host, port)
+	return config, nil
+}
+
+func touch(path string, at time.Time) error {
+	if err := os.Chtimes(path, at, at); err == nil {
+		return nil
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		return err
+	}
+	return os.Chtimes(path, at, at)
+}
+
+func init() {
+	digestCmd.Flags().String("since", "7d", "Period to summarize, e.g. 7d, 2w or 12h")
+	digestCmd.Flags().String("last-run", "", "File recording the last sent digest; the period starts there when it exists")
+	digestCmd.Flags().String("policy", "", "YAML file with the SLA policy")
+	digestCmd.Flags().StringSlice("to", nil, "Recipient address, repeatable or comma separated")
+	digestCmd.Flags().String("from", "", "Sender address, e.g. \"Chariot <chariot@acme.com>\"")
+	digestCmd.Flags().String("smtp-host", "", "SMTP server host")
+	digestCmd.Flags().String("smtp-port", "", "SMTP server port (default 587, or 465 with --smtp-tls tls)")
+	digestCmd.Flags().String("smtp-user", "", "SMTP username; the password is read from "+PasswordEnv)
+	digestCmd.Flags().String("smtp-tls", digest.StartTLS, "TLS mode - can be starttls, tls or none")
+	digestCmd.Flags().Bool("dry-run", false, "Print the plaintext digest instead of sending it")
+}
@@ -0,0 +1,110 @@
+// Package fanout runs list, search, report and export commands across several
+// accounts with --all-accounts or --accounts.
+package fanout
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+
+	"github.com/spf13/cobra"
+)
+
+func AddFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("all-accounts", false, "Run for every account you are a member of")
+	cmd.Flags().StringSlice("accounts", nil, "Run for these accounts (e.g., --accounts a@acme.com,b@acme.com)")
+	cmd.MarkFlagsMutuallyExclusive("all-accounts", "accounts")
+}
+
+// Accounts returns the accounts selected by the fan-out flags. Without them it
+// returns a single empty account, which stands for the current one.
+func Accounts(cmd *cobra.Command, client *sdk.Chariot) ([]string, error) {
+	all, _ := cmd.Flags().GetBool("all-accounts")
+	selected, _ := cmd.Flags().GetStringSlice("accounts")
+
+	switch {
+	case all:
+		accounts, err := client.LinkedAccounts()
+		if err != nil {
+			return nil, err
+		}
+		if len(accounts) == 0 {
+			return nil, fmt.Errorf("%s is not a member of any other account", client.Username)
+		}
+		return accounts, nil
+	case len(selected) > 0:
+		accounts := make([]string, 0, len(selected))
+		seen := make(map[string]bool)
+		for _, account := range selected {
+			account = strings.TrimSpace(account)
+			if account != "" && !seen[account] {
+				seen[account] = true
+				accounts = append(accounts, account)
+			}
+		}
+		return accounts, nil
+	default:
+		return []string{""}, nil
+	}
+}
+
+// Prefix tags an output row with its account
+func Prefix(account string) string {
+	if account == "" {
+		return ""
+	}
+	return account + "\t"
+}
+
+// For names the account in an error message
+func For(account string) string {
+	if account == "" {
+		return ""
+	}
+	return " for " + account
+}
+
+// JSON marshals v with an account field added when fanning out
+func JSON(account string, v any) []byte {
+	data, _ := json.Marshal(v)
+	if account == "" || len(data) < 2 || data[0] != '{' {
+		return data
+	}
+	tag, _ := json.Marshal(account)
+	if string(data) == "{}" {
+		return []byte(fmt.Sprintf(`{"account":%s}`, tag))
+	}
+	return []byte(fmt.Sprintf(`{"account":%s,%s`, tag, data[1:]))
+}
+
+// Counts aggregates how many rows each account produced
+type Counts struct {
+	accounts []string
+	counts   map[string]int
+}
+
+func (c *Counts) Add(account string, n int) {
+	if c.counts == nil {
+		c.counts = make(map[string]int)
+	}
+	if _, ok := c.counts[account]; !ok {
+		c.accounts = append(c.accounts, account)
+	}
+	c.counts[account] += n
+}
+
+// Print writes per-account and total counts to stderr, so they do not mix with
+// the rows on stdout. Nothing is printed for the current account alone.
+func (c *Counts) Print(cmd *cobra.Command, noun string) {
+	if len(c.accounts) == 0 || (len(c.accounts) == 1 && c.accounts[0] == "") {
+		return
+	}
+	total := 0
+	for _, account := range c.accounts {
+		cmd.PrintErrf("%s: %d %s\n", account, c.counts[account], noun)
+		total += c.counts[account]
+	}
+	cmd.PrintErrf("Total: %d %s across %d accounts\n", total, noun, len(c.accounts))
+}
@@ -1,9 +1,11 @@
 package file
 
 import (
+	"context"
 	"os"
+	"path/filepath"
 
-	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
 
 	"github.com/spf13/cobra"
 )
@@ -14,19 +16,51 @@ var downloadCmd = &cobra.Command{
 	Long: `Download the file from the specified file path,
 
 Example Usages:
-	chariot file download --name "path/to/fileNameOnChariot.txt" --path /path/to/downloadedFile.txt`,
+	chariot file download --name "path/to/fileNameOnChariot.txt" --path /path/to/downloadedFile.txt
+	chariot file download --name "captures/scan.pcap" --sha256 <digest> --progress`,
 	Run: func(cmd *cobra.Command, args []string) {
 		name, _ := cmd.Flags().GetString("name")
 		path, _ := cmd.Flags().GetString("path")
-		b, err := Client.DownloadFile(model.NewFile(name))
+		showProgress, _ := cmd.Flags().GetBool("progress")
+		checksum, _ := cmd.Flags().GetString("sha256")
+
+		if path == "" {
+			path = name
+		}
+		if dir := filepath.Dir(path); dir != "." {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				cmd.Printf("Failed to create directory: %v\n", err)
+				return
+			}
+		}
+
+		// download next to the destination so a failed or corrupt transfer never replaces it
+		tmp, err := os.CreateTemp(filepath.Dir(path), ".chariot-download-*")
 		if err != nil {
+			cmd.Printf("Failed to create file: %v\n", err)
+			return
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+
+		options := []sdk.TransferOption{sdk.WithSHA256(checksum)}
+		if showProgress {
+			options = append(options, sdk.WithProgress(progress(cmd)))
+		}
+
+		if err := Client.DownloadTo(context.Background(), name, tmp, options...); err != nil {
 			cmd.Printf("Failed to read file: %v\n", err)
 			return
 		}
-		if path == "" {
-			path = name
+		if err := tmp.Close(); err != nil {
+			cmd.Printf("Failed to write file: %v\n", err)
+			return
+		}
+		if err := os.Rename(tmp.Name(), path); err != nil {
+			cmd.Printf("Failed to write file: %v\n", err)
+			return
 		}
-		os.WriteFile(path, b, 0644)
+		os.Chmod(path, 0644)
 		cmd.Printf("Saved file at %s\n", path)
 	},
 }
@@ -34,5 +68,7 @@ Example Usages:
 func init() {
 	downloadCmd.Flags().String("name", "", "Name of the file to read")
 	downloadCmd.Flags().String("path", "", "Path to save the file to")
+	downloadCmd.Flags().Bool("progress", false, "Show download progress")
+	downloadCmd.Flags().String("sha256", "", "Expected SHA-256 of the file, verified after downloading")
 	downloadCmd.MarkFlagRequired("name")
 }
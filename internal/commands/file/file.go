@@ -21,6 +21,8 @@ func init() {
 		uploadCmd,
 		downloadCmd,
 		listCmd,
+		syncCmd,
+		keyCmd,
 	)
 }
 
@@ -0,0 +1,107 @@
+package file
+
+import (
+	"context"
+	"strings"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/envelope"
+
+	"github.com/spf13/cobra"
+)
+
+var keyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "Manage the team key used for client-side encryption",
+	Long: `Files and proofs can be encrypted before they leave your machine. Each file is
+sealed with its own random data key (AES-256-GCM), which is wrapped with a team
+key shared through your keychain profile:
+
+  encryption_key = <base64 team key>
+  previous_encryption_keys = <base64 old key>,<base64 older key>
+  encrypt = true
+
+Uploads are encrypted when encrypt is set in the profile or --encrypt is passed.
+Downloads of encrypted files are always decrypted transparently. Definitions are
+never encrypted since the Chariot UI renders them.
+
+Example Usages:
+	chariot file key generate
+	chariot file key rotate --new-key <base64 key> --prefix proofs/`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+	Args: cobra.MinimumNArgs(1),
+}
+
+var keyGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate a new team encryption key",
+	Run: func(cmd *cobra.Command, args []string) {
+		key, err := envelope.GenerateKey()
+		if err != nil {
+			cmd.PrintErrf("Failed to generate key: %v\n", err)
+			return
+		}
+		cmd.Printf("%s\n", key)
+	},
+}
+
+var keyRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Rewrap encrypted files with a new team key",
+	Long: `Rewrap the data key of every encrypted file with a new team key. File contents
+are not re-encrypted, only their headers are rewritten. Files encrypted with
+any key in your profile can be rotated.
+
+Once rotation completes, set encryption_key to the new key in your keychain
+profile and move the old key to previous_encryption_keys until every
+collaborator has updated.
+
+Example Usages:
+	chariot file key rotate --new-key <base64 key>
+	chariot file key rotate --new-key <base64 key> --prefix proofs/example.com`,
+	Run: func(cmd *cobra.Command, args []string) {
+		encoded, _ := cmd.Flags().GetString("new-key")
+		prefix, _ := cmd.Flags().GetString("prefix")
+
+		key, err := envelope.ParseKey(encoded)
+		if err != nil {
+			cmd.PrintErrf("Failed to parse key: %v\n", err)
+			return
+		}
+
+		files, err := Client.Files.List()
+		if err != nil {
+			cmd.PrintErrf("Failed to list files: %v\n", err)
+			return
+		}
+
+		rotated := 0
+		for _, file := range files {
+			if !strings.HasPrefix(file.Name, prefix) || strings.HasPrefix(file.Name, "definitions/") {
+				continue
+			}
+			ok, err := Client.Rewrap(context.Background(), file.Name, key)
+			if err != nil {
+				cmd.PrintErrf("Failed to rotate %s: %v\n", file.Name, err)
+				continue
+			}
+			if ok {
+				rotated++
+				cmd.Printf("Rotated %s\n", file.Name)
+			}
+		}
+		cmd.Printf("Rotated %d files to key %s\n", rotated, key.ID)
+	},
+}
+
+func init() {
+	keyCmd.AddCommand(
+		keyGenerateCmd,
+		keyRotateCmd,
+	)
+
+	keyRotateCmd.Flags().String("new-key", "", "Base64 encoded team key to rewrap files with (required)")
+	keyRotateCmd.Flags().String("prefix", "", "Only rotate files whose name starts with this prefix")
+	keyRotateCmd.MarkFlagRequired("new-key")
+}
@@ -0,0 +1,43 @@
+package file
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// progress reports transfers on stderr, redrawing at most once per percent
+func progress(cmd *cobra.Command) func(done, total int64) {
+	last := int64(-1)
+	return func(done, total int64) {
+		if total <= 0 {
+			if done/(1<<20) != last {
+				last = done / (1 << 20)
+				cmd.PrintErrf("\r%s", size(done))
+			}
+			return
+		}
+		percent := done * 100 / total
+		if percent == last {
+			return
+		}
+		last = percent
+		cmd.PrintErrf("\r%s / %s (%d%%)", size(done), size(total), percent)
+		if done == total {
+			cmd.PrintErrln()
+		}
+	}
+}
+
+func size(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
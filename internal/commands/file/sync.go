@@ -0,0 +1,99 @@
+package file
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+
+	"github.com/spf13/cobra"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Synchronize a local directory with Chariot file storage",
+	Long: `Compare a local directory against the files stored under a remote prefix and
+transfer only the files that are missing or were modified more recently on the
+source side. With --delete the destination becomes a mirror of the source and
+files missing from the source are removed, so --delete requires a non-empty
+--remote prefix. Remote files whose names would be written outside --local are
+skipped.
+
+Example Usages:
+	chariot file sync --local ./proofs --remote proofs/ --direction up --dry-run
+	chariot file sync --local ./backup --remote "" --direction down --concurrency 8
+	chariot file sync --local ./definitions --remote definitions/ --direction up --delete`,
+	Run: func(cmd *cobra.Command, args []string) {
+		local, _ := cmd.Flags().GetString("local")
+		remote, _ := cmd.Flags().GetString("remote")
+		direction, _ := cmd.Flags().GetString("direction")
+		mirror, _ := cmd.Flags().GetBool("delete")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+
+		if mirror && remote == "" {
+			cmd.PrintErrf("Refusing to mirror the whole file store: --delete requires a --remote prefix\n")
+			return
+		}
+		if remote != "" && !strings.HasSuffix(remote, "/") {
+			remote += "/"
+		}
+
+		actions, err := Client.Files.PlanSync(local, remote, direction, mirror)
+		if err != nil {
+			cmd.PrintErrf("Failed to plan sync: %v\n", err)
+			return
+		}
+
+		if len(actions) == 0 {
+			cmd.Printf("Everything is up to date\n")
+			return
+		}
+
+		if dryRun {
+			for _, action := range actions {
+				cmd.Printf("(dry run) %s\n", action)
+			}
+			return
+		}
+
+		skipped := 0
+		for _, action := range actions {
+			if action.Op == sdk.Skip {
+				skipped++
+				cmd.PrintErrf("Skipped %s: %s\n", action.Remote, action.Reason)
+			}
+		}
+
+		var mu sync.Mutex
+		failed := 0
+		Client.Sync(context.Background(), actions, concurrency, func(action sdk.SyncAction, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if action.Op == sdk.Skip {
+				return
+			}
+			if err != nil {
+				failed++
+				cmd.PrintErrf("Failed to %s: %v\n", action, err)
+				return
+			}
+			cmd.Printf("%s\n", action)
+		})
+
+		total := len(actions) - skipped
+		cmd.Printf("Synced %d of %d files\n", total-failed, total)
+	},
+}
+
+func init() {
+	syncCmd.Flags().String("local", "", "Local directory to sync")
+	syncCmd.Flags().String("remote", "", "Remote prefix to sync (e.g., proofs/)")
+	syncCmd.Flags().String("direction", "", "Direction to sync - can be up or down")
+	syncCmd.Flags().Bool("delete", false, "Delete destination files that are missing from the source")
+	syncCmd.Flags().Bool("dry-run", false, "Print the planned changes without applying them")
+	syncCmd.Flags().Int("concurrency", 4, "Number of files to transfer at once")
+	syncCmd.MarkFlagRequired("local")
+	syncCmd.MarkFlagRequired("direction")
+}
@@ -1,8 +1,11 @@
 package file
 
 import (
+	"context"
 	"os"
 
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+
 	"github.com/spf13/cobra"
 )
 
@@ -10,21 +13,37 @@ import (
 var uploadCmd = &cobra.Command{
 	Use:   "upload",
 	Short: "Upload a file to the server",
-	Long: `Upload a file to the server at the specified path via name.
+	Long: `Upload a file to the server at the specified path via name. The file is
+streamed, so large archives and packet captures do not need to fit in memory.
 
 Example Usages:
-	chariot file upload --name "fileNameOnChariot.txt" --file /path/to/file.txt`,
+	chariot file upload --name "fileNameOnChariot.txt" --file /path/to/file.txt
+	chariot file upload --name "captures/scan.pcap" --file scan.pcap --progress`,
 	Run: func(cmd *cobra.Command, args []string) {
 		name, _ := cmd.Flags().GetString("name")
 		file, _ := cmd.Flags().GetString("file")
+		showProgress, _ := cmd.Flags().GetBool("progress")
+		checksum, _ := cmd.Flags().GetString("sha256")
+
+		f, err := os.Open(file)
+		if err != nil {
+			cmd.PrintErrf("Failed to read file: %v\n", err)
+			return
+		}
+		defer f.Close()
 
-		data, err := os.ReadFile(file)
+		info, err := f.Stat()
 		if err != nil {
 			cmd.PrintErrf("Failed to read file: %v\n", err)
 			return
 		}
 
-		err = Client.Upload(name, data)
+		options := []sdk.TransferOption{sdk.WithSHA256(checksum)}
+		if showProgress {
+			options = append(options, sdk.WithProgress(progress(cmd)))
+		}
+
+		err = Client.UploadReader(context.Background(), name, f, info.Size(), options...)
 		if err != nil {
 			cmd.PrintErrf("Failed to upload file: %v\n", err)
 			return
@@ -37,6 +56,8 @@ Example Usages:
 func init() {
 	uploadCmd.Flags().String("name", "", "Name to store the file as in Chariot")
 	uploadCmd.Flags().String("file", "", "Path to the file to upload")
+	uploadCmd.Flags().Bool("progress", false, "Show upload progress")
+	uploadCmd.Flags().String("sha256", "", "Expected SHA-256 of the file, verified before uploading")
 	uploadCmd.MarkFlagRequired("name")
 	uploadCmd.MarkFlagRequired("file")
 }
@@ -0,0 +1,31 @@
+package integrate
+
+import (
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+
+	"github.com/spf13/cobra"
+)
+
+var integrateCmd = &cobra.Command{
+	Use:   "integrate",
+	Short: "Push Chariot risks into external systems",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+	Args: cobra.MinimumNArgs(1),
+}
+
+var Client *sdk.Chariot
+
+func init() {
+	integrateCmd.AddCommand(
+		runCmd,
+		siemCmd,
+	)
+}
+
+func Cmd(client *sdk.Chariot) *cobra.Command {
+	Client = client
+	return integrateCmd
+}
@@ -0,0 +1,109 @@
+package integrate
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/praetorian-inc/chariot-client/pkg/integrations"
+
+	"github.com/spf13/cobra"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Push new and changed risks to your integrations until interrupted",
+	Long: `Poll the risks of your account and push every risk that is new or changed since
+the last poll to the integrations in the config file, optionally limited by a
+filter per integration. What each integration has received is saved to a local
+checkpoint file, so a restarted runner picks up where it stopped and failed
+pushes are retried on the next poll.
+
+Health and Prometheus metrics are served on /healthz and /metrics of the
+listen address. Interrupt to stop; batched notifications are sent first.
+
+  interval: 1m
+  checkpoint: chariot-integrations.json
+  listen: 127.0.0.1:9464
+  skip_existing: true
+  integrations:
+    - name: jira
+      config:
+        url: https://acme.atlassian.net
+        user: security@acme.com
+        token: ${JIRA_TOKEN}
+        project: SEC
+      filter:
+        states: [open]
+    - name: on-call
+      type: slack
+      config:
+        url: ${SLACK_WEBHOOK}
+      filter:
+        min_severity: high
+        dns: ["*.acme.com"]
+
+Available integrations: jira, pagerduty, servicenow, siem, slack, teams and
+webhook.
+
+Example Usages:
+  chariot integrate run --config integrations.yaml
+  chariot integrate run --config integrations.yaml --listen 0.0.0.0:9464
+  chariot integrate run --config integrations.yaml --once`,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, _ := cmd.Flags().GetString("config")
+		once, _ := cmd.Flags().GetBool("once")
+
+		config, err := integrations.LoadRunnerConfig(path)
+		if err != nil {
+			cmd.PrintErrf("Failed to load %s: %v\n", path, err)
+			return
+		}
+		if cmd.Flags().Changed("listen") {
+			config.Listen, _ = cmd.Flags().GetString("listen")
+		}
+
+		runner, err := integrations.NewRunner(Client, config)
+		if err != nil {
+			cmd.PrintErrf("Failed to configure integrations: %v\n", err)
+			return
+		}
+
+		if once {
+			defer runner.Close()
+			if err := runner.Poll(context.Background()); err != nil {
+				cmd.PrintErrf("Failed to push risks: %v\n", err)
+			}
+			return
+		}
+
+		listener, err := net.Listen("tcp", config.Listen)
+		if err != nil {
+			cmd.PrintErrf("Failed to listen on %s: %v\n", config.Listen, err)
+			return
+		}
+		server := &http.Server{Handler: runner.Handler()}
+		go func() {
+			if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				cmd.PrintErrf("Failed to serve health checks: %v\n", err)
+			}
+		}()
+		defer server.Close()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		cmd.Printf("Pushing risks to %d integrations every %s, health and metrics on http://%s\n", len(config.Integrations), config.Interval, listener.Addr())
+		runner.Run(ctx)
+	},
+}
+
+func init() {
+	runCmd.Flags().String("config", "", "YAML file of integrations (required)")
+	runCmd.Flags().String("listen", integrations.DefaultListen, "Address for /healthz and /metrics, overriding the config file")
+	runCmd.Flags().Bool("once", false, "Poll once and exit instead of running until interrupted")
+	runCmd.MarkFlagRequired("config")
+}
@@ -0,0 +1,137 @@
+package integrate
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/integrations"
+
+	"github.com/spf13/cobra"
+)
+
+var siemCmd = &cobra.Command{
+	Use:   "siem",
+	Short: "Ship risks to a SIEM over syslog",
+	Long: `Send every existing risk to a syslog collector as CEF, LEEF or JSON records,
+then keep polling Chariot and send each risk that is added or changes. With
+--assets, assets are shipped the same way. Interrupt to stop.
+
+Records are wrapped in RFC 5424 syslog messages. TCP and TLS use octet
+counting framing, as rsyslog, syslog-ng, Splunk and QRadar expect.
+
+Example Usages:
+  chariot integrate siem --address siem.acme.com:514
+  chariot integrate siem --address siem.acme.com:6514 --network tls --ca-file ca.pem --format leef
+  chariot integrate siem --address 127.0.0.1:514 --format json --assets --backfill=false --interval 5m
+  chariot integrate siem --address 127.0.0.1:514 --once`,
+	Run: func(cmd *cobra.Command, args []string) {
+		config := map[string]string{}
+		for flag, key := range map[string]string{"address": "address", "network": "network", "format": "format", "ca-file": "ca_file", "hostname": "hostname"} {
+			config[key], _ = cmd.Flags().GetString(flag)
+		}
+		if assets, _ := cmd.Flags().GetBool("assets"); assets {
+			config["assets"] = "true"
+		}
+		backfill, _ := cmd.Flags().GetBool("backfill")
+		once, _ := cmd.Flags().GetBool("once")
+		interval, _ := cmd.Flags().GetDuration("interval")
+
+		if interval <= 0 {
+			cmd.PrintErrf("Failed to configure SIEM: --interval must be positive\n")
+			return
+		}
+
+		siem, err := integrations.NewSIEM(config)
+		if err != nil {
+			cmd.PrintErrf("Failed to configure SIEM: %v\n", err)
+			return
+		}
+		defer siem.Close()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		// seen holds the last update shipped for each key, so polls only ship changes
+		seen := make(map[string]string)
+		poll := func(send bool) {
+			risks, assets := 0, 0
+			failed := 0
+			list, err := Client.Risks.List()
+			if err != nil {
+				cmd.PrintErrf("Failed to list risks: %v\n", err)
+				return
+			}
+			for _, risk := range list {
+				if seen[risk.Key] == risk.Updated {
+					continue
+				}
+				if send {
+					if err := siem.Sync(ctx, risk); err != nil {
+						cmd.PrintErrf("Failed to send %s: %v\n", risk.Key, err)
+						failed++
+						continue
+					}
+					risks++
+				}
+				seen[risk.Key] = risk.Updated
+			}
+
+			if siem.Assets {
+				list, err := Client.Assets.List()
+				if err != nil {
+					cmd.PrintErrf("Failed to list assets: %v\n", err)
+					return
+				}
+				for _, asset := range list {
+					if seen[asset.Key] == asset.Updated {
+						continue
+					}
+					if send {
+						if err := siem.SyncAsset(ctx, asset); err != nil {
+							cmd.PrintErrf("Failed to send %s: %v\n", asset.Key, err)
+							failed++
+							continue
+						}
+						assets++
+					}
+					seen[asset.Key] = asset.Updated
+				}
+			}
+
+			if risks+assets+failed > 0 {
+				cmd.Printf("%s: sent %d risks and %d assets, %d failed\n", time.Now().Format(time.RFC3339), risks, assets, failed)
+			}
+		}
+
+		poll(backfill)
+		if once {
+			return
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				poll(true)
+			}
+		}
+	},
+}
+
+func init() {
+	siemCmd.Flags().String("address", "", "host:port of the syslog collector (required)")
+	siemCmd.Flags().String("network", "udp", "Transport: udp, tcp or tls")
+	siemCmd.Flags().String("format", integrations.CEF, "Record format: cef, leef or json")
+	siemCmd.Flags().String("ca-file", "", "PEM file of CA certificates trusted for tls, instead of the system pool")
+	siemCmd.Flags().String("hostname", "", "Syslog hostname (default is this host's name)")
+	siemCmd.Flags().Bool("assets", false, "Also ship assets as they are added or change")
+	siemCmd.Flags().Bool("backfill", true, "Ship existing risks before tailing; with --backfill=false only later changes are shipped")
+	siemCmd.Flags().Bool("once", false, "Ship existing risks and exit instead of tailing")
+	siemCmd.Flags().Duration("interval", time.Minute, "How often to poll for new and changed risks")
+	siemCmd.MarkFlagRequired("address")
+}
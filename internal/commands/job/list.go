@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/praetorian-inc/chariot-client/internal/commands/fanout"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+
 	"github.com/spf13/cobra"
 )
 
@@ -13,33 +17,49 @@ var listCmd = &cobra.Command{
 	Long: `Return the list of all jobs in the Chariot database.
 
 Example Usages:
-	chariot job list`,
+	chariot job list
+	chariot job list --status F --all-accounts`,
 	Run: func(cmd *cobra.Command, args []string) {
 		capability, _ := cmd.Flags().GetString("capability")
 		status, _ := cmd.Flags().GetString("status")
 		details, _ := cmd.Flags().GetBool("details")
 
-		jobs, err := Client.Jobs.List()
+		accounts, err := fanout.Accounts(cmd, Client)
 		if err != nil {
-			cmd.Printf("Failed to list jobs: %v\n", err)
+			cmd.Printf("Failed to list accounts: %v\n", err)
 			return
 		}
 
-		for _, job := range jobs {
-			if capability != "" && strings.ToLower(job.Source) != strings.ToLower(capability) {
+		var counts fanout.Counts
+		results := sdk.FanOut(Client, accounts, func(client *sdk.Chariot) ([]model.Job, error) {
+			return client.Jobs.List()
+		})
+		for _, result := range results {
+			if result.Err != nil {
+				cmd.Printf("Failed to list jobs%s: %v\n", fanout.For(result.Account), result.Err)
 				continue
 			}
 
-			if status != "" && string(job.Status[1]) != status {
-				continue
-			}
+			listed := 0
+			for _, job := range result.Value {
+				if capability != "" && strings.ToLower(job.Source) != strings.ToLower(capability) {
+					continue
+				}
+
+				if status != "" && string(job.Status[1]) != status {
+					continue
+				}
 
-			if details {
-				cmd.Printf("%s\n", job.Raw())
-			} else {
-				cmd.Printf("%s,%s,%s\n", job.DNS, job.Source, job.Status)
+				if details {
+					cmd.Printf("%s\n", fanout.JSON(result.Account, job))
+				} else {
+					cmd.Printf("%s%s,%s,%s\n", fanout.Prefix(result.Account), job.DNS, job.Source, job.Status)
+				}
+				listed++
 			}
+			counts.Add(result.Account, listed)
 		}
+		counts.Print(cmd, "jobs")
 	},
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		status, _ := cmd.Flags().GetString("status")
@@ -59,4 +79,5 @@ func init() {
 	listCmd.Flags().String("capability", "", "Filter the list of risks by capability")
 	listCmd.Flags().String("status", "", "Filter the list of risks by status (Q, R, F, P)")
 	listCmd.Flags().Bool("details", false, "Show detailed information about each risk")
+	fanout.AddFlags(listCmd)
 }
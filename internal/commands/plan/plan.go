@@ -0,0 +1,92 @@
+package plan
+
+import (
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/surface"
+
+	"github.com/spf13/cobra"
+)
+
+var Client *sdk.Chariot
+
+func Cmd(client *sdk.Chariot) *cobra.Command {
+	Client = client
+	return planCmd
+}
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Show the changes needed to match a surface file",
+	Long: `Compare the seed assets, collaborators and integrations declared in a surface
+file against your account and print the changes apply would make. Nothing is
+modified.
+
+Assets not yet in the account are added with their priority (standard when
+omitted), and existing assets with a different priority are updated. apply
+records the declared items in a state file, surface.state.json next to
+surface.yaml by default. With --prune, items recorded in the state file that
+were since removed from the surface file are deleted from the account. Items
+the state file does not list, such as collaborators invited in the UI or
+discovered assets, are never pruned, and neither is the webhook.
+
+Integration config values may reference environment variables as ${NAME}.
+
+Example surface.yaml:
+  assets:
+    - dns: acme.com
+      priority: comprehensive
+    - dns: acme.com
+      name: 1.2.3.4
+      priority: frozen
+  collaborators:
+    - research@praetorian.com
+  integrations:
+    - name: slack
+      value: "#security"
+      config:
+        token: ${SLACK_TOKEN}
+
+Example Usages:
+  chariot plan -f surface.yaml
+  chariot plan -f surface.yaml --prune`,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, _ := cmd.Flags().GetString("file")
+		prune, _ := cmd.Flags().GetBool("prune")
+		statePath, _ := cmd.Flags().GetString("state")
+		if statePath == "" {
+			statePath = surface.StatePath(path)
+		}
+
+		file, err := surface.Load(path)
+		if err != nil {
+			cmd.PrintErrf("Failed to load surface file: %v\n", err)
+			return
+		}
+		state, err := surface.LoadState(statePath)
+		if err != nil {
+			cmd.PrintErrf("Failed to load state file: %v\n", err)
+			return
+		}
+
+		plan, err := surface.Build(Client, file, state, prune)
+		if err != nil {
+			cmd.PrintErrf("Failed to plan changes: %v\n", err)
+			return
+		}
+
+		if len(plan.Changes) == 0 {
+			cmd.Println("No changes. Your account matches the surface file.")
+			return
+		}
+		for _, change := range plan.Changes {
+			cmd.Println(change)
+		}
+		cmd.Println(plan.Summary())
+	},
+}
+
+func init() {
+	planCmd.Flags().StringP("file", "f", "surface.yaml", "Surface file declaring assets, collaborators and integrations")
+	planCmd.Flags().Bool("prune", false, "Remove items recorded in the state file that are no longer in the surface file")
+	planCmd.Flags().String("state", "", "State file of the items apply manages (default is <file>.state.json)")
+}
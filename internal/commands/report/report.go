@@ -0,0 +1,116 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/praetorian-inc/chariot-client/internal/commands/fanout"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/report"
+
+	"github.com/spf13/cobra"
+)
+
+var Client *sdk.Chariot
+
+func Cmd(client *sdk.Chariot) *cobra.Command {
+	Client = client
+	return reportCmd
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate an HTML or Markdown report for your account",
+	Long: `Generate a self-contained report of your account: asset inventory by class,
+risks grouped by severity and state, and per-risk details including status
+history. Definitions and proof excerpts can be included when available.
+
+The embedded templates can be replaced with your own Go template via --template.
+Templates receive the report data and the helpers date, state and severity.
+
+With --all-accounts or --accounts, one report is generated per account and
+--output, which is then required, is suffixed with the account name.
+
+Example Usages:
+	chariot report --format html --output report.html
+	chariot report --format markdown --definitions --proofs
+	chariot report --format html --template branded.html.tmpl --output report.html
+	chariot report --all-accounts --output report.html`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		override, _ := cmd.Flags().GetString("template")
+		definitions, _ := cmd.Flags().GetBool("definitions")
+		proofs, _ := cmd.Flags().GetBool("proofs")
+		limit, _ := cmd.Flags().GetInt("proof-limit")
+		all, _ := cmd.Flags().GetBool("all-accounts")
+		selected, _ := cmd.Flags().GetStringSlice("accounts")
+
+		// reports for several accounts would be concatenated on stdout
+		if output == "" && (all || len(selected) > 0) {
+			cmd.PrintErrf("Failed to generate reports: --output is required with --all-accounts or --accounts\n")
+			return
+		}
+
+		accounts, err := fanout.Accounts(cmd, Client)
+		if err != nil {
+			cmd.PrintErrf("Failed to list accounts: %v\n", err)
+			return
+		}
+
+		var assets, risks fanout.Counts
+		for _, result := range sdk.FanOut(Client, accounts, func(client *sdk.Chariot) (*report.Report, error) {
+			return report.Build(client, report.Options{Definitions: definitions, Proofs: proofs, ProofLimit: limit})
+		}) {
+			if result.Err != nil {
+				cmd.PrintErrf("Failed to build report%s: %v\n", fanout.For(result.Account), result.Err)
+				continue
+			}
+			r := result.Value
+
+			var buf bytes.Buffer
+			if err := r.Render(&buf, format, override); err != nil {
+				cmd.PrintErrf("Failed to render report%s: %v\n", fanout.For(result.Account), err)
+				continue
+			}
+			assets.Add(result.Account, r.TotalAssets)
+			risks.Add(result.Account, r.TotalRisks)
+
+			if output == "" {
+				cmd.Print(buf.String())
+				continue
+			}
+			path := outputFor(output, result.Account)
+			if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+				cmd.PrintErrf("Failed to write report%s: %v\n", fanout.For(result.Account), err)
+				continue
+			}
+			cmd.Printf("Report saved at %s\n", path)
+		}
+		assets.Print(cmd, "assets")
+		risks.Print(cmd, "risks")
+	},
+}
+
+// outputFor names one report per account, e.g. report.html becomes
+// report.acme@example.com.html
+func outputFor(output, account string) string {
+	if account == "" {
+		return output
+	}
+	ext := filepath.Ext(output)
+	return strings.TrimSuffix(output, ext) + "." + account + ext
+}
+
+func init() {
+	reportCmd.Flags().String("format", report.HTML, "Report format - can be html or markdown")
+	reportCmd.Flags().String("output", "", "File to save the report to (default is stdout)")
+	reportCmd.Flags().String("template", "", "Go template to render instead of the embedded one")
+	reportCmd.Flags().Bool("definitions", false, "Include risk definitions")
+	reportCmd.Flags().Bool("proofs", false, "Include proof of exploitation excerpts")
+	reportCmd.Flags().Int("proof-limit", 2048, "Maximum number of proof bytes to include per risk")
+	fanout.AddFlags(reportCmd)
+}
@@ -1,7 +1,10 @@
 package risk
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
 
@@ -13,9 +16,20 @@ var definitionCmd = &cobra.Command{
 	Short: "Manage the definitions for a risk",
 	Long: `Definitions are markdown files which are displayed by the Chariot UI to provide context for a risk. Use this command to upload and download definitions for specific risks.
 
+A definition has a "# Title" heading followed by the sections ## Description,
+## Impact, ## Recommendation and ## References. It may start with a front-matter
+block setting the severity:
+
+  ---
+  severity: H
+  ---
+
 Example Usages:
+  chariot risk definition new --name "sql-injection" --title "SQL Injection" --severity H
+  chariot risk definition lint --file sql-injection.md
   chariot risk definition upload --name "sql-injection" --file sql_injection_md_file
-  chariot risk definition download --name "sql-injection"`,
+  chariot risk definition download --name "sql-injection"
+  chariot risk definition sync --dir ./definitions --direction up`,
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.Help()
 	},
@@ -25,10 +39,11 @@ Example Usages:
 var defUploadCmd = &cobra.Command{
 	Use:   "upload",
 	Short: "Upload a definition for a risk",
-	Long:  ``,
+	Long:  `Upload a definition for a risk. The definition is linted first and not uploaded if it is invalid, unless --force is set.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		name, _ := cmd.Flags().GetString("name")
 		file, _ := cmd.Flags().GetString("file")
+		force, _ := cmd.Flags().GetBool("force")
 
 		data, err := os.ReadFile(file)
 		if err != nil {
@@ -36,6 +51,15 @@ var defUploadCmd = &cobra.Command{
 			return
 		}
 
+		definition := model.ParseDefinition(name, data)
+		if errs := definition.Lint(); len(errs) > 0 && !force {
+			for _, err := range errs {
+				cmd.PrintErrf("%v\n", err)
+			}
+			cmd.PrintErrf("Failed to upload definition: %d problems found, use --force to upload anyway\n", len(errs))
+			return
+		}
+
 		err = Client.UploadDefinition(model.Risk{Name: name}, data)
 		if err != nil {
 			cmd.PrintErrf("Failed to upload definition: %v\n", err)
@@ -63,17 +87,242 @@ var defDownloadCmd = &cobra.Command{
 	},
 }
 
+var defLintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Validate definition markdown files",
+	Long: `Check that definitions have a title, every required section filled in and a
+valid front-matter severity. Files are named after the risk they define.
+
+Example Usages:
+  chariot risk definition lint --file sql-injection.md
+  chariot risk definition lint --dir ./definitions`,
+	// problems fail the command, so lint can gate CI
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		dir, _ := cmd.Flags().GetString("dir")
+
+		files := []string{file}
+		if dir != "" {
+			var err error
+			files, err = definitionFiles(dir)
+			if err != nil {
+				return fmt.Errorf("failed to read directory: %v", err)
+			}
+		}
+
+		problems := 0
+		for _, path := range files {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				cmd.PrintErrf("Failed to read file: %v\n", err)
+				problems++
+				continue
+			}
+			definition := model.ParseDefinition(definitionName(path), data)
+			for _, err := range definition.Lint() {
+				cmd.PrintErrf("%s: %v\n", path, err)
+				problems++
+			}
+		}
+
+		if problems > 0 {
+			return fmt.Errorf("%d problems found in %d definitions", problems, len(files))
+		}
+		cmd.Printf("%d definitions are valid\n", len(files))
+		return nil
+	},
+}
+
+var defNewCmd = &cobra.Command{
+	Use:   "new",
+	Short: "Scaffold a new definition",
+	Long: `Write a definition skeleton with every required section for a risk. The
+placeholders fail linting until they are replaced.
+
+Example Usages:
+  chariot risk definition new --name "sql-injection" --title "SQL Injection" --severity H
+  chariot risk definition new --name "sql-injection" --output ./definitions/sql-injection.md`,
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		title, _ := cmd.Flags().GetString("title")
+		severity, _ := cmd.Flags().GetString("severity")
+		output, _ := cmd.Flags().GetString("output")
+
+		severity = strings.ToUpper(severity)
+		switch severity {
+		case "", "I", "L", "M", "H", "C":
+		default:
+			cmd.PrintErrf("Invalid severity level: %s, expected I, L, M, H or C\n", severity)
+			return
+		}
+		if title == "" {
+			title = name
+		}
+		definition := model.NewDefinition(name, title, severity)
+
+		if output == "" {
+			output = name + ".md"
+		}
+		if _, err := os.Stat(output); err == nil {
+			cmd.PrintErrf("Failed to create definition: %s already exists\n", output)
+			return
+		}
+		if err := os.WriteFile(output, definition.Markdown(), 0644); err != nil {
+			cmd.PrintErrf("Failed to write definition: %v\n", err)
+			return
+		}
+		cmd.Printf("Definition scaffold saved at %s\n", output)
+	},
+}
+
+var defSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Upload or download a directory of definitions",
+	Long: `Synchronize a directory of <risk name>.md files with the definitions stored in
+Chariot. Uploading lints every definition first and uploads nothing if any are
+invalid, unless --force is set.
+
+Example Usages:
+  chariot risk definition sync --dir ./definitions --direction up
+  chariot risk definition sync --dir ./definitions --direction down`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, _ := cmd.Flags().GetString("dir")
+		direction, _ := cmd.Flags().GetString("direction")
+		force, _ := cmd.Flags().GetBool("force")
+
+		switch direction {
+		case "up":
+			syncUp(cmd, dir, force)
+		case "down":
+			syncDown(cmd, dir)
+		default:
+			cmd.PrintErrf("Invalid direction: %s, expected up or down\n", direction)
+		}
+	},
+}
+
+func syncUp(cmd *cobra.Command, dir string, force bool) {
+	files, err := definitionFiles(dir)
+	if err != nil {
+		cmd.PrintErrf("Failed to read directory: %v\n", err)
+		return
+	}
+
+	definitions := make(map[string][]byte)
+	problems := 0
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			cmd.PrintErrf("Failed to read file: %v\n", err)
+			return
+		}
+		name := definitionName(path)
+		definition := model.ParseDefinition(name, data)
+		for _, err := range definition.Lint() {
+			cmd.PrintErrf("%s: %v\n", path, err)
+			problems++
+		}
+		definitions[name] = data
+	}
+	if problems > 0 && !force {
+		cmd.PrintErrf("Failed to sync definitions: %d problems found, use --force to upload anyway\n", problems)
+		return
+	}
+
+	for name, data := range definitions {
+		if err := Client.UploadDefinition(model.Risk{Name: name}, data); err != nil {
+			cmd.PrintErrf("Failed to upload %s: %v\n", name, err)
+			continue
+		}
+		cmd.Printf("Uploaded %s\n", name)
+	}
+}
+
+func syncDown(cmd *cobra.Command, dir string) {
+	files, err := Client.Files.List()
+	if err != nil {
+		cmd.PrintErrf("Failed to list files: %v\n", err)
+		return
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		cmd.PrintErrf("Failed to create directory: %v\n", err)
+		return
+	}
+
+	for _, file := range files {
+		name, ok := strings.CutPrefix(file.Name, "definitions/")
+		if !ok || name == "" || strings.Contains(name, "/") {
+			continue
+		}
+		data, err := Client.DownloadDefinition(model.Risk{Name: name})
+		if err != nil {
+			cmd.PrintErrf("Failed to download %s: %v\n", name, err)
+			continue
+		}
+		path := filepath.Join(dir, name+".md")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			cmd.PrintErrf("Failed to write %s: %v\n", path, err)
+			continue
+		}
+		cmd.Printf("Downloaded %s\n", path)
+	}
+}
+
+func definitionFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .md files found in %s", dir)
+	}
+	return files, nil
+}
+
+func definitionName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
 func init() {
 	definitionCmd.AddCommand(
 		defUploadCmd,
 		defDownloadCmd,
+		defLintCmd,
+		defNewCmd,
+		defSyncCmd,
 	)
 
 	defUploadCmd.Flags().String("name", "", "Name of the risk")
 	defUploadCmd.Flags().String("file", "", "File to upload")
+	defUploadCmd.Flags().Bool("force", false, "Upload even if the definition fails linting")
 	defUploadCmd.MarkFlagRequired("name")
 	defUploadCmd.MarkFlagRequired("file")
 
 	defDownloadCmd.Flags().String("name", "", "Name of the risk")
 	defDownloadCmd.MarkFlagRequired("name")
+
+	defLintCmd.Flags().String("file", "", "Definition file to lint")
+	defLintCmd.Flags().String("dir", "", "Directory of definition files to lint")
+	defLintCmd.MarkFlagsOneRequired("file", "dir")
+	defLintCmd.MarkFlagsMutuallyExclusive("file", "dir")
+
+	defNewCmd.Flags().String("name", "", "Name of the risk")
+	defNewCmd.Flags().String("title", "", "Title of the definition (default is the risk name)")
+	defNewCmd.Flags().String("severity", "", "Severity to record in the front-matter (I, L, M, H, C)")
+	defNewCmd.Flags().String("output", "", "Path to write the definition to (default is <name>.md)")
+	defNewCmd.MarkFlagRequired("name")
+
+	defSyncCmd.Flags().String("dir", "", "Directory of <risk name>.md definitions")
+	defSyncCmd.Flags().String("direction", "up", "Direction to sync - can be up or down")
+	defSyncCmd.Flags().Bool("force", false, "Upload even if definitions fail linting")
+	defSyncCmd.MarkFlagRequired("dir")
 }
@@ -1,6 +1,7 @@
 package risk
 
 import (
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
 	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
 
 	"github.com/spf13/cobra"
@@ -9,27 +10,45 @@ import (
 var deleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete a risk",
-	Long:  `Specify the key of the risk to delete with --key. This will flag the risk as deleted in the Chariot database.`,
+	Long: `Specify the key of the risk to delete with --key, or a file with one key per line
+with --keys-file (- reads from stdin). This will flag the risk as deleted in the Chariot database.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		key, _ := cmd.Flags().GetString("key")
+		keysFile, _ := cmd.Flags().GetString("keys-file")
 
-		risk, err := model.GetRiskFromKey(key)
-		if err != nil {
-			cmd.PrintErrf("Failed to get risk from key: %v\n", err)
-			return
+		keys := []string{key}
+		if keysFile != "" {
+			var err error
+			keys, err = sdk.ReadKeysFile(keysFile)
+			if err != nil {
+				cmd.PrintErrf("Failed to read keys: %v\n", err)
+				return
+			}
 		}
 
-		err = Client.Risks.Delete(*risk)
-		if err != nil {
-			cmd.PrintErrf("Failed to delete risk: %v\n", err)
-			return
+		risks := make([]model.Risk, 0, len(keys))
+		for _, key := range keys {
+			risk, err := model.GetRiskFromKey(key)
+			if err != nil {
+				cmd.PrintErrf("Failed to get risk from key: %v\n", err)
+				return
+			}
+			risks = append(risks, *risk)
 		}
 
-		cmd.Printf("Risk %s deleted successfully\n", key)
+		for _, result := range Client.Risks.DeleteMany(risks).Results {
+			if result.Err != nil {
+				cmd.PrintErrf("Failed to delete risk %s: %v\n", result.Item.Key, result.Err)
+				continue
+			}
+			cmd.Printf("Risk %s deleted successfully\n", result.Item.Key)
+		}
 	},
 }
 
 func init() {
 	deleteCmd.Flags().String("key", "", "Key of the risk to remove")
-	deleteCmd.MarkFlagRequired("key")
+	deleteCmd.Flags().String("keys-file", "", "File with one risk key per line to remove, - for stdin")
+	deleteCmd.MarkFlagsOneRequired("key", "keys-file")
+	deleteCmd.MarkFlagsMutuallyExclusive("key", "keys-file")
 }
@@ -0,0 +1,117 @@
+package risk
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/praetorian-inc/chariot-client/internal/commands/fanout"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export risks for other security tools",
+	Long: `Export risks in a format consumed by other security tools. SARIF 2.1.0 logs
+can be uploaded to code-scanning dashboards: each risk name becomes a rule, each
+risk a result located at its DNS name. With --all-accounts or --accounts, each
+account is exported as a separate run tagged with the account name.
+
+Example Usages:
+  chariot risk export --format sarif > chariot.sarif
+  chariot risk export --format sarif --status O --definitions --proofs --output chariot.sarif
+  chariot risk export --format sarif --all-accounts --output clients.sarif`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		status, _ := cmd.Flags().GetString("status")
+		definitions, _ := cmd.Flags().GetBool("definitions")
+		proofs, _ := cmd.Flags().GetBool("proofs")
+		output, _ := cmd.Flags().GetString("output")
+
+		if format != "sarif" {
+			cmd.PrintErrf("Unsupported format: %s\n", format)
+			return
+		}
+
+		accounts, err := fanout.Accounts(cmd, Client)
+		if err != nil {
+			cmd.PrintErrf("Failed to list accounts: %v\n", err)
+			return
+		}
+
+		var counts fanout.Counts
+		log := &sdk.SarifLog{}
+		for _, result := range sdk.FanOut(Client, accounts, func(client *sdk.Chariot) (*sdk.SarifLog, error) {
+			risks, err := client.Risks.List()
+			if err != nil {
+				return nil, err
+			}
+
+			filtered := make([]model.Risk, 0)
+			for _, risk := range risks {
+				// risks without a status have no severity to export
+				if risk.Status == "" || !strings.HasPrefix(risk.Status, status) {
+					continue
+				}
+				filtered = append(filtered, risk)
+			}
+			return client.SARIF(filtered, sdk.SarifOptions{Definitions: definitions, Proofs: proofs})
+		}) {
+			if result.Err != nil {
+				cmd.PrintErrf("Failed to export risks%s: %v\n", fanout.For(result.Account), result.Err)
+				continue
+			}
+			// each account becomes its own run, tagged with the account
+			run := result.Value.Runs[0]
+			if result.Account != "" {
+				run.Properties = map[string]any{"account": result.Account}
+			}
+			log.Schema, log.Version = result.Value.Schema, result.Value.Version
+			log.Runs = append(log.Runs, run)
+			counts.Add(result.Account, len(run.Results))
+		}
+		counts.Print(cmd, "risks")
+
+		data, err := json.MarshalIndent(log, "", "  ")
+		if err != nil {
+			cmd.PrintErrf("Failed to encode SARIF log: %v\n", err)
+			return
+		}
+
+		if output == "" {
+			cmd.Printf("%s\n", data)
+			return
+		}
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			cmd.PrintErrf("Failed to write %s: %v\n", output, err)
+			return
+		}
+		total := 0
+		for _, run := range log.Runs {
+			total += len(run.Results)
+		}
+		cmd.Printf("Exported %d risks to %s\n", total, output)
+	},
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		status, _ := cmd.Flags().GetString("status")
+		switch status {
+		case "", "T", "O", "R", "M":
+			return nil
+		default:
+			return fmt.Errorf("invalid status level: %s", status)
+		}
+	},
+}
+
+func init() {
+	exportCmd.Flags().String("format", "sarif", "Export format (sarif)")
+	exportCmd.Flags().String("status", "", "Filter the exported risks by status (T, O, R, M)")
+	exportCmd.Flags().Bool("definitions", false, "Include risk definitions as rule help")
+	exportCmd.Flags().Bool("proofs", false, "Include proof excerpts as related locations")
+	exportCmd.Flags().String("output", "", "File to write the export to (default is stdout)")
+	fanout.AddFlags(exportCmd)
+}
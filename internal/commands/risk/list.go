@@ -1,49 +1,129 @@
 package risk
 
 import (
-	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
+	"github.com/praetorian-inc/chariot-client/internal/commands/fanout"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+
 	"github.com/spf13/cobra"
 )
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all risks",
-	Long:  `Return the list of all risks in the Chariot database`,
+	Long: `Return the list of all risks in the Chariot database
+
+Sorting by score ranks risks by severity (or a cvss attribute), the priority of
+the affected asset, exposure attributes such as port, protocol and cloud, age,
+and an epss attribute when present.
+
+Example Usages:
+	chariot risk list --status O
+	chariot risk list --status O --sort score
+	chariot risk list --sort score --details
+	chariot risk list --status O --sort score --all-accounts`,
 	Run: func(cmd *cobra.Command, args []string) {
 		source, _ := cmd.Flags().GetString("source")
 		severity, _ := cmd.Flags().GetString("severity")
 		status, _ := cmd.Flags().GetString("status")
 		details, _ := cmd.Flags().GetBool("details")
+		sortBy, _ := cmd.Flags().GetString("sort")
 
-		risks, err := Client.Risks.List()
+		accounts, err := fanout.Accounts(cmd, Client)
 		if err != nil {
-			cmd.Printf("Failed to list risks: %s\n", err)
+			cmd.Printf("Failed to list accounts: %s\n", err)
 			return
 		}
 
-		for _, risk := range risks {
-			if severity != "" && risk.Severity() != severity {
-				continue
+		type listing struct {
+			risks  []model.Risk
+			scored []model.ScoredRisk
+		}
+
+		results := sdk.FanOut(Client, accounts, func(client *sdk.Chariot) (listing, error) {
+			risks, err := client.Risks.List()
+			if err != nil {
+				return listing{}, err
+			}
+
+			filtered := make([]model.Risk, 0)
+			for _, risk := range risks {
+				if severity != "" && risk.Severity() != severity {
+					continue
+				}
+				if status != "" && string(risk.Status[0]) != status {
+					continue
+				}
+				if source != "" && strings.ToLower(risk.Source) != strings.ToLower(source) {
+					continue
+				}
+				filtered = append(filtered, risk)
+			}
+			if sortBy != "score" {
+				return listing{risks: filtered}, nil
+			}
+
+			assets, err := client.Assets.List()
+			if err != nil {
+				return listing{}, fmt.Errorf("failed to list assets: %s", err)
+			}
+			attributes, err := client.Attributes.List()
+			if err != nil {
+				return listing{}, fmt.Errorf("failed to list attributes: %s", err)
 			}
-			if status != "" && string(risk.Status[0]) != status {
+			return listing{risks: filtered, scored: model.NewScorer().Rank(filtered, assets, attributes)}, nil
+		})
+
+		type row struct {
+			account string
+			model.ScoredRisk
+		}
+
+		var counts fanout.Counts
+		var rows []row
+		for _, result := range results {
+			if result.Err != nil {
+				cmd.Printf("Failed to list risks%s: %s\n", fanout.For(result.Account), result.Err)
 				continue
 			}
-			if source != "" && strings.ToLower(risk.Source) != strings.ToLower(source) {
+			counts.Add(result.Account, len(result.Value.risks))
+
+			if sortBy == "score" {
+				for _, scored := range result.Value.scored {
+					rows = append(rows, row{account: result.Account, ScoredRisk: scored})
+				}
 				continue
 			}
+			for _, risk := range result.Value.risks {
+				if details {
+					cmd.Printf("%s\n", fanout.JSON(result.Account, risk))
+				} else {
+					cmd.Printf("%s%s\n", fanout.Prefix(result.Account), risk.Key)
+				}
+			}
+		}
+
+		// scores are comparable across accounts, so the ranking is global
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i].Score > rows[j].Score })
+		for _, scored := range rows {
 			if details {
-				json, _ := json.Marshal(risk)
-				cmd.Printf("%s\n", json)
+				cmd.Printf("%s\n", fanout.JSON(scored.account, scored.ScoredRisk))
 			} else {
-				cmd.Printf("%s\n", risk.Key)
+				cmd.Printf("%s%.2f %s\n", fanout.Prefix(scored.account), scored.Score, scored.Key)
 			}
 		}
+		counts.Print(cmd, "risks")
 	},
 
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		sortBy, _ := cmd.Flags().GetString("sort")
+		if sortBy != "" && sortBy != "score" {
+			return fmt.Errorf("invalid sort order: %s", sortBy)
+		}
 		severity, _ := cmd.Flags().GetString("severity")
 		if severity != "" {
 			switch severity {
@@ -71,4 +151,6 @@ func init() {
 	listCmd.Flags().String("severity", "", "Filter the list of risks by severity (I, L, M, H, C)")
 	listCmd.Flags().String("status", "", "Filter the list of risks by status (T, O, C, M)")
 	listCmd.Flags().Bool("details", false, "Show detailed information about each risk")
+	listCmd.Flags().String("sort", "", "Sort the list of risks (score)")
+	fanout.AddFlags(listCmd)
 }
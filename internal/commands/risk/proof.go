@@ -1,6 +1,7 @@
 package risk
 
 import (
+	"context"
 	"os"
 
 	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
@@ -36,13 +37,20 @@ var proofUploadCmd = &cobra.Command{
 			return
 		}
 
-		data, err := os.ReadFile(file)
+		f, err := os.Open(file)
 		if err != nil {
 			cmd.PrintErrf("Failed to read file: %v\n", err)
 			return
 		}
+		defer f.Close()
 
-		err = Client.UploadPoE(*risk, data)
+		info, err := f.Stat()
+		if err != nil {
+			cmd.PrintErrf("Failed to read file: %v\n", err)
+			return
+		}
+
+		err = Client.UploadReader(context.Background(), risk.Proof(nil).Name, f, info.Size())
 		if err != nil {
 			cmd.PrintErrf("Failed to upload PoE: %v\n", err)
 			return
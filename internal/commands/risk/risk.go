@@ -32,6 +32,8 @@ func init() {
 		listCmd,
 		proofCmd,
 		definitionCmd,
+		exportCmd,
+		slaCmd,
 	)
 }
 
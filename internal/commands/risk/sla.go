@@ -0,0 +1,126 @@
+package risk
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/internal/commands/fanout"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/report"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/sla"
+
+	"github.com/spf13/cobra"
+)
+
+var slaCmd = &cobra.Command{
+	Use:   "sla",
+	Short: "Report overdue risks and time to remediate against your SLA",
+	Long: `Measure every risk against a remediation SLA per severity. Time to open runs
+from creation until the risk was first opened, time open from then until it was
+remediated, or until now, following the status history of each risk.
+
+The report lists the open risks past their due date, most overdue first, and
+per severity and source capability the open, overdue and remediated risks, the
+remediated risks that missed their due date, and the mean time to open and to
+remediate.
+
+The default policy allows 7 days for critical risks, 30 for high, 90 for medium
+and 180 for low, counted from creation. A policy file can change the deadlines,
+and start the clock when a risk is first opened instead:
+
+  start: opened
+  deadlines:
+    critical: 3d
+    high: 2w
+    medium: 60d
+
+Example Usages:
+	chariot risk sla
+	chariot risk sla --policy sla.yaml
+	chariot risk sla --overdue
+	chariot risk sla --all-accounts`,
+	Run: func(cmd *cobra.Command, args []string) {
+		policyFile, _ := cmd.Flags().GetString("policy")
+		overdueOnly, _ := cmd.Flags().GetBool("overdue")
+
+		policy := sla.DefaultPolicy
+		if policyFile != "" {
+			var err error
+			if policy, err = sla.LoadPolicy(policyFile); err != nil {
+				cmd.PrintErrf("Failed to load %s: %v\n", policyFile, err)
+				return
+			}
+		}
+
+		accounts, err := fanout.Accounts(cmd, Client)
+		if err != nil {
+			cmd.PrintErrf("Failed to list accounts: %v\n", err)
+			return
+		}
+
+		var counts fanout.Counts
+		for _, result := range sdk.FanOut(Client, accounts, func(client *sdk.Chariot) (*sla.Report, error) {
+			return sla.Build(client, policy)
+		}) {
+			if result.Err != nil {
+				cmd.PrintErrf("Failed to measure risks%s: %v\n", fanout.For(result.Account), result.Err)
+				continue
+			}
+			if result.Account != "" {
+				cmd.Printf("Account: %s\n\n", result.Account)
+			}
+			printOverdue(cmd.OutOrStdout(), result.Value)
+			if !overdueOnly {
+				printGroups(cmd.OutOrStdout(), "SEVERITY", result.Value.Severities)
+				printGroups(cmd.OutOrStdout(), "SOURCE", result.Value.Sources)
+			}
+			counts.Add(result.Account, len(result.Value.Overdue))
+		}
+		counts.Print(cmd, "overdue risks")
+	},
+}
+
+func printOverdue(out io.Writer, r *sla.Report) {
+	if len(r.Overdue) == 0 {
+		fmt.Fprintf(out, "No overdue risks\n\n")
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SEVERITY\tRISK\tDNS\tSOURCE\tOPEN\tSLA\tOVERDUE")
+	for _, m := range r.Overdue {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", report.SeverityName(m.Risk.Severity()), m.Risk.Name, m.Risk.DNS, m.Risk.Source,
+			days(m.TimeOpen), days(m.Deadline), days(m.OverdueBy(r.Generated)))
+	}
+	w.Flush()
+	fmt.Fprintln(out)
+}
+
+func printGroups(out io.Writer, heading string, groups []sla.Group) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tOPEN\tOVERDUE\tREMEDIATED\tLATE\tMEAN TIME TO OPEN\tMEAN TIME TO REMEDIATE\n", heading)
+	for _, g := range groups {
+		mtto, mttr := "-", "-"
+		if g.Opened > 0 {
+			mtto = days(g.MeanTimeToOpen)
+		}
+		if g.Remediated > 0 {
+			mttr = days(g.MeanTimeToRemediate)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%s\t%s\n", g.Name, g.Open, g.Overdue, g.Remediated, g.Late, mtto, mttr)
+	}
+	w.Flush()
+	fmt.Fprintln(out)
+}
+
+// days formats a duration in days, e.g. 3.5d
+func days(d time.Duration) string {
+	return fmt.Sprintf("%.1fd", d.Hours()/24)
+}
+
+func init() {
+	slaCmd.Flags().String("policy", "", "YAML file with the SLA policy (default is 7d critical, 30d high, 90d medium, 180d low)")
+	slaCmd.Flags().Bool("overdue", false, "Only list overdue risks")
+	fanout.AddFlags(slaCmd)
+}
@@ -1,6 +1,7 @@
 package risk
 
 import (
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
 	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
 
 	"github.com/spf13/cobra"
@@ -9,33 +10,51 @@ import (
 var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Update the status or severity of a risk",
-	Long:  `When a risk needs to have its status changed (such as closing a risk or modifying its severity), this is the command to use.`,
+	Long: `When a risk needs to have its status changed (such as closing a risk or modifying its severity), this is the command to use.
+Use --keys-file to apply the same status to every risk listed in a file, one key per line.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		key := cmd.Flag("key").Value.String()
+		keysFile := cmd.Flag("keys-file").Value.String()
 		status := cmd.Flag("status").Value.String()
 
-		risk, err := model.GetRiskFromKey(key)
-		if err != nil {
-			cmd.PrintErrf("Failed to get risk from key: %v\n", err)
-			return
+		keys := []string{key}
+		if keysFile != "" {
+			var err error
+			keys, err = sdk.ReadKeysFile(keysFile)
+			if err != nil {
+				cmd.PrintErrf("Failed to read keys: %v\n", err)
+				return
+			}
 		}
 
-		// we should probably validate the status but man that'd be a
-		// huge case tree...
-		risk.Status = status
+		risks := make([]model.Risk, 0, len(keys))
+		for _, key := range keys {
+			risk, err := model.GetRiskFromKey(key)
+			if err != nil {
+				cmd.PrintErrf("Failed to get risk from key: %v\n", err)
+				return
+			}
+			// we should probably validate the status but man that'd be a
+			// huge case tree...
+			risk.Status = status
+			risks = append(risks, *risk)
+		}
 
-		err = Client.Risks.Update(*risk)
-		if err != nil {
-			cmd.PrintErrf("Failed to update risk: %v\n", err)
-			return
+		for _, result := range Client.Risks.UpdateMany(risks).Results {
+			if result.Err != nil {
+				cmd.PrintErrf("Failed to update risk %s: %v\n", result.Item.Key, result.Err)
+				continue
+			}
+			cmd.Printf("Risk %s updated successfully\n", result.Item.Key)
 		}
-		cmd.Printf("Risk %s updated successfully\n", key)
 	},
 }
 
 func init() {
 	updateCmd.Flags().String("status", "", "Status of the risk, can be...")
 	updateCmd.Flags().String("key", "", "Key of the asset")
+	updateCmd.Flags().String("keys-file", "", "File with one risk key per line, - for stdin")
 	updateCmd.MarkFlagRequired("status")
-	updateCmd.MarkFlagRequired("key")
+	updateCmd.MarkFlagsOneRequired("key", "keys-file")
+	updateCmd.MarkFlagsMutuallyExclusive("key", "keys-file")
 }
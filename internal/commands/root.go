@@ -4,18 +4,27 @@ import (
 	"fmt"
 	"github.com/praetorian-inc/chariot-client/internal/commands/account"
 	"github.com/spf13/viper"
+	"io"
+	"log/slog"
 	"os"
+	"strings"
 
+	"github.com/praetorian-inc/chariot-client/internal/commands/apply"
 	"github.com/praetorian-inc/chariot-client/internal/commands/asset"
 	"github.com/praetorian-inc/chariot-client/internal/commands/attribute"
+	"github.com/praetorian-inc/chariot-client/internal/commands/digest"
 	"github.com/praetorian-inc/chariot-client/internal/commands/file"
+	"github.com/praetorian-inc/chariot-client/internal/commands/integrate"
 	"github.com/praetorian-inc/chariot-client/internal/commands/job"
+	"github.com/praetorian-inc/chariot-client/internal/commands/plan"
+	"github.com/praetorian-inc/chariot-client/internal/commands/report"
 	"github.com/praetorian-inc/chariot-client/internal/commands/risk"
 	"github.com/praetorian-inc/chariot-client/internal/commands/search"
 	"github.com/praetorian-inc/chariot-client/internal/commands/webhook"
 	"github.com/praetorian-inc/chariot-client/pkg/sdk"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
@@ -23,6 +32,7 @@ var (
 	profileOverride string
 	cfgFile         string
 	Chariot         *sdk.Chariot
+	tracer          *sdk.Tracer
 )
 
 var rootCmd = &cobra.Command{
@@ -32,12 +42,63 @@ var rootCmd = &cobra.Command{
 }
 
 func Execute() {
+	initConfig()
+	addCommands(Chariot)
+
 	err := rootCmd.Execute()
+	if harErr := writeHAR(); harErr != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write HAR file: %v\n", harErr)
+	}
 	if err != nil {
 		os.Exit(1)
 	}
 }
 
+// ExecuteWith runs the CLI against an existing client instead of one built
+// from the keychain, writing all output to out. Tests use it to drive the
+// commands against a chariottest server. Flags are reset to their defaults
+// first, so every call only sees its own arguments.
+func ExecuteWith(client *sdk.Chariot, out io.Writer, args ...string) error {
+	Chariot = client
+	tracer = nil
+	addCommands(Chariot)
+	resetFlags(rootCmd)
+
+	rootCmd.SetOut(out)
+	rootCmd.SetErr(out)
+	rootCmd.ParseFlags(args)
+	applyFlags(Chariot)
+
+	rootCmd.SetArgs(args)
+	err := rootCmd.Execute()
+	if harErr := writeHAR(); harErr != nil && err == nil {
+		err = harErr
+	}
+	return err
+}
+
+// resetFlags restores the flags of cmd and its subcommands to their defaults,
+// since cobra keeps parsed values in the command tree between executions
+func resetFlags(cmd *cobra.Command) {
+	reset := func(flag *pflag.Flag) {
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			var values []string
+			if def := strings.Trim(flag.DefValue, "[]"); def != "" {
+				values = strings.Split(def, ",")
+			}
+			slice.Replace(values)
+		} else {
+			flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, child := range cmd.Commands() {
+		resetFlags(child)
+	}
+}
+
 func init() {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 
@@ -46,19 +107,33 @@ func init() {
 
 	rootCmd.PersistentFlags().String("profile", "", "profile name from your keychain (stored at $HOME/.praetorian/keychain.ini) to use")
 	rootCmd.PersistentFlags().String("account", "", "account to perform actions for")
+	rootCmd.PersistentFlags().Bool("encrypt", false, "encrypt uploaded files and proofs with the team key from your keychain")
+	rootCmd.PersistentFlags().Float64("rate", 0, "maximum API requests per second, overriding the profile's rate (0 for unlimited)")
+	rootCmd.PersistentFlags().Bool("debug", false, "log every API request with its status and latency to stderr")
+	rootCmd.PersistentFlags().Bool("trace", false, "like --debug, but also log request and response bodies")
+	rootCmd.PersistentFlags().String("har", "", "write a HAR file of all API traffic to this path, for support tickets")
+}
 
-	initConfig()
-
-	rootCmd.AddCommand(
-		account.Cmd(Chariot),
-		asset.Cmd(Chariot),
-		attribute.Cmd(Chariot),
-		file.Cmd(Chariot),
-		job.Cmd(Chariot),
-		risk.Cmd(Chariot),
-		search.Cmd(Chariot),
-		webhook.Cmd(Chariot),
-	)
+func addCommands(client *sdk.Chariot) {
+	// Cmd points each command group at the client, so it runs on every call
+	commands := []*cobra.Command{
+		account.Cmd(client),
+		apply.Cmd(client),
+		asset.Cmd(client),
+		attribute.Cmd(client),
+		digest.Cmd(client),
+		file.Cmd(client),
+		integrate.Cmd(client),
+		job.Cmd(client),
+		plan.Cmd(client),
+		report.Cmd(client),
+		risk.Cmd(client),
+		search.Cmd(client),
+		webhook.Cmd(client),
+	}
+	if !rootCmd.HasSubCommands() {
+		rootCmd.AddCommand(commands...)
+	}
 }
 
 func initConfig() {
@@ -82,13 +157,51 @@ func initConfig() {
 	}
 
 	profileOverride, _ = rootCmd.PersistentFlags().GetString("profile")
-	accountOverride, _ = rootCmd.PersistentFlags().GetString("account")
 	Chariot = sdk.NewClient(profileOverride)
 	profileName := viper.Get("profile")
 	if profileName != nil && profileOverride != "" {
 		Chariot = sdk.NewClient(profileName.(string))
 	}
+	applyFlags(Chariot)
+}
+
+func applyFlags(client *sdk.Chariot) {
+	accountOverride, _ = rootCmd.PersistentFlags().GetString("account")
 	if accountOverride != "" {
-		Chariot.SetAccount(accountOverride)
+		client.SetAccount(accountOverride)
+	}
+	if encrypt, _ := rootCmd.PersistentFlags().GetBool("encrypt"); encrypt {
+		client.Encrypt = true
+	}
+
+	if rootCmd.PersistentFlags().Changed("rate") {
+		rate, _ := rootCmd.PersistentFlags().GetFloat64("rate")
+		client.SetRateLimit(rate, client.Burst)
+	}
+
+	// credentials are always redacted from logs and HAR files
+	debug, _ := rootCmd.PersistentFlags().GetBool("debug")
+	trace, _ := rootCmd.PersistentFlags().GetBool("trace")
+	har, _ := rootCmd.PersistentFlags().GetString("har")
+	if debug || trace || har != "" {
+		level := slog.LevelDebug
+		if !debug && !trace {
+			level = slog.LevelError + 1
+		}
+		handler := slog.NewTextHandler(rootCmd.ErrOrStderr(), &slog.HandlerOptions{Level: level})
+		tracer = client.Trace(sdk.TraceOptions{Logger: slog.New(handler), Bodies: trace, HAR: har != ""})
+	}
+}
+
+func writeHAR() error {
+	har, _ := rootCmd.PersistentFlags().GetString("har")
+	if tracer == nil || har == "" {
+		return nil
+	}
+	file, err := os.Create(har)
+	if err != nil {
+		return err
 	}
+	defer file.Close()
+	return tracer.WriteHAR(file)
 }
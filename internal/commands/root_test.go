@@ -0,0 +1,277 @@
+package commands
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/chariottest"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+var (
+	acme = model.NewAsset("acme.com", "acme.com")
+	sqli = func() model.Risk {
+		risk := model.NewRisk(acme, "sql-injection")
+		risk.Status = model.OpenHigh
+		return risk
+	}()
+	xss = func() model.Risk {
+		risk := model.NewRisk(acme, "xss")
+		risk.Status = model.OpenMedium
+		return risk
+	}()
+)
+
+// expand replaces {dir} with the test's temporary directory and {hook} with
+// the URL of a server that counts the requests it receives
+func expand(value, dir, hook string) string {
+	return strings.NewReplacer("{dir}", dir, "{hook}", hook).Replace(value)
+}
+
+func TestExecuteWith(t *testing.T) {
+	tests := []struct {
+		name  string
+		seed  []any
+		files map[string]string
+		args  []string
+		want  string
+		check func(t *testing.T, store *chariottest.Store, hooks int64)
+	}{
+		{
+			name: "account add",
+			args: []string{"account", "add", "--email", "alice@acme.com"},
+			check: func(t *testing.T, store *chariottest.Store, _ int64) {
+				if len(store.Accounts) != 1 {
+					t.Errorf("got %d accounts, want 1", len(store.Accounts))
+				}
+			},
+		},
+		{
+			name:  "apply",
+			files: map[string]string{"surface.yaml": "assets:\n  - dns: acme.com\n"},
+			args:  []string{"apply", "--file", "{dir}/surface.yaml"},
+			check: func(t *testing.T, store *chariottest.Store, _ int64) {
+				if _, ok := store.Assets[acme.Key]; !ok {
+					t.Errorf("apply did not add %s", acme.Key)
+				}
+			},
+		},
+		{
+			name: "asset add",
+			args: []string{"asset", "add", "--dns", "acme.com", "--name", "acme.com"},
+			check: func(t *testing.T, store *chariottest.Store, _ int64) {
+				if _, ok := store.Assets[acme.Key]; !ok {
+					t.Errorf("asset add did not add %s", acme.Key)
+				}
+			},
+		},
+		{
+			name:  "asset delete --keys-file",
+			seed:  []any{acme, model.NewAsset("acme.com", "1.2.3.4")},
+			files: map[string]string{"keys.txt": "#asset#acme.com#acme.com\n\n#asset#acme.com#1.2.3.4\n#asset#acme.com#5.6.7.8\n"},
+			args:  []string{"asset", "delete", "--keys-file", "{dir}/keys.txt"},
+			want:  "Failed to delete asset #asset#acme.com#5.6.7.8",
+			check: func(t *testing.T, store *chariottest.Store, _ int64) {
+				for _, asset := range store.Assets {
+					if asset.Status != model.Deleted {
+						t.Errorf("%s is still %s", asset.Key, asset.Status)
+					}
+				}
+			},
+		},
+		{
+			name: "asset list",
+			seed: []any{acme},
+			args: []string{"asset", "list"},
+			want: acme.Key,
+		},
+		{
+			name: "attribute add",
+			seed: []any{acme},
+			args: []string{"attribute", "add", "--name", "port", "--value", "443", "--key", acme.Key},
+			check: func(t *testing.T, store *chariottest.Store, _ int64) {
+				if len(store.Attributes) != 1 {
+					t.Errorf("got %d attributes, want 1", len(store.Attributes))
+				}
+			},
+		},
+		{
+			name: "digest",
+			seed: []any{acme, sqli},
+			args: []string{"digest", "--dry-run"},
+			want: "sql-injection",
+		},
+		{
+			name:  "file upload",
+			files: map[string]string{"proof.txt": "proof of exploit"},
+			args:  []string{"file", "upload", "--name", "proofs/acme.com/sql-injection", "--file", "{dir}/proof.txt"},
+			check: func(t *testing.T, store *chariottest.Store, _ int64) {
+				if got := string(store.Files["proofs/acme.com/sql-injection"].Bytes); got != "proof of exploit" {
+					t.Errorf("uploaded %q", got)
+				}
+			},
+		},
+		{
+			name:  "integrate run",
+			seed:  []any{acme, sqli},
+			files: map[string]string{"integrations.yaml": "checkpoint: {dir}/checkpoint.json\nintegrations:\n  - name: webhook\n    config:\n      url: {hook}\n"},
+			args:  []string{"integrate", "run", "--config", "{dir}/integrations.yaml", "--once"},
+			check: func(t *testing.T, _ *chariottest.Store, hooks int64) {
+				if hooks != 1 {
+					t.Errorf("webhook received %d requests, want 1", hooks)
+				}
+			},
+		},
+		{
+			name: "job add",
+			seed: []any{acme},
+			args: []string{"job", "add", "--capability", "nuclei", "--key", acme.Key},
+			check: func(t *testing.T, store *chariottest.Store, _ int64) {
+				if len(store.Jobs) != 1 {
+					t.Errorf("got %d jobs, want 1", len(store.Jobs))
+				}
+			},
+		},
+		{
+			name:  "plan",
+			files: map[string]string{"surface.yaml": "assets:\n  - dns: acme.com\n"},
+			args:  []string{"plan", "--file", "{dir}/surface.yaml"},
+			want:  "Plan: 1 to add, 0 to change, 0 to remove.",
+			check: func(t *testing.T, store *chariottest.Store, _ int64) {
+				if len(store.Assets) != 0 {
+					t.Errorf("plan added %d assets", len(store.Assets))
+				}
+			},
+		},
+		{
+			name: "report",
+			seed: []any{acme, sqli},
+			args: []string{"report", "--format", "markdown"},
+			want: "sql-injection",
+		},
+		{
+			name: "risk add",
+			seed: []any{acme},
+			args: []string{"risk", "add", "--dns", "acme.com", "--name", "sql-injection"},
+			check: func(t *testing.T, store *chariottest.Store, _ int64) {
+				if _, ok := store.Risks[sqli.Key]; !ok {
+					t.Errorf("risk add did not add %s", sqli.Key)
+				}
+			},
+		},
+		{
+			name:  "risk update --keys-file",
+			seed:  []any{acme, sqli, xss},
+			files: map[string]string{"keys.txt": sqli.Key + "\n" + xss.Key + "\n#risk#acme.com#missing\n"},
+			args:  []string{"risk", "update", "--keys-file", "{dir}/keys.txt", "--status", model.RemediatedHigh},
+			want:  "Failed to update risk #risk#acme.com#missing",
+			check: func(t *testing.T, store *chariottest.Store, _ int64) {
+				for _, key := range []string{sqli.Key, xss.Key} {
+					if got := store.Risks[key].Status; got != model.RemediatedHigh {
+						t.Errorf("%s is %s, want %s", key, got, model.RemediatedHigh)
+					}
+				}
+			},
+		},
+		{
+			name: "risk list",
+			seed: []any{acme, sqli},
+			args: []string{"risk", "list"},
+			want: sqli.Key,
+		},
+		{
+			name: "search",
+			seed: []any{acme, sqli},
+			args: []string{"search", "--term", "#risk"},
+			want: sqli.Key,
+		},
+		{
+			name: "webhook generate",
+			args: []string{"webhook", "generate"},
+			want: "/hook/",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var hooks atomic.Int64
+			hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hooks.Add(1)
+			}))
+			defer hook.Close()
+			server := chariottest.NewServer()
+			defer server.Close()
+			server.Seed("", test.seed...)
+
+			dir := t.TempDir()
+			for name, content := range test.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(expand(content, dir, hook.URL)), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var args []string
+			for _, arg := range test.args {
+				args = append(args, expand(arg, dir, hook.URL))
+			}
+
+			var out bytes.Buffer
+			if err := ExecuteWith(server.NewClient(), &out, args...); err != nil {
+				t.Fatalf("ExecuteWith(%q): %v\n%s", args, err, out.String())
+			}
+			if !strings.Contains(out.String(), test.want) {
+				t.Errorf("output does not contain %q:\n%s", test.want, out.String())
+			}
+			if test.check != nil {
+				test.check(t, server.Store(""), hooks.Load())
+			}
+		})
+	}
+}
+
+func TestExecuteWithResetsFlags(t *testing.T) {
+	server := chariottest.NewServer()
+	defer server.Close()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "proof.txt"), []byte("proof of exploit"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	sync := []string{"file", "sync", "--local", dir, "--remote", "proofs/", "--direction", "up"}
+	if err := ExecuteWith(server.NewClient(), &out, append(sync, "--dry-run")...); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(server.Store("").Files) != 0 {
+		t.Fatalf("dry run uploaded %d files", len(server.Store("").Files))
+	}
+
+	// --dry-run must not carry over from the previous call
+	if err := ExecuteWith(server.NewClient(), &out, sync...); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if len(server.Store("").Files) != 1 {
+		t.Fatalf("sync uploaded %d files, want 1:\n%s", len(server.Store("").Files), out.String())
+	}
+
+	// and neither must the persistent --account flag
+	server.Seed("other@acme.com", acme)
+	out.Reset()
+	if err := ExecuteWith(server.NewClient(), &out, "asset", "list", "--account", "other@acme.com"); err != nil {
+		t.Fatalf("asset list: %v", err)
+	}
+	if !strings.Contains(out.String(), acme.Key) {
+		t.Fatalf("asset list --account other@acme.com does not list %s:\n%s", acme.Key, out.String())
+	}
+	out.Reset()
+	if err := ExecuteWith(server.NewClient(), &out, "asset", "list"); err != nil {
+		t.Fatalf("asset list: %v", err)
+	}
+	if strings.Contains(out.String(), acme.Key) {
+		t.Fatalf("asset list still uses the account of the previous call:\n%s", out.String())
+	}
+}
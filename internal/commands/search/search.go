@@ -1,10 +1,11 @@
 package search
 
 import (
-	"encoding/json"
 	"reflect"
 
+	"github.com/praetorian-inc/chariot-client/internal/commands/fanout"
 	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
 
 	"github.com/spf13/cobra"
 )
@@ -24,54 +25,68 @@ var searchCmd = &cobra.Command{
 Example Usages:
 	chariot search --term name:test
 	chariot search --term source:provided --details
-	chariot search --term dns:example.com`,
+	chariot search --term dns:example.com
+	chariot search --term name:CVE-2024-3400 --all-accounts`,
 
 	Run: func(cmd *cobra.Command, args []string) {
 		showDetails, _ := cmd.Flags().GetBool("details")
 
-		results, err := Client.Search(cmd.Flag("term").Value.String())
+		accounts, err := fanout.Accounts(cmd, Client)
 		if err != nil {
-			cmd.Printf("Failed to search: %v\n", err)
+			cmd.Printf("Failed to list accounts: %v\n", err)
 			return
 		}
 
-		var items []interface{}
+		term := cmd.Flag("term").Value.String()
+		var counts fanout.Counts
+		for _, result := range sdk.FanOut(Client, accounts, func(client *sdk.Chariot) (*model.SearchResult, error) {
+			return client.Search(term)
+		}) {
+			if result.Err != nil {
+				cmd.Printf("Failed to search%s: %v\n", fanout.For(result.Account), result.Err)
+				continue
+			}
+			results := result.Value
 
-		for _, a := range results.Assets {
-			items = append(items, a)
-		}
-		for _, a := range results.Accounts {
-			items = append(items, a)
-		}
-		for _, a := range results.Attributes {
-			items = append(items, a)
-		}
-		for _, j := range results.Jobs {
-			items = append(items, j)
-		}
-		for _, r := range results.Risks {
-			items = append(items, r)
-		}
-		for _, f := range results.Files {
-			items = append(items, f)
-		}
+			var items []interface{}
+
+			for _, a := range results.Assets {
+				items = append(items, a)
+			}
+			for _, a := range results.Accounts {
+				items = append(items, a)
+			}
+			for _, a := range results.Attributes {
+				items = append(items, a)
+			}
+			for _, j := range results.Jobs {
+				items = append(items, j)
+			}
+			for _, r := range results.Risks {
+				items = append(items, r)
+			}
+			for _, f := range results.Files {
+				items = append(items, f)
+			}
 
-		for _, item := range items {
-			if showDetails {
-				json, _ := json.Marshal(item)
-				cmd.Printf("%s\n", json)
-			} else {
-				var key string
-				v := reflect.ValueOf(item)
-				field := v.FieldByName("Key")
-				if field.IsValid() && field.Kind() == reflect.String {
-					key = field.String()
+			for _, item := range items {
+				if showDetails {
+					cmd.Printf("%s\n", fanout.JSON(result.Account, item))
 				} else {
-					continue
+					var key string
+					v := reflect.ValueOf(item)
+					field := v.FieldByName("Key")
+					if field.IsValid() && field.Kind() == reflect.String {
+						key = field.String()
+					} else {
+						continue
+					}
+					cmd.Printf("%s%s\n", fanout.Prefix(result.Account), key)
 				}
-				cmd.Printf("%s\n", key)
 			}
+			counts.Add(result.Account, len(items))
 		}
+		counts.Print(cmd, "results")
 	},
 }
 
@@ -80,4 +95,5 @@ func init() {
 	searchCmd.Flags().String("term", "", "Search term to use (required)")
 
 	searchCmd.MarkFlagRequired("term")
+	fanout.AddFlags(searchCmd)
 }
@@ -1,19 +1,40 @@
 package webhook
 
 import (
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+
 	"github.com/spf13/cobra"
 )
 
 var generateCmd = &cobra.Command{
 	Use:   "generate",
-	Short: "Generate a new webhook. This will replace older webooks, if they exist.",
-	Long: `Create a webhook for posting content into Chariot.
+	Short: "Generate a new webhook",
+	Long: `Create a webhook for posting content into Chariot. With --name the webhook is
+stored under that name, so it can be shown, rotated and revoked by name. An
+existing webhook is only replaced with --force; rotate also replaces its pin.
+Either way the old URL stops working immediately.
 
 Example Usages:
   chariot webhook generate
+  chariot webhook generate --name github-actions
 `,
 	Run: func(cmd *cobra.Command, args []string) {
-		webhook, err := Client.Accounts.AddWebhook()
+		name, _ := cmd.Flags().GetString("name")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if !force {
+			_, exists, err := Client.Accounts.GetWebhook(name)
+			if err != nil {
+				cmd.PrintErrf("Failed to generate webhook: %v\n", err)
+				return
+			}
+			if exists {
+				cmd.PrintErrf("Failed to generate webhook: %s already exists, use 'chariot webhook rotate --name %s' or --force to replace it\n", name, name)
+				return
+			}
+		}
+
+		webhook, err := Client.Accounts.AddNamedWebhook(name)
 		if err != nil {
 			cmd.PrintErrf("Failed to generate webhook: %v\n", err)
 			return
@@ -21,3 +42,8 @@ Example Usages:
 		cmd.Printf("Webhook generated: %s\n", webhook)
 	},
 }
+
+func init() {
+	generateCmd.Flags().String("name", sdk.DefaultWebhook, "Name of the webhook")
+	generateCmd.Flags().Bool("force", false, "Replace an existing webhook of the same name, invalidating its pin immediately")
+}
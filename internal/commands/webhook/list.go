@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List your webhooks",
+	Long: `List every webhook with its name, URL and when it was last updated. The
+old URL of a webhook rotated with a grace period is listed as <name>.previous
+with when it expires.
+
+Example Usages:
+  chariot webhook list`,
+	Run: func(cmd *cobra.Command, args []string) {
+		webhooks, err := Client.Accounts.Webhooks()
+		if err != nil {
+			cmd.PrintErrf("Failed to list webhooks: %v\n", err)
+			return
+		}
+		if len(webhooks) == 0 {
+			cmd.Printf("No existing webhook found, try using 'chariot webhook generate'\n")
+			return
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tURL\tUPDATED\tEXPIRES")
+		for _, webhook := range webhooks {
+			expires := ""
+			if !webhook.Expires.IsZero() {
+				expires = webhook.Expires.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", webhook.Name, webhook.URL, webhook.Updated, expires)
+		}
+		w.Flush()
+	},
+}
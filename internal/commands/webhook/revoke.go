@@ -0,0 +1,28 @@
+package webhook
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var revokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Revoke a webhook",
+	Long: `Delete a webhook. Its URL stops working immediately.
+
+Example Usages:
+  chariot webhook revoke --name github-actions`,
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+
+		if err := Client.Accounts.RevokeWebhook(name); err != nil {
+			cmd.PrintErrf("Failed to revoke webhook: %v\n", err)
+			return
+		}
+		cmd.Printf("Webhook %s revoked\n", name)
+	},
+}
+
+func init() {
+	revokeCmd.Flags().String("name", "", "Name of the webhook (required)")
+	revokeCmd.MarkFlagRequired("name")
+}
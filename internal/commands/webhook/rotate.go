@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+
+	"github.com/spf13/cobra"
+)
+
+var rotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Give a webhook a new pin",
+	Long: `Replace the pin of an existing webhook. The previous URL stops working
+immediately, so update every sender with the new URL. With --grace the previous
+URL keeps working as <name>.previous for that long, so senders can move over.
+
+Example Usages:
+  chariot webhook rotate
+  chariot webhook rotate --name github-actions
+  chariot webhook rotate --name github-actions --grace 24h`,
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		grace, _ := cmd.Flags().GetDuration("grace")
+
+		webhook, err := Client.Accounts.RotateWebhook(name, grace)
+		if err != nil {
+			cmd.PrintErrf("Failed to rotate webhook: %v\n", err)
+			return
+		}
+		cmd.Printf("Webhook rotated: %s\n", webhook.URL)
+		if grace > 0 {
+			cmd.Printf("The previous URL keeps working until %s\n", time.Now().Add(grace).UTC().Format(time.RFC3339))
+		}
+	},
+}
+
+func init() {
+	rotateCmd.Flags().String("name", sdk.DefaultWebhook, "Name of the webhook")
+	rotateCmd.Flags().Duration("grace", 0, "How long the previous URL keeps working (e.g., 24h)")
+}
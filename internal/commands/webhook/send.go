@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"context"
+	"os"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/webhook"
+
+	"github.com/spf13/cobra"
+)
+
+var sendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Send assets, risks and attributes through a webhook",
+	Long: `Report findings into Chariot through a webhook. Only the webhook URL is needed,
+so scanners and CI jobs can report without keychain credentials. The URL is
+read from --url or the CHARIOT_WEBHOOK environment variable, and otherwise
+the webhook given by --name is looked up with your keychain credentials.
+
+The file holds one JSON object per line. dns and name identify an asset, which
+is created when needed. Add finding (and optionally status and comment) to
+report a risk on it, or attribute and value to add an attribute to it:
+
+  {"dns": "acme.com", "name": "1.2.3.4"}
+  {"dns": "acme.com", "name": "1.2.3.4", "finding": "CVE-2024-3400", "status": "TH"}
+  {"dns": "acme.com", "name": "1.2.3.4", "attribute": "port", "value": "443"}
+
+Example Usages:
+  chariot webhook send --file findings.jsonl
+  scanner --jsonl | chariot webhook send --file - --url "$CHARIOT_WEBHOOK"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, _ := cmd.Flags().GetString("file")
+		hook, _ := cmd.Flags().GetString("url")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		name, _ := cmd.Flags().GetString("name")
+
+		input := os.Stdin
+		if path != "-" {
+			f, err := os.Open(path)
+			if err != nil {
+				cmd.PrintErrf("Failed to open %s: %v\n", path, err)
+				return
+			}
+			defer f.Close()
+			input = f
+		}
+		payloads, err := webhook.ReadPayloads(input)
+		if err != nil {
+			cmd.PrintErrf("Failed to read %s: %v\n", path, err)
+			return
+		}
+
+		if hook == "" {
+			hook = os.Getenv("CHARIOT_WEBHOOK")
+		}
+		if hook == "" {
+			webhook, ok, err := Client.Accounts.GetWebhook(name)
+			if err != nil {
+				cmd.PrintErrf("Failed to find webhook: %v\n", err)
+				return
+			}
+			hook = webhook.URL
+			if !ok {
+				cmd.PrintErrf("No existing webhook found, try using 'chariot webhook generate'\n")
+				return
+			}
+		}
+
+		client, err := webhook.New(hook)
+		if err != nil {
+			cmd.PrintErrf("Failed to create webhook client: %v\n", err)
+			return
+		}
+		client.Concurrency = concurrency
+		client.Limiter = Client.Limiter
+
+		failed := 0
+		for i, err := range client.SendAll(context.Background(), payloads) {
+			if err != nil {
+				failed++
+				cmd.PrintErrf("Failed to send %s/%s: %v\n", payloads[i].DNS, payloads[i].Name, err)
+			}
+		}
+		cmd.Printf("Sent %d of %d items\n", len(payloads)-failed, len(payloads))
+	},
+}
+
+func init() {
+	sendCmd.Flags().String("file", "", "JSON lines file of items to send, - for stdin (required)")
+	sendCmd.Flags().String("url", "", "Webhook URL (default is $CHARIOT_WEBHOOK, then your current webhook)")
+	sendCmd.Flags().String("name", sdk.DefaultWebhook, "Name of the webhook to look up when no URL is given")
+	sendCmd.Flags().Int("concurrency", 4, "Number of items to send in parallel")
+	sendCmd.MarkFlagRequired("file")
+}
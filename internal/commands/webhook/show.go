@@ -1,35 +1,36 @@
 package webhook
 
 import (
-	"encoding/base64"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+
 	"github.com/spf13/cobra"
-	"strings"
 )
 
 var showCmd = &cobra.Command{
 	Use:   "show",
-	Short: "Show the current webhook",
-	Long: `Show the current webhook into chariot, if it has been created.
+	Short: "Show a webhook",
+	Long: `Show a webhook into chariot, if it has been created.
 
 Example Usages:
   chariot webhook show
+  chariot webhook show --name github-actions
 `,
 	Run: func(cmd *cobra.Command, args []string) {
-		accounts, err := Client.Accounts.List()
+		name, _ := cmd.Flags().GetString("name")
 
-		for _, account := range accounts {
-			if account.Member == "hook" {
-				pin := account.Config["pin"]
-				username := base64.StdEncoding.EncodeToString([]byte(Client.Username))
-				encodedUsername := strings.TrimRight(username, "=")
-				cmd.Printf("Webhook: %s/hook/%s/%s", Client.API, encodedUsername, pin)
-				return
-			}
-		}
+		webhook, ok, err := Client.Accounts.GetWebhook(name)
 		if err != nil {
-			cmd.PrintErrf("Failed to add webhook: %v\n", err)
+			cmd.PrintErrf("Failed to show webhook: %v\n", err)
+			return
+		}
+		if !ok {
+			cmd.Printf("No existing webhook found, try using 'chariot webhook generate'\n")
 			return
 		}
-		cmd.Printf("No existing webhook found, try using 'chariot webhook add'\n")
+		cmd.Printf("Webhook: %s\n", webhook.URL)
 	},
 }
+
+func init() {
+	showCmd.Flags().String("name", sdk.DefaultWebhook, "Name of the webhook")
+}
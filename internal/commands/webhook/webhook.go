@@ -8,7 +8,7 @@ import (
 
 var jobCmd = &cobra.Command{
 	Use:   "webhook",
-	Short: "Generate or display your Chariot webhook",
+	Short: "Manage your Chariot webhooks and send findings through them",
 	Long:  ``,
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.Help()
@@ -21,6 +21,10 @@ var Client *sdk.Chariot
 func init() {
 	jobCmd.AddCommand(
 		generateCmd,
+		listCmd,
+		revokeCmd,
+		rotateCmd,
+		sendCmd,
 		showCmd,
 	)
 }
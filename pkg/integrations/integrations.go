@@ -0,0 +1,196 @@
+// Package integrations pushes Chariot risks into ticketing, chat and
+// monitoring systems. Each integration implements model.Integration and is
+// configured from the config of an account integration link, so the same
+// values can be managed with chariot plan and apply.
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+// Syncer is implemented by integrations that can report failures. Push has no
+// error return, so it logs them instead.
+type Syncer interface {
+	model.Integration
+	Sync(ctx context.Context, risk model.Risk) error
+}
+
+// Flusher is implemented by integrations that batch risks. Flush sends
+// whatever is pending and should be called before exiting.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// DefinitionUser is implemented by integrations that include the markdown
+// definition of a risk, e.g. in a new ticket. The runner passes the account's
+// definitions, such as client.DownloadDefinition.
+type DefinitionUser interface {
+	UseDefinitions(source func(model.Risk) ([]byte, error))
+}
+
+type constructor func(config map[string]string) (Syncer, error)
+
+var registry = map[string]constructor{
+	"jira":       func(config map[string]string) (Syncer, error) { return NewJira(config) },
+	"pagerduty":  func(config map[string]string) (Syncer, error) { return NewPagerDuty(config) },
+	"servicenow": func(config map[string]string) (Syncer, error) { return NewServiceNow(config) },
+	"siem":       func(config map[string]string) (Syncer, error) { return NewSIEM(config) },
+	"slack":      func(config map[string]string) (Syncer, error) { return NewSlack(config) },
+	"teams":      func(config map[string]string) (Syncer, error) { return NewTeams(config) },
+	"webhook":    func(config map[string]string) (Syncer, error) { return NewWebhook(config) },
+}
+
+// New returns the integration registered under name, configured from config
+func New(name string, config map[string]string) (Syncer, error) {
+	newIntegration, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown integration %q, expected one of %v", name, Names())
+	}
+	return newIntegration(config)
+}
+
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var severities = map[string]string{
+	"C": "Critical",
+	"H": "High",
+	"M": "Medium",
+	"L": "Low",
+	"I": "Info",
+}
+
+var states = map[string]string{
+	model.Triage:         "Triage",
+	model.Open:           "Open",
+	model.Remediated:     "Remediated",
+	model.Deleted:        "Deleted",
+	model.MachineOpen:    "Machine Open",
+	model.MachineDeleted: "Machine Deleted",
+}
+
+// describe names a risk status, e.g. "Open (High)"
+func describe(status string) string {
+	if status == "" {
+		return "Unknown"
+	}
+	risk := model.Risk{Status: status}
+	return fmt.Sprintf("%s (%s)", state(risk), severity(risk))
+}
+
+func state(risk model.Risk) string {
+	if risk.Status == "" {
+		return "Unknown"
+	}
+	if name, ok := states[risk.State()]; ok {
+		return name
+	}
+	return risk.State()
+}
+
+func severity(risk model.Risk) string {
+	if risk.Status == "" {
+		return "Unknown"
+	}
+	if name, ok := severities[risk.Severity()]; ok {
+		return name
+	}
+	return risk.Severity()
+}
+
+// severityRank orders severities from info to critical
+const severityRank = "ILMHC"
+
+// atLeast reports whether the risk is as severe as minimum, a severity code
+func atLeast(risk model.Risk, minimum string) bool {
+	if risk.Status == "" {
+		return false
+	}
+	return strings.Index(severityRank, risk.Severity()) >= strings.Index(severityRank, minimum)
+}
+
+// isOpen reports whether the risk has been confirmed, by a person or a capability
+func isOpen(risk model.Risk) bool {
+	if risk.Status == "" {
+		return false
+	}
+	state := risk.State()
+	return state == model.Open || state == model.MachineOpen
+}
+
+// isClosed reports whether an incident raised for the risk should be resolved:
+// it was remediated or deleted, or moved back to triage after being opened
+func isClosed(risk model.Risk) bool {
+	if risk.Status == "" {
+		return false
+	}
+	switch risk.State() {
+	case model.Remediated, model.Deleted, model.MachineDeleted:
+		return true
+	case model.Triage:
+		for _, event := range risk.History {
+			if isOpen(model.Risk{Status: event.From}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DedupKey identifies a risk in external systems. Risk keys contain characters
+// that labels and correlation fields do not allow, so the key is hashed.
+func DedupKey(risk model.Risk) string {
+	sum := sha256.Sum256([]byte(risk.Key))
+	return "chariot-" + hex.EncodeToString(sum[:8])
+}
+
+// required returns an error naming the first missing config key
+func required(integration string, config map[string]string, keys ...string) error {
+	for _, key := range keys {
+		if config[key] == "" {
+			return fmt.Errorf("%s: %s is required", integration, key)
+		}
+	}
+	return nil
+}
+
+// postJSON posts body to url and fails on any status other than 2xx
+func postJSON(ctx context.Context, client *http.Client, url string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d, %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	return nil
+}
@@ -0,0 +1,328 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+// JiraPriorities maps risk severities to the default Jira priority scheme
+var JiraPriorities = map[string]string{
+	"C": "Highest",
+	"H": "High",
+	"M": "Medium",
+	"L": "Low",
+	"I": "Lowest",
+}
+
+// Jira keeps one issue per open risk. The issue is found again through its
+// DedupKey label, so no state is kept outside Jira. Status changes are added
+// as comments, and the issue is closed when the risk is remediated and
+// reopened when it is seen again.
+type Jira struct {
+	// URL is the Jira base URL, e.g. https://acme.atlassian.net
+	URL string
+	// User and Token authenticate with an API token. Without a user, Token is
+	// sent as a personal access token.
+	User      string
+	Token     string
+	Project   string
+	IssueType string
+	// CloseTransition and ReopenTransition name the workflow transitions used
+	// when a risk is remediated or opened again
+	CloseTransition  string
+	ReopenTransition string
+	// Priorities maps severities to Jira priorities. Priority is not set when
+	// nil, for projects without a priority field.
+	Priorities map[string]string
+	// Definition, when set, loads the markdown definition added to new issues,
+	// e.g. client.DownloadDefinition
+	Definition func(model.Risk) ([]byte, error)
+	HTTPClient *http.Client
+}
+
+// jiraProjectKey matches the project keys Jira allows, e.g. SEC or APP_2
+var jiraProjectKey = regexp.MustCompile(`^[A-Z][A-Z0-9_]+$`)
+
+// NewJira reads url, user, token, project, issue_type, close_transition and
+// reopen_transition from an integration config
+func NewJira(config map[string]string) (*Jira, error) {
+	if err := required("jira", config, "url", "token", "project"); err != nil {
+		return nil, err
+	}
+	if !jiraProjectKey.MatchString(config["project"]) {
+		return nil, fmt.Errorf("jira: invalid project key %q, expected uppercase letters, digits and underscores such as SEC", config["project"])
+	}
+	jira := &Jira{
+		URL:              strings.TrimRight(config["url"], "/"),
+		User:             config["user"],
+		Token:            config["token"],
+		Project:          config["project"],
+		IssueType:        config["issue_type"],
+		CloseTransition:  config["close_transition"],
+		ReopenTransition: config["reopen_transition"],
+		Priorities:       JiraPriorities,
+		HTTPClient:       &http.Client{},
+	}
+	if jira.IssueType == "" {
+		jira.IssueType = "Task"
+	}
+	if jira.CloseTransition == "" {
+		jira.CloseTransition = "Done"
+	}
+	if jira.ReopenTransition == "" {
+		jira.ReopenTransition = "To Do"
+	}
+	return jira, nil
+}
+
+func (j *Jira) UseDefinitions(source func(model.Risk) ([]byte, error)) {
+	j.Definition = source
+}
+
+func (j *Jira) Push(risk model.Risk) {
+	if err := j.Sync(context.Background(), risk); err != nil {
+		slog.Error("jira sync failed", "risk", risk.Key, "error", err)
+	}
+}
+
+// Sync creates, updates, closes or reopens the issue of a risk. Risks that
+// were never opened do not get an issue.
+func (j *Jira) Sync(ctx context.Context, risk model.Risk) error {
+	issue, ok, err := j.find(ctx, risk)
+	if err != nil {
+		return fmt.Errorf("failed to search issues: %v", err)
+	}
+	if !ok {
+		if !isOpen(risk) {
+			return nil
+		}
+		if issue, err = j.create(ctx, risk); err != nil {
+			return fmt.Errorf("failed to create issue: %v", err)
+		}
+	}
+
+	if err := j.comment(ctx, issue, risk); err != nil {
+		return fmt.Errorf("failed to comment on %s: %v", issue.Key, err)
+	}
+	if priority := j.priority(risk); priority != "" && priority != issue.Fields.Priority.Name {
+		update := map[string]any{"fields": map[string]any{"priority": map[string]string{"name": priority}}}
+		if err := j.do(ctx, "PUT", "/issue/"+issue.Key, update, nil); err != nil {
+			return fmt.Errorf("failed to update %s: %v", issue.Key, err)
+		}
+	}
+
+	done := issue.Fields.Status.StatusCategory.Key == "done"
+	switch {
+	case risk.Is(model.Remediated) && !done:
+		return j.transition(ctx, issue, j.CloseTransition)
+	case isOpen(risk) && done:
+		return j.transition(ctx, issue, j.ReopenTransition)
+	}
+	return nil
+}
+
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Priority struct {
+			Name string `json:"name"`
+		} `json:"priority"`
+		Status struct {
+			Name           string `json:"name"`
+			StatusCategory struct {
+				Key string `json:"key"`
+			} `json:"statusCategory"`
+		} `json:"status"`
+	} `json:"fields"`
+}
+
+func (j *Jira) priority(risk model.Risk) string {
+	if j.Priorities == nil || risk.Status == "" {
+		return ""
+	}
+	return j.Priorities[risk.Severity()]
+}
+
+func (j *Jira) find(ctx context.Context, risk model.Risk) (jiraIssue, bool, error) {
+	query := url.Values{}
+	query.Set("jql", fmt.Sprintf(`project = %s AND labels = %s`, jqlString(j.Project), jqlString(DedupKey(risk))))
+	query.Set("fields", "status,priority")
+
+	var result struct {
+		Issues []jiraIssue `json:"issues"`
+	}
+	if err := j.do(ctx, "GET", "/search?"+query.Encode(), nil, &result); err != nil {
+		return jiraIssue{}, false, err
+	}
+	if len(result.Issues) == 0 {
+		return jiraIssue{}, false, nil
+	}
+	return result.Issues[0], true, nil
+}
+
+// jqlString quotes a JQL string literal
+func jqlString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+func (j *Jira) create(ctx context.Context, risk model.Risk) (jiraIssue, error) {
+	summary := fmt.Sprintf("[Chariot] %s on %s", risk.Name, risk.DNS)
+	if len(summary) > 255 {
+		summary = summary[:255]
+	}
+	fields := map[string]any{
+		"project":     map[string]string{"key": j.Project},
+		"issuetype":   map[string]string{"name": j.IssueType},
+		"summary":     summary,
+		"description": j.description(risk),
+		"labels":      []string{"chariot", DedupKey(risk)},
+	}
+	if priority := j.priority(risk); priority != "" {
+		fields["priority"] = map[string]string{"name": priority}
+	}
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := j.do(ctx, "POST", "/issue", map[string]any{"fields": fields}, &created); err != nil {
+		return jiraIssue{}, err
+	}
+	// read the issue back for the status its workflow starts in
+	var issue jiraIssue
+	if err := j.do(ctx, "GET", "/issue/"+created.Key+"?fields=status,priority", nil, &issue); err != nil {
+		return jiraIssue{}, err
+	}
+	return issue, nil
+}
+
+func (j *Jira) description(risk model.Risk) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Chariot found *%s* on %s.\n\n", risk.Name, risk.DNS)
+	fmt.Fprintf(&b, "*Status:* %s\n", describe(risk.Status))
+	if risk.Source != "" {
+		fmt.Fprintf(&b, "*Source:* %s\n", risk.Source)
+	}
+	fmt.Fprintf(&b, "*Created:* %s\n", risk.Created)
+	fmt.Fprintf(&b, "*Proof:* %s\n", risk.Link())
+
+	if j.Definition != nil {
+		definition, err := j.Definition(risk)
+		if err == nil && len(definition) > 0 {
+			fmt.Fprintf(&b, "\n{noformat}\n%s\n{noformat}\n", bytes.TrimSpace(definition))
+		}
+	}
+	return b.String()
+}
+
+// historyComment renders a status change. Existing comments are compared with
+// it, so every change is commented once however often the risk is synced.
+func historyComment(event model.History) string {
+	comment := fmt.Sprintf("Chariot status changed from %s to %s on %s", describe(event.From), describe(event.To), event.Updated)
+	if event.By != "" {
+		comment += " by " + event.By
+	}
+	if event.Comment != "" {
+		comment += ":\n" + event.Comment
+	}
+	return comment
+}
+
+func (j *Jira) comment(ctx context.Context, issue jiraIssue, risk model.Risk) error {
+	if len(risk.History) == 0 {
+		return nil
+	}
+	var result struct {
+		Comments []struct {
+			Body string `json:"body"`
+		} `json:"comments"`
+	}
+	if err := j.do(ctx, "GET", "/issue/"+issue.Key+"/comment?maxResults=1000", nil, &result); err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for _, comment := range result.Comments {
+		existing[comment.Body] = true
+	}
+
+	for _, event := range risk.History {
+		body := historyComment(event)
+		if existing[body] {
+			continue
+		}
+		if err := j.do(ctx, "POST", "/issue/"+issue.Key+"/comment", map[string]string{"body": body}, nil); err != nil {
+			return err
+		}
+		existing[body] = true
+	}
+	return nil
+}
+
+func (j *Jira) transition(ctx context.Context, issue jiraIssue, name string) error {
+	var result struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"transitions"`
+	}
+	if err := j.do(ctx, "GET", "/issue/"+issue.Key+"/transitions", nil, &result); err != nil {
+		return fmt.Errorf("failed to list transitions of %s: %v", issue.Key, err)
+	}
+	for _, transition := range result.Transitions {
+		if strings.EqualFold(transition.Name, name) {
+			body := map[string]any{"transition": map[string]string{"id": transition.ID}}
+			if err := j.do(ctx, "POST", "/issue/"+issue.Key+"/transitions", body, nil); err != nil {
+				return fmt.Errorf("failed to transition %s to %s: %v", issue.Key, name, err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%s has no %q transition from %s", issue.Key, name, issue.Fields.Status.Name)
+}
+
+func (j *Jira) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, j.URL+"/rest/api/2"+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if j.User != "" {
+		req.SetBasicAuth(j.User, j.Token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+j.Token)
+	}
+
+	resp, err := j.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d, %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,188 @@
+package integrations
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/praetorian-inc/chariot-client/pkg/integrations/jiratest"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+func newTestJira(t *testing.T) (*Jira, *jiratest.Server) {
+	t.Helper()
+	server := jiratest.NewServer()
+	t.Cleanup(server.Close)
+	jira, err := NewJira(server.Config())
+	if err != nil {
+		t.Fatalf("NewJira: %v", err)
+	}
+	jira.HTTPClient = server.Client()
+	return jira, server
+}
+
+func testRisk(status string) model.Risk {
+	risk := model.NewRisk(model.NewAsset("acme.com", "acme.com"), "sql-injection")
+	risk.Status = status
+	return risk
+}
+
+// setStatus moves a risk to status, recording the change in its history
+func setStatus(risk *model.Risk, status string) {
+	update := *risk
+	update.Status = status
+	risk.Merge(update)
+}
+
+func syncJira(t *testing.T, jira *Jira, risk model.Risk) {
+	t.Helper()
+	if err := jira.Sync(context.Background(), risk); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+}
+
+func onlyIssue(t *testing.T, server *jiratest.Server) jiratest.Issue {
+	t.Helper()
+	issues := server.Issues()
+	if len(issues) != 1 {
+		t.Fatalf("got %d issues, want 1", len(issues))
+	}
+	return issues[0]
+}
+
+func TestJiraCreate(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		issues   int
+		priority string
+	}{
+		{"open", model.OpenHigh, 1, "High"},
+		{"machine open", model.MachineOpenCritical, 1, "Highest"},
+		{"triage", model.TriageHigh, 0, ""},
+		{"remediated", model.RemediatedHigh, 0, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jira, server := newTestJira(t)
+			risk := testRisk(test.status)
+			syncJira(t, jira, risk)
+
+			issues := server.Issues()
+			if len(issues) != test.issues {
+				t.Fatalf("got %d issues, want %d", len(issues), test.issues)
+			}
+			if test.issues == 0 {
+				return
+			}
+			issue := issues[0]
+			if issue.Priority != test.priority {
+				t.Errorf("priority = %q, want %q", issue.Priority, test.priority)
+			}
+			if !slices.Contains(issue.Labels, DedupKey(risk)) {
+				t.Errorf("labels %v do not contain %s", issue.Labels, DedupKey(risk))
+			}
+			if issue.Summary != "[Chariot] sql-injection on acme.com" {
+				t.Errorf("summary = %q", issue.Summary)
+			}
+		})
+	}
+}
+
+func TestJiraCommentsOnce(t *testing.T) {
+	jira, server := newTestJira(t)
+	risk := testRisk(model.TriageHigh)
+	setStatus(&risk, model.OpenHigh)
+
+	for range 3 {
+		syncJira(t, jira, risk)
+	}
+	if issue := onlyIssue(t, server); len(issue.Comments) != len(risk.History) {
+		t.Fatalf("got %d comments, want %d: %q", len(issue.Comments), len(risk.History), issue.Comments)
+	}
+
+	risk.History = append(risk.History, model.History{From: model.OpenHigh, To: model.OpenHigh, Comment: "still there", Updated: "2026-01-02T00:00:00Z"})
+	syncJira(t, jira, risk)
+	syncJira(t, jira, risk)
+	if issue := onlyIssue(t, server); len(issue.Comments) != len(risk.History) {
+		t.Fatalf("got %d comments, want %d: %q", len(issue.Comments), len(risk.History), issue.Comments)
+	}
+}
+
+func TestJiraUpdatesPriority(t *testing.T) {
+	jira, server := newTestJira(t)
+	risk := testRisk(model.OpenMedium)
+	syncJira(t, jira, risk)
+
+	setStatus(&risk, model.OpenCritical)
+	syncJira(t, jira, risk)
+	if issue := onlyIssue(t, server); issue.Priority != "Highest" {
+		t.Fatalf("priority = %q, want Highest", issue.Priority)
+	}
+}
+
+func TestJiraCloseAndReopen(t *testing.T) {
+	jira, server := newTestJira(t)
+	risk := testRisk(model.OpenHigh)
+	syncJira(t, jira, risk)
+
+	setStatus(&risk, model.RemediatedHigh)
+	syncJira(t, jira, risk)
+	if issue := onlyIssue(t, server); issue.Status != jiratest.Done {
+		t.Fatalf("status after remediation = %v, want Done", issue.Status)
+	}
+
+	setStatus(&risk, model.OpenHigh)
+	syncJira(t, jira, risk)
+	if issue := onlyIssue(t, server); issue.Status != jiratest.ToDo {
+		t.Fatalf("status after reopening = %v, want To Do", issue.Status)
+	}
+}
+
+func TestJiraReopensIssueClosedInJira(t *testing.T) {
+	jira, server := newTestJira(t)
+	risk := testRisk(model.OpenHigh)
+	syncJira(t, jira, risk)
+
+	server.SetStatus(onlyIssue(t, server).Key, jiratest.Done)
+	syncJira(t, jira, risk)
+	if issue := onlyIssue(t, server); issue.Status != jiratest.ToDo {
+		t.Fatalf("status = %v, want To Do", issue.Status)
+	}
+}
+
+func TestNewJiraProject(t *testing.T) {
+	tests := []struct {
+		project string
+		valid   bool
+	}{
+		{"SEC", true},
+		{"APP_2", true},
+		{"sec", false},
+		{"S", false},
+		{`SEC" OR project = "OPS`, false},
+		{"", false},
+	}
+	for _, test := range tests {
+		config := map[string]string{"url": "https://acme.atlassian.net", "token": "token", "project": test.project}
+		if _, err := NewJira(config); (err == nil) != test.valid {
+			t.Errorf("NewJira(project %q) error = %v, want valid %v", test.project, err, test.valid)
+		}
+	}
+}
+
+func TestJQLString(t *testing.T) {
+	tests := []struct {
+		value, want string
+	}{
+		{"SEC", `"SEC"`},
+		{`a"b`, `"a\"b"`},
+		{`a\b`, `"a\\b"`},
+		{`x" OR labels = "y`, `"x\" OR labels = \"y"`},
+	}
+	for _, test := range tests {
+		if got := jqlString(test.value); got != test.want {
+			t.Errorf("jqlString(%q) = %s, want %s", test.value, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+// Package jiratest provides an in-process fake of the Jira REST API v2 with a
+// To Do, In Progress, Done workflow, so the Jira integration can be exercised
+// without a Jira site.
+//
+//	server := jiratest.NewServer()
+//	defer server.Close()
+//	jira, _ := integrations.NewJira(server.Config())
+//	jira.HTTPClient = server.Client()
+package jiratest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+)
+
+const (
+	User    = "jiratest@praetorian.com"
+	Token   = "jiratest-token"
+	Project = "SEC"
+)
+
+type Status struct {
+	Name     string
+	Category string
+}
+
+var (
+	ToDo       = Status{Name: "To Do", Category: "new"}
+	InProgress = Status{Name: "In Progress", Category: "indeterminate"}
+	Done       = Status{Name: "Done", Category: "done"}
+)
+
+// transitions are available from every status but the one they lead to
+var transitions = []struct {
+	ID     string
+	Status Status
+}{
+	{"11", ToDo},
+	{"21", InProgress},
+	{"31", Done},
+}
+
+type Issue struct {
+	Key         string
+	Project     string
+	IssueType   string
+	Summary     string
+	Description string
+	Labels      []string
+	Priority    string
+	Status      Status
+	Comments    []string
+}
+
+type Server struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	issues map[string]*Issue
+	next   int
+}
+
+func NewServer() *Server {
+	s := &Server{issues: make(map[string]*Issue)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/2/search", s.search)
+	mux.HandleFunc("POST /rest/api/2/issue", s.create)
+	mux.HandleFunc("GET /rest/api/2/issue/{key}", s.get)
+	mux.HandleFunc("PUT /rest/api/2/issue/{key}", s.update)
+	mux.HandleFunc("GET /rest/api/2/issue/{key}/comment", s.comments)
+	mux.HandleFunc("POST /rest/api/2/issue/{key}/comment", s.comment)
+	mux.HandleFunc("GET /rest/api/2/issue/{key}/transitions", s.transitions)
+	mux.HandleFunc("POST /rest/api/2/issue/{key}/transitions", s.transition)
+
+	s.Server = httptest.NewServer(s.authenticate(mux))
+	return s
+}
+
+// Config returns the integration config for the fake server
+func (s *Server) Config() map[string]string {
+	return map[string]string{
+		"url":     s.URL,
+		"user":    User,
+		"token":   Token,
+		"project": Project,
+	}
+}
+
+// Issues returns copies of every issue, ordered by key
+func (s *Server) Issues() []Issue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issues := make([]Issue, 0, len(s.issues))
+	for _, issue := range s.issues {
+		copied := *issue
+		copied.Labels = slices.Clone(issue.Labels)
+		copied.Comments = slices.Clone(issue.Comments)
+		issues = append(issues, copied)
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Key < issues[j].Key })
+	return issues
+}
+
+// SetStatus moves an issue as a person working it in Jira would
+func (s *Server) SetStatus(key string, status Status) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if issue, ok := s.issues[key]; ok {
+		issue.Status = status
+	}
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, token, ok := r.BasicAuth()
+		if !ok || user != User || token != Token {
+			if r.Header.Get("Authorization") != "Bearer "+Token {
+				reply(w, http.StatusUnauthorized, errors("unauthorized"))
+				return
+			}
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func reply(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if v != nil {
+		json.NewEncoder(w).Encode(v)
+	}
+}
+
+func errors(messages ...string) map[string]any {
+	return map[string]any{"errorMessages": messages}
+}
+
+func (s *Server) issue(w http.ResponseWriter, r *http.Request) (*Issue, bool) {
+	issue, ok := s.issues[r.PathValue("key")]
+	if !ok {
+		reply(w, http.StatusNotFound, errors("Issue does not exist or you do not have permission to see it."))
+	}
+	return issue, ok
+}
+
+func render(issue *Issue) map[string]any {
+	return map[string]any{
+		"key": issue.Key,
+		"fields": map[string]any{
+			"project":     map[string]string{"key": issue.Project},
+			"issuetype":   map[string]string{"name": issue.IssueType},
+			"summary":     issue.Summary,
+			"description": issue.Description,
+			"labels":      issue.Labels,
+			"priority":    map[string]string{"name": issue.Priority},
+			"status": map[string]any{
+				"name":           issue.Status.Name,
+				"statusCategory": map[string]string{"key": issue.Status.Category},
+			},
+		},
+	}
+}
+
+var (
+	projectClause = regexp.MustCompile(`project\s*=\s*"?([^"\s]+)"?`)
+	labelsClause  = regexp.MustCompile(`labels\s*=\s*"?([^"\s]+)"?`)
+)
+
+// search supports JQL of the form project = X AND labels = Y
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	jql := r.URL.Query().Get("jql")
+	var project, label string
+	if m := projectClause.FindStringSubmatch(jql); m != nil {
+		project = m[1]
+	}
+	if m := labelsClause.FindStringSubmatch(jql); m != nil {
+		label = m[1]
+	}
+
+	keys := make([]string, 0)
+	for key, issue := range s.issues {
+		if project != "" && issue.Project != project {
+			continue
+		}
+		if label != "" && !slices.Contains(issue.Labels, label) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	issues := make([]map[string]any, 0, len(keys))
+	for _, key := range keys {
+		issues = append(issues, render(s.issues[key]))
+	}
+	reply(w, http.StatusOK, map[string]any{"total": len(issues), "issues": issues})
+}
+
+type named struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+type fields struct {
+	Project     *named   `json:"project"`
+	IssueType   *named   `json:"issuetype"`
+	Summary     string   `json:"summary"`
+	Description string   `json:"description"`
+	Labels      []string `json:"labels"`
+	Priority    *named   `json:"priority"`
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Fields fields `json:"fields"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		reply(w, http.StatusBadRequest, errors(err.Error()))
+		return
+	}
+	f := body.Fields
+	if f.Project == nil || f.Project.Key != Project {
+		reply(w, http.StatusBadRequest, map[string]any{"errors": map[string]string{"project": "valid project is required"}})
+		return
+	}
+	if f.IssueType == nil || f.IssueType.Name == "" || f.Summary == "" {
+		reply(w, http.StatusBadRequest, map[string]any{"errors": map[string]string{"summary": "issue type and summary are required"}})
+		return
+	}
+
+	s.next++
+	issue := &Issue{
+		Key:         fmt.Sprintf("%s-%d", Project, s.next),
+		Project:     Project,
+		IssueType:   f.IssueType.Name,
+		Summary:     f.Summary,
+		Description: f.Description,
+		Labels:      f.Labels,
+		Priority:    "Medium",
+		Status:      ToDo,
+	}
+	if f.Priority != nil {
+		issue.Priority = f.Priority.Name
+	}
+	s.issues[issue.Key] = issue
+	reply(w, http.StatusCreated, map[string]string{"key": issue.Key, "self": s.URL + "/rest/api/2/issue/" + issue.Key})
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request) {
+	if issue, ok := s.issue(w, r); ok {
+		reply(w, http.StatusOK, render(issue))
+	}
+}
+
+func (s *Server) update(w http.ResponseWriter, r *http.Request) {
+	issue, ok := s.issue(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Fields fields `json:"fields"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		reply(w, http.StatusBadRequest, errors(err.Error()))
+		return
+	}
+	if body.Fields.Summary != "" {
+		issue.Summary = body.Fields.Summary
+	}
+	if body.Fields.Description != "" {
+		issue.Description = body.Fields.Description
+	}
+	if body.Fields.Priority != nil {
+		issue.Priority = body.Fields.Priority.Name
+	}
+	if body.Fields.Labels != nil {
+		issue.Labels = body.Fields.Labels
+	}
+	reply(w, http.StatusNoContent, nil)
+}
+
+func (s *Server) comments(w http.ResponseWriter, r *http.Request) {
+	issue, ok := s.issue(w, r)
+	if !ok {
+		return
+	}
+	comments := make([]map[string]string, 0, len(issue.Comments))
+	for i, body := range issue.Comments {
+		comments = append(comments, map[string]string{"id": fmt.Sprint(i + 1), "body": body})
+	}
+	reply(w, http.StatusOK, map[string]any{"total": len(comments), "comments": comments})
+}
+
+func (s *Server) comment(w http.ResponseWriter, r *http.Request) {
+	issue, ok := s.issue(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Body == "" {
+		reply(w, http.StatusBadRequest, errors("comment body is required"))
+		return
+	}
+	issue.Comments = append(issue.Comments, body.Body)
+	reply(w, http.StatusCreated, map[string]string{"id": fmt.Sprint(len(issue.Comments)), "body": body.Body})
+}
+
+func (s *Server) transitions(w http.ResponseWriter, r *http.Request) {
+	issue, ok := s.issue(w, r)
+	if !ok {
+		return
+	}
+	available := make([]map[string]string, 0)
+	for _, t := range transitions {
+		if t.Status != issue.Status {
+			available = append(available, map[string]string{"id": t.ID, "name": t.Status.Name})
+		}
+	}
+	reply(w, http.StatusOK, map[string]any{"transitions": available})
+}
+
+func (s *Server) transition(w http.ResponseWriter, r *http.Request) {
+	issue, ok := s.issue(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Transition struct {
+			ID string `json:"id"`
+		} `json:"transition"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		reply(w, http.StatusBadRequest, errors(err.Error()))
+		return
+	}
+	for _, t := range transitions {
+		if t.ID == body.Transition.ID && t.Status != issue.Status {
+			issue.Status = t.Status
+			reply(w, http.StatusNoContent, nil)
+			return
+		}
+	}
+	reply(w, http.StatusBadRequest, errors(fmt.Sprintf("transition %s is not valid for %s", body.Transition.ID, issue.Key)))
+}
@@ -0,0 +1,272 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+const (
+	DefaultBatchSize   = 20
+	DefaultBatchWindow = 30 * time.Second
+	// maxBatchSize keeps a Slack message under its limit of 50 blocks
+	maxBatchSize = 40
+)
+
+// Notification is the data available to message templates
+type Notification struct {
+	Key      string
+	Name     string
+	DNS      string
+	Severity string
+	// State names the status without its severity, e.g. Triage
+	State string
+	// Source is the capability that found the risk, or provided
+	Source  string
+	Created string
+	Link    string
+}
+
+func NewNotification(risk model.Risk) Notification {
+	return Notification{
+		Key:      risk.Key,
+		Name:     risk.Name,
+		DNS:      risk.DNS,
+		Severity: severity(risk),
+		State:    state(risk),
+		Source:   risk.Source,
+		Created:  risk.Created,
+		Link:     risk.Link(),
+	}
+}
+
+// Notifier filters risks and batches them into chat messages, so a large scan
+// produces a few messages rather than one per risk. Slack and Teams differ only
+// in how a batch is rendered.
+type Notifier struct {
+	// MinSeverity is the least severe risk notified, as a severity code
+	MinSeverity string
+	// States limits notifications to risks in these states, e.g. model.Triage
+	States []string
+	// Template renders one risk of a message from a Notification
+	Template *template.Template
+	// A message is sent once BatchSize risks are pending, or BatchWindow after
+	// the first of them. With no window every risk is sent on its own.
+	BatchSize   int
+	BatchWindow time.Duration
+
+	name string
+	send func(ctx context.Context, title string, risks []Notification, texts []string) error
+	// escape, when set, escapes the risk fields rendered into message markup
+	escape func(string) string
+
+	mu      sync.Mutex
+	pending []Notification
+	timer   *time.Timer
+}
+
+// newNotifier reads min_severity, states, template, batch_size and
+// batch_window from an integration config
+func newNotifier(name string, config map[string]string, defaultTemplate string) (*Notifier, error) {
+	n := &Notifier{
+		name:        name,
+		MinSeverity: "H",
+		States:      []string{model.Triage},
+		BatchSize:   DefaultBatchSize,
+		BatchWindow: DefaultBatchWindow,
+	}
+
+	if value := config["min_severity"]; value != "" {
+		code, ok := severityCode(value)
+		if !ok {
+			return nil, fmt.Errorf("%s: invalid min_severity %q, expected critical, high, medium, low or info", name, value)
+		}
+		n.MinSeverity = code
+	}
+	if value := config["states"]; value != "" {
+		n.States = nil
+		for _, state := range strings.Split(value, ",") {
+			code, ok := stateCode(strings.TrimSpace(state))
+			if !ok {
+				return nil, fmt.Errorf("%s: invalid state %q", name, state)
+			}
+			n.States = append(n.States, code)
+		}
+	}
+
+	text := defaultTemplate
+	if value := config["template"]; value != "" {
+		text = value
+	}
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid template: %v", name, err)
+	}
+	n.Template = tmpl
+
+	if value := config["batch_size"]; value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 || size > maxBatchSize {
+			return nil, fmt.Errorf("%s: batch_size must be between 1 and %d", name, maxBatchSize)
+		}
+		n.BatchSize = size
+	}
+	if value := config["batch_window"]; value != "" {
+		window, err := time.ParseDuration(value)
+		if err != nil || window < 0 {
+			return nil, fmt.Errorf("%s: invalid batch_window %q", name, value)
+		}
+		n.BatchWindow = window
+	}
+	return n, nil
+}
+
+func severityCode(value string) (string, bool) {
+	for code, name := range severities {
+		if strings.EqualFold(value, code) || strings.EqualFold(value, name) {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+func stateCode(value string) (string, bool) {
+	for code, name := range states {
+		if value == code || strings.EqualFold(value, name) {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+func title(count int) string {
+	if count == 1 {
+		return "Chariot: 1 risk"
+	}
+	return fmt.Sprintf("Chariot: %d risks", count)
+}
+
+// Matches reports whether a risk passes the severity and state filters
+func (n *Notifier) Matches(risk model.Risk) bool {
+	if risk.Status == "" || !atLeast(risk, n.MinSeverity) {
+		return false
+	}
+	return len(n.States) == 0 || slices.Contains(n.States, risk.State())
+}
+
+func (n *Notifier) Push(risk model.Risk) {
+	if err := n.Sync(context.Background(), risk); err != nil {
+		slog.Error(n.name+" notification failed", "risk", risk.Key, "error", err)
+	}
+}
+
+// Sync queues a matching risk. It only returns send errors when the risk fills
+// the batch; messages sent when the window ends log their errors.
+func (n *Notifier) Sync(ctx context.Context, risk model.Risk) error {
+	if !n.Matches(risk) {
+		return nil
+	}
+	notification := NewNotification(risk)
+
+	n.mu.Lock()
+	// a risk pushed twice in one window is sent once, as last seen
+	index := slices.IndexFunc(n.pending, func(pending Notification) bool { return pending.Key == risk.Key })
+	if index >= 0 {
+		n.pending[index] = notification
+	} else {
+		n.pending = append(n.pending, notification)
+	}
+	full := n.BatchWindow <= 0 || len(n.pending) >= n.BatchSize
+	if !full {
+		n.schedule()
+	}
+	n.mu.Unlock()
+
+	if full {
+		return n.Flush(ctx)
+	}
+	return nil
+}
+
+// Flush sends the pending risks, in messages of at most BatchSize risks. Risks
+// of a message that fails, and of the messages after it, stay pending for the
+// next Flush.
+func (n *Notifier) Flush(ctx context.Context) error {
+	n.mu.Lock()
+	pending := n.pending
+	n.pending = nil
+	if n.timer != nil {
+		n.timer.Stop()
+		n.timer = nil
+	}
+	n.mu.Unlock()
+
+	size := max(n.BatchSize, 1)
+	for len(pending) > 0 {
+		batch := pending[:min(size, len(pending))]
+		pending = pending[len(batch):]
+
+		texts := make([]string, len(batch))
+		for i, notification := range batch {
+			var buf bytes.Buffer
+			if err := n.Template.Execute(&buf, n.escaped(notification)); err != nil {
+				n.requeue(slices.Concat(batch, pending))
+				return fmt.Errorf("failed to render %s: %v", notification.Key, err)
+			}
+			texts[i] = buf.String()
+		}
+		if err := n.send(ctx, title(len(batch)), batch, texts); err != nil {
+			n.requeue(slices.Concat(batch, pending))
+			return err
+		}
+	}
+	return nil
+}
+
+// schedule starts the batch window unless it is already running. The caller
+// holds n.mu.
+func (n *Notifier) schedule() {
+	if n.timer != nil || n.BatchWindow <= 0 {
+		return
+	}
+	n.timer = time.AfterFunc(n.BatchWindow, func() {
+		if err := n.Flush(context.Background()); err != nil {
+			slog.Error(n.name+" notification failed", "error", err)
+		}
+	})
+}
+
+// requeue puts unsent risks back in front of those queued since the flush
+// started, unless a newer notification of the same risk is already pending,
+// and starts a new batch window so they are retried without another Sync
+func (n *Notifier) requeue(unsent []Notification) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	unsent = slices.DeleteFunc(unsent, func(old Notification) bool {
+		return slices.ContainsFunc(n.pending, func(pending Notification) bool { return pending.Key == old.Key })
+	})
+	n.pending = append(unsent, n.pending...)
+	if len(n.pending) > 0 {
+		n.schedule()
+	}
+}
+
+func (n *Notifier) escaped(notification Notification) Notification {
+	if n.escape == nil {
+		return notification
+	}
+	notification.Name = n.escape(notification.Name)
+	notification.DNS = n.escape(notification.DNS)
+	notification.Source = n.escape(notification.Source)
+	notification.Link = n.escape(notification.Link)
+	return notification
+}
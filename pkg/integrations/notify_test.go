@@ -0,0 +1,137 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+// slackSink records the messages posted to it and fails while failing is set
+type slackSink struct {
+	*httptest.Server
+	mu       sync.Mutex
+	failing  bool
+	messages []string
+}
+
+func newSlackSink(t *testing.T) *slackSink {
+	sink := &slackSink{}
+	sink.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sink.mu.Lock()
+		defer sink.mu.Unlock()
+		if sink.failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var message struct {
+			Text string `json:"text"`
+		}
+		json.NewDecoder(r.Body).Decode(&message)
+		sink.messages = append(sink.messages, message.Text)
+	}))
+	t.Cleanup(sink.Close)
+	return sink
+}
+
+func (s *slackSink) setFailing(failing bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failing = failing
+}
+
+func namedRisk(name string) model.Risk {
+	risk := model.NewRisk(model.NewAsset("acme.com", "acme.com"), name)
+	risk.Status = model.TriageCritical
+	return risk
+}
+
+func TestNotifierFlushKeepsUnsentRisks(t *testing.T) {
+	sink := newSlackSink(t)
+	slack, err := NewSlack(map[string]string{"url": sink.URL, "batch_size": "2", "batch_window": "1h"})
+	if err != nil {
+		t.Fatalf("NewSlack: %v", err)
+	}
+	ctx := context.Background()
+
+	sink.setFailing(true)
+	for _, name := range []string{"a", "b", "c"} {
+		slack.Sync(ctx, namedRisk(name))
+	}
+	if err := slack.Flush(ctx); err == nil {
+		t.Fatal("Flush succeeded against a failing webhook")
+	}
+
+	sink.setFailing(false)
+	if err := slack.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	sent := strings.Join(sink.messages, "\n")
+	for _, name := range []string{"a", "b", "c"} {
+		if !strings.Contains(sent, "* "+name+" on acme.com") {
+			t.Errorf("risk %s was not sent after the retry: %s", name, sent)
+		}
+	}
+}
+
+func TestNotifierRetriesAfterTheWindow(t *testing.T) {
+	sink := newSlackSink(t)
+	slack, err := NewSlack(map[string]string{"url": sink.URL, "batch_window": "20ms"})
+	if err != nil {
+		t.Fatalf("NewSlack: %v", err)
+	}
+
+	sink.setFailing(true)
+	if err := slack.Sync(context.Background(), namedRisk("a")); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	sink.setFailing(false)
+
+	// no further Sync: the failed batch goes out when a later window ends
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		sink.mu.Lock()
+		sent := len(sink.messages)
+		sink.mu.Unlock()
+		if sent > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the risk of the failed batch was never sent")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSlackEscapesRiskFields(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"a<b>", "a&lt;b&gt;"},
+		{"x&y", "x&amp;y"},
+		{"<!channel>", "&lt;!channel&gt;"},
+	}
+	for _, test := range tests {
+		sink := newSlackSink(t)
+		slack, err := NewSlack(map[string]string{"url": sink.URL, "batch_window": "0s"})
+		if err != nil {
+			t.Fatalf("NewSlack: %v", err)
+		}
+		if err := slack.Sync(context.Background(), namedRisk(test.name)); err != nil {
+			t.Fatalf("Sync: %v", err)
+		}
+		if len(sink.messages) != 1 || !strings.Contains(sink.messages[0], test.want+" on acme.com") {
+			t.Errorf("risk %q was sent as %q, want %q", test.name, sink.messages, test.want)
+		}
+		if len(sink.messages) == 1 && !strings.Contains(sink.messages[0], "#acme.com#"+test.want+"|View in Chariot>") {
+			t.Errorf("link of risk %q was sent as %q, want %q", test.name, sink.messages, test.want)
+		}
+	}
+}
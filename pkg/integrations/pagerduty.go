@@ -0,0 +1,100 @@
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutySeverities maps risk severities to PagerDuty event severities
+var PagerDutySeverities = map[string]string{
+	"C": "critical",
+	"H": "error",
+	"M": "warning",
+	"L": "info",
+	"I": "info",
+}
+
+// PagerDuty triggers an alert through the Events API v2 for each open risk at
+// or above MinSeverity, and resolves it when the risk is remediated, deleted or
+// moved back to triage. Alerts use the risk's DedupKey, so repeated pushes
+// update a single alert.
+type PagerDuty struct {
+	RoutingKey  string
+	MinSeverity string
+	// URL is the events endpoint, PagerDutyEventsURL unless testing
+	URL        string
+	HTTPClient *http.Client
+}
+
+// NewPagerDuty reads routing_key, min_severity (critical by default) and url
+// from an integration config
+func NewPagerDuty(config map[string]string) (*PagerDuty, error) {
+	if err := required("pagerduty", config, "routing_key"); err != nil {
+		return nil, err
+	}
+	pd := &PagerDuty{
+		RoutingKey:  config["routing_key"],
+		MinSeverity: "C",
+		URL:         PagerDutyEventsURL,
+		HTTPClient:  &http.Client{},
+	}
+	if value := config["min_severity"]; value != "" {
+		code, ok := severityCode(value)
+		if !ok {
+			return nil, fmt.Errorf("pagerduty: invalid min_severity %q, expected critical, high, medium, low or info", value)
+		}
+		pd.MinSeverity = code
+	}
+	if value := config["url"]; value != "" {
+		pd.URL = value
+	}
+	return pd, nil
+}
+
+func (p *PagerDuty) Push(risk model.Risk) {
+	if err := p.Sync(context.Background(), risk); err != nil {
+		slog.Error("pagerduty event failed", "risk", risk.Key, "error", err)
+	}
+}
+
+func (p *PagerDuty) Sync(ctx context.Context, risk model.Risk) error {
+	event := map[string]any{
+		"routing_key": p.RoutingKey,
+		"dedup_key":   DedupKey(risk),
+	}
+	switch {
+	case isClosed(risk):
+		// resolving an alert that was never triggered is a no-op
+		event["event_action"] = "resolve"
+	case isOpen(risk) && atLeast(risk, p.MinSeverity):
+		event["event_action"] = "trigger"
+		event["payload"] = map[string]any{
+			"summary":   fmt.Sprintf("%s %s on %s", severity(risk), risk.Name, risk.DNS),
+			"source":    risk.DNS,
+			"severity":  PagerDutySeverities[risk.Severity()],
+			"timestamp": eventTime(risk.Updated, risk.Created).Format(time.RFC3339),
+			"component": risk.Name,
+			"class":     risk.Source,
+			"custom_details": map[string]string{
+				"key":     risk.Key,
+				"status":  describe(risk.Status),
+				"created": risk.Created,
+			},
+		}
+		event["links"] = []map[string]string{{"href": risk.Link(), "text": "View in Chariot"}}
+	default:
+		return nil
+	}
+
+	if err := postJSON(ctx, p.HTTPClient, p.URL, event); err != nil {
+		return fmt.Errorf("failed to %s alert: %v", event["event_action"], err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+func TestPagerDutyActions(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		action   string
+	}{
+		{"open critical triggers", []string{model.OpenCritical}, "trigger"},
+		{"open high is ignored", []string{model.OpenHigh}, ""},
+		{"new triage is ignored", []string{model.TriageCritical}, ""},
+		{"remediated resolves", []string{model.OpenCritical, model.RemediatedCritical}, "resolve"},
+		{"deleted resolves", []string{model.OpenCritical, model.Deleted + "C"}, "resolve"},
+		{"machine deleted resolves", []string{model.MachineOpenCritical, model.MachineDeletedCritical}, "resolve"},
+		{"back to triage resolves", []string{model.OpenCritical, model.TriageCritical}, "resolve"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var actions []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var event struct {
+					Action string `json:"event_action"`
+				}
+				json.NewDecoder(r.Body).Decode(&event)
+				actions = append(actions, event.Action)
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer server.Close()
+			pd, err := NewPagerDuty(map[string]string{"routing_key": "key", "url": server.URL})
+			if err != nil {
+				t.Fatalf("NewPagerDuty: %v", err)
+			}
+
+			risk := testRisk(test.statuses[0])
+			for _, status := range test.statuses[1:] {
+				setStatus(&risk, status)
+			}
+			if err := pd.Sync(context.Background(), risk); err != nil {
+				t.Fatalf("Sync: %v", err)
+			}
+			last := ""
+			if len(actions) > 0 {
+				last = actions[len(actions)-1]
+			}
+			if last != test.action {
+				t.Errorf("action = %q, want %q", last, test.action)
+			}
+		})
+	}
+}
@@ -0,0 +1,525 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DefaultInterval   = time.Minute
+	DefaultListen     = "127.0.0.1:9464"
+	DefaultCheckpoint = "chariot-integrations.json"
+)
+
+// RunnerConfig is the YAML file read by chariot integrate run. Config values
+// may reference environment variables as ${NAME}.
+//
+//	interval: 1m
+//	checkpoint: /var/lib/chariot/checkpoint.json
+//	listen: 127.0.0.1:9464
+//	integrations:
+//	  - name: jira
+//	    config:
+//	      url: https://acme.atlassian.net
+//	      user: security@acme.com
+//	      token: ${JIRA_TOKEN}
+//	      project: SEC
+//	  - name: on-call
+//	    type: slack
+//	    config:
+//	      url: ${SLACK_WEBHOOK}
+//	    filter:
+//	      min_severity: critical
+//	      dns: ["*.acme.com"]
+type RunnerConfig struct {
+	Interval   time.Duration `yaml:"interval,omitempty"`
+	Checkpoint string        `yaml:"checkpoint,omitempty"`
+	Listen     string        `yaml:"listen,omitempty"`
+	// SkipExisting records the risks that exist when an integration first runs
+	// without pushing them, so only later changes are pushed
+	SkipExisting bool                `yaml:"skip_existing,omitempty"`
+	Integrations []IntegrationConfig `yaml:"integrations"`
+}
+
+type IntegrationConfig struct {
+	// Name identifies the integration in the checkpoint and metrics
+	Name string `yaml:"name"`
+	// Type is a registered integration, Name by default
+	Type   string            `yaml:"type,omitempty"`
+	Config map[string]string `yaml:"config,omitempty"`
+	Filter Filter            `yaml:"filter,omitempty"`
+}
+
+// Filter selects the risks pushed to an integration. Empty fields match every
+// risk.
+type Filter struct {
+	MinSeverity string `yaml:"min_severity,omitempty"`
+	// States are state codes or names, e.g. open or MO
+	States []string `yaml:"states,omitempty"`
+	// Sources are capabilities, or provided
+	Sources []string `yaml:"sources,omitempty"`
+	// DNS are glob patterns matched against the risk's DNS
+	DNS []string `yaml:"dns,omitempty"`
+}
+
+func LoadRunnerConfig(path string) (*RunnerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRunnerConfig(data)
+}
+
+func ParseRunnerConfig(data []byte) (*RunnerConfig, error) {
+	var config RunnerConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("invalid integrations file: %v", err)
+	}
+
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Checkpoint == "" {
+		config.Checkpoint = DefaultCheckpoint
+	}
+	if config.Listen == "" {
+		config.Listen = DefaultListen
+	}
+	if len(config.Integrations) == 0 {
+		return nil, fmt.Errorf("invalid integrations file: no integrations")
+	}
+
+	names := make(map[string]bool)
+	for i, integration := range config.Integrations {
+		if integration.Name == "" {
+			return nil, fmt.Errorf("integration %d: name is required", i+1)
+		}
+		if names[integration.Name] {
+			return nil, fmt.Errorf("integration %s: duplicate name", integration.Name)
+		}
+		names[integration.Name] = true
+		if integration.Type == "" {
+			config.Integrations[i].Type = integration.Name
+		}
+		for key, value := range integration.Config {
+			config.Integrations[i].Config[key] = os.ExpandEnv(value)
+		}
+		if err := integration.Filter.validate(); err != nil {
+			return nil, fmt.Errorf("integration %s: %v", integration.Name, err)
+		}
+	}
+	return &config, nil
+}
+
+func (f Filter) validate() error {
+	if f.MinSeverity != "" {
+		if _, ok := severityCode(f.MinSeverity); !ok {
+			return fmt.Errorf("invalid min_severity %q", f.MinSeverity)
+		}
+	}
+	for _, state := range f.States {
+		if _, ok := stateCode(state); !ok {
+			return fmt.Errorf("invalid state %q", state)
+		}
+	}
+	for _, pattern := range f.DNS {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid dns pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+func (f Filter) Matches(risk model.Risk) bool {
+	if risk.Status == "" {
+		return false
+	}
+	if f.MinSeverity != "" {
+		code, _ := severityCode(f.MinSeverity)
+		if !atLeast(risk, code) {
+			return false
+		}
+	}
+	if len(f.States) > 0 && !slices.ContainsFunc(f.States, func(state string) bool {
+		code, _ := stateCode(state)
+		return code == risk.State()
+	}) {
+		return false
+	}
+	if len(f.Sources) > 0 && !slices.Contains(f.Sources, risk.Source) {
+		return false
+	}
+	if len(f.DNS) > 0 && !slices.ContainsFunc(f.DNS, func(pattern string) bool {
+		matched, _ := path.Match(pattern, risk.DNS)
+		return matched
+	}) {
+		return false
+	}
+	return true
+}
+
+// Checkpoint records the last Updated value pushed to each integration for
+// each risk key, so a restarted runner only pushes what changed meanwhile
+type Checkpoint struct {
+	Path         string                       `json:"-"`
+	Integrations map[string]map[string]string `json:"integrations"`
+
+	mu sync.Mutex
+}
+
+// LoadCheckpoint reads a checkpoint, or returns an empty one when path does
+// not exist yet
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	checkpoint := &Checkpoint{Path: path, Integrations: make(map[string]map[string]string)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %v", path, err)
+	}
+	if checkpoint.Integrations == nil {
+		checkpoint.Integrations = make(map[string]map[string]string)
+	}
+	return checkpoint, nil
+}
+
+func (c *Checkpoint) seen(integration, key, updated string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Integrations[integration][key] == updated
+}
+
+func (c *Checkpoint) started(integration string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.Integrations[integration]
+	return ok
+}
+
+func (c *Checkpoint) start(integration string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Integrations[integration] == nil {
+		c.Integrations[integration] = make(map[string]string)
+	}
+}
+
+func (c *Checkpoint) mark(integration, key, updated string) {
+	c.start(integration)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Integrations[integration][key] = updated
+}
+
+// Save writes the checkpoint through a temporary file, so a crash never leaves
+// it truncated
+func (c *Checkpoint) Save() error {
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.Path), filepath.Base(c.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.Path)
+}
+
+type target struct {
+	name        string
+	integration Syncer
+	filter      Filter
+
+	mu          sync.Mutex
+	pushed      int
+	failed      int
+	lastSuccess time.Time
+	lastError   string
+}
+
+func (t *target) record(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil {
+		t.failed++
+		t.lastError = err.Error()
+		return
+	}
+	t.pushed++
+	t.lastSuccess = time.Now()
+}
+
+// Runner polls the risks of an account and pushes new and changed risks to
+// each integration whose filter matches
+type Runner struct {
+	Client       *sdk.Chariot
+	Interval     time.Duration
+	Checkpoint   *Checkpoint
+	SkipExisting bool
+
+	targets []*target
+	started time.Time
+
+	mu          sync.Mutex
+	polls       int
+	pollErrors  int
+	lastPoll    time.Time
+	lastError   string
+	lastRisks   int
+	lastLatency time.Duration
+}
+
+func NewRunner(client *sdk.Chariot, config *RunnerConfig) (*Runner, error) {
+	checkpoint, err := LoadCheckpoint(config.Checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	runner := &Runner{
+		Client:       client,
+		Interval:     config.Interval,
+		Checkpoint:   checkpoint,
+		SkipExisting: config.SkipExisting,
+		started:      time.Now(),
+	}
+	for _, integration := range config.Integrations {
+		syncer, err := New(integration.Type, integration.Config)
+		if err != nil {
+			return nil, fmt.Errorf("integration %s: %v", integration.Name, err)
+		}
+		if user, ok := syncer.(DefinitionUser); ok {
+			user.UseDefinitions(client.DownloadDefinition)
+		}
+		runner.targets = append(runner.targets, &target{name: integration.Name, integration: syncer, filter: integration.Filter})
+	}
+	return runner, nil
+}
+
+// Run polls until ctx is done, then flushes and closes the integrations
+func (r *Runner) Run(ctx context.Context) error {
+	defer r.Close()
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		if err := r.Poll(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("integration poll failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll lists the risks once and pushes changes to every integration in
+// parallel. Risks are pushed to one integration in order, and a failed push
+// is retried on the next poll. Integrations that batch risks are flushed at
+// the end of the poll, and their risks are only checkpointed once the flush
+// succeeds.
+func (r *Runner) Poll(ctx context.Context) error {
+	start := time.Now()
+	risks, err := r.Client.Risks.List()
+	if err != nil {
+		r.recordPoll(start, 0, err)
+		return fmt.Errorf("failed to list risks: %v", err)
+	}
+	sort.SliceStable(risks, func(i, j int) bool { return risks[i].Updated < risks[j].Updated })
+
+	errs := sdk.ForEach(ctx, r.targets, len(r.targets), func(ctx context.Context, t *target) error {
+		skip := r.SkipExisting && !r.Checkpoint.started(t.name)
+		flusher, batched := t.integration.(Flusher)
+		var queued []model.Risk
+		failed := 0
+		for _, risk := range risks {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if r.Checkpoint.seen(t.name, risk.Key, risk.Updated) {
+				continue
+			}
+			if !skip && t.filter.Matches(risk) {
+				err := t.integration.Sync(ctx, risk)
+				if err != nil {
+					t.record(err)
+					slog.Error("integration push failed", "integration", t.name, "risk", risk.Key, "error", err)
+					failed++
+					continue
+				}
+				if batched {
+					queued = append(queued, risk)
+					continue
+				}
+				t.record(nil)
+			}
+			r.Checkpoint.mark(t.name, risk.Key, risk.Updated)
+		}
+
+		if len(queued) > 0 {
+			// unsent risks stay queued in the integration and are pushed again
+			// next poll, so they are not checkpointed
+			if err := flusher.Flush(ctx); err != nil {
+				t.record(err)
+				return fmt.Errorf("%s: failed to send %d risks: %v", t.name, len(queued), err)
+			}
+			for _, risk := range queued {
+				t.record(nil)
+				r.Checkpoint.mark(t.name, risk.Key, risk.Updated)
+			}
+		}
+		r.Checkpoint.start(t.name)
+		if failed > 0 {
+			return fmt.Errorf("%s: %d of %d pushes failed", t.name, failed, len(risks))
+		}
+		return nil
+	})
+
+	saveErr := r.Checkpoint.Save()
+	for _, err := range errs {
+		if err != nil && saveErr == nil {
+			saveErr = err
+		}
+	}
+	r.recordPoll(start, len(risks), saveErr)
+	return saveErr
+}
+
+func (r *Runner) recordPoll(start time.Time, risks int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.polls++
+	r.lastLatency = time.Since(start)
+	if err != nil {
+		r.pollErrors++
+		r.lastError = err.Error()
+		return
+	}
+	r.lastPoll = time.Now()
+	r.lastRisks = risks
+	r.lastError = ""
+}
+
+// Close sends batched notifications and closes connections
+func (r *Runner) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for _, t := range r.targets {
+		if flusher, ok := t.integration.(Flusher); ok {
+			if err := flusher.Flush(ctx); err != nil {
+				slog.Error("integration flush failed", "integration", t.name, "error", err)
+			}
+		}
+		if closer, ok := t.integration.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
+// Handler serves /healthz, which fails once no poll has succeeded for three
+// intervals, and Prometheus metrics on /metrics
+func (r *Runner) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", r.health)
+	mux.HandleFunc("/metrics", r.metrics)
+	return mux
+}
+
+func (r *Runner) health(w http.ResponseWriter, _ *http.Request) {
+	r.mu.Lock()
+	last := r.lastPoll
+	if last.IsZero() {
+		last = r.started
+	}
+	healthy := time.Since(last) < 3*r.Interval
+	status := map[string]any{
+		"healthy":    healthy,
+		"last_poll":  r.lastPoll,
+		"last_error": r.lastError,
+	}
+	r.mu.Unlock()
+
+	integrations := make(map[string]any)
+	for _, t := range r.targets {
+		t.mu.Lock()
+		integrations[t.name] = map[string]any{"pushed": t.pushed, "failed": t.failed, "last_error": t.lastError}
+		t.mu.Unlock()
+	}
+	status["integrations"] = integrations
+
+	w.Header().Set("Content-Type", "application/json")
+	if !healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(status)
+}
+
+func (r *Runner) metrics(w http.ResponseWriter, _ *http.Request) {
+	var b strings.Builder
+	r.mu.Lock()
+	fmt.Fprintf(&b, "# HELP chariot_polls_total Risk polls by result.\n# TYPE chariot_polls_total counter\n")
+	fmt.Fprintf(&b, "chariot_polls_total{result=\"success\"} %d\n", r.polls-r.pollErrors)
+	fmt.Fprintf(&b, "chariot_polls_total{result=\"failure\"} %d\n", r.pollErrors)
+	fmt.Fprintf(&b, "# HELP chariot_poll_duration_seconds Duration of the last poll.\n# TYPE chariot_poll_duration_seconds gauge\n")
+	fmt.Fprintf(&b, "chariot_poll_duration_seconds %g\n", r.lastLatency.Seconds())
+	fmt.Fprintf(&b, "# HELP chariot_poll_last_success_timestamp_seconds Time of the last successful poll.\n# TYPE chariot_poll_last_success_timestamp_seconds gauge\n")
+	fmt.Fprintf(&b, "chariot_poll_last_success_timestamp_seconds %d\n", unix(r.lastPoll))
+	fmt.Fprintf(&b, "# HELP chariot_risks Risks listed by the last successful poll.\n# TYPE chariot_risks gauge\n")
+	fmt.Fprintf(&b, "chariot_risks %d\n", r.lastRisks)
+	r.mu.Unlock()
+
+	fmt.Fprintf(&b, "# HELP chariot_integration_pushes_total Risks pushed to each integration by result.\n# TYPE chariot_integration_pushes_total counter\n")
+	for _, t := range r.targets {
+		t.mu.Lock()
+		fmt.Fprintf(&b, "chariot_integration_pushes_total{integration=%q,result=\"success\"} %d\n", t.name, t.pushed)
+		fmt.Fprintf(&b, "chariot_integration_pushes_total{integration=%q,result=\"failure\"} %d\n", t.name, t.failed)
+		t.mu.Unlock()
+	}
+	fmt.Fprintf(&b, "# HELP chariot_integration_last_success_timestamp_seconds Time of the last successful push to each integration.\n# TYPE chariot_integration_last_success_timestamp_seconds gauge\n")
+	for _, t := range r.targets {
+		t.mu.Lock()
+		fmt.Fprintf(&b, "chariot_integration_last_success_timestamp_seconds{integration=%q} %d\n", t.name, unix(t.lastSuccess))
+		t.mu.Unlock()
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	io.WriteString(w, b.String())
+}
+
+func unix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,131 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/praetorian-inc/chariot-client/pkg/integrations/jiratest"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/chariottest"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+// batcher queues risks like a Notifier and fails to flush while failing is set
+type batcher struct {
+	failing bool
+	pending []model.Risk
+	sent    []model.Risk
+}
+
+func (b *batcher) Push(risk model.Risk) {}
+
+func (b *batcher) Sync(ctx context.Context, risk model.Risk) error {
+	b.pending = append(b.pending, risk)
+	return nil
+}
+
+func (b *batcher) Flush(ctx context.Context) error {
+	if b.failing {
+		return errors.New("webhook unavailable")
+	}
+	b.sent = append(b.sent, b.pending...)
+	b.pending = nil
+	return nil
+}
+
+func newTestRunner(t *testing.T, risks ...any) (*Runner, *chariottest.Server) {
+	t.Helper()
+	server := chariottest.NewServer()
+	t.Cleanup(server.Close)
+	server.Seed("", risks...)
+	checkpoint, err := LoadCheckpoint(filepath.Join(t.TempDir(), "checkpoint.json"))
+	if err != nil {
+		t.Fatalf("LoadCheckpoint: %v", err)
+	}
+	return &Runner{Client: server.NewClient(), Checkpoint: checkpoint}, server
+}
+
+func TestRunnerCheckpointsAfterFlush(t *testing.T) {
+	runner, _ := newTestRunner(t, namedRisk("a"), namedRisk("b"))
+	integration := &batcher{failing: true}
+	runner.targets = []*target{{name: "chat", integration: integration}}
+	ctx := context.Background()
+
+	if err := runner.Poll(ctx); err == nil {
+		t.Fatal("Poll succeeded although the flush failed")
+	}
+	if len(runner.Checkpoint.Integrations["chat"]) != 0 {
+		t.Fatalf("risks were checkpointed before they were sent: %v", runner.Checkpoint.Integrations["chat"])
+	}
+
+	integration.failing = false
+	integration.pending = nil
+	if err := runner.Poll(ctx); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(integration.sent) != 2 || len(runner.Checkpoint.Integrations["chat"]) != 2 {
+		t.Fatalf("sent %d risks and checkpointed %d, want 2", len(integration.sent), len(runner.Checkpoint.Integrations["chat"]))
+	}
+
+	if err := runner.Poll(ctx); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(integration.sent) != 2 {
+		t.Fatalf("unchanged risks were sent again, %d sent", len(integration.sent))
+	}
+}
+
+func TestRunnerFilters(t *testing.T) {
+	high := namedRisk("high")
+	high.Status = model.OpenHigh
+	high.DNS = "www.acme.com"
+	low := namedRisk("low")
+	low.Status = model.OpenLow
+	other := namedRisk("other")
+	other.Status = model.OpenHigh
+	other.DNS = "example.com"
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{"no filter", Filter{}, 3},
+		{"severity", Filter{MinSeverity: "high"}, 2},
+		{"state", Filter{States: []string{"triage"}}, 0},
+		{"dns", Filter{DNS: []string{"*.acme.com"}}, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			runner, _ := newTestRunner(t, high, low, other)
+			integration := &batcher{}
+			runner.targets = []*target{{name: "chat", integration: integration, filter: test.filter}}
+			if err := runner.Poll(context.Background()); err != nil {
+				t.Fatalf("Poll: %v", err)
+			}
+			if len(integration.sent) != test.want {
+				t.Errorf("sent %d risks, want %d", len(integration.sent), test.want)
+			}
+		})
+	}
+}
+
+func TestNewRunnerPassesDefinitions(t *testing.T) {
+	jira := jiratest.NewServer()
+	defer jira.Close()
+	server := chariottest.NewServer()
+	defer server.Close()
+
+	config := &RunnerConfig{
+		Checkpoint:   filepath.Join(t.TempDir(), "checkpoint.json"),
+		Integrations: []IntegrationConfig{{Name: "jira", Type: "jira", Config: jira.Config()}},
+	}
+	runner, err := NewRunner(server.NewClient(), config)
+	if err != nil {
+		t.Fatalf("NewRunner: %v", err)
+	}
+	if runner.targets[0].integration.(*Jira).Definition == nil {
+		t.Fatal("the Jira integration has no definition source")
+	}
+}
@@ -0,0 +1,230 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+// ServiceNowSeverities maps risk severities to incident impact and urgency,
+// which ServiceNow combines into priorities 1 (critical) to 5 (planning)
+var ServiceNowSeverities = map[string][2]string{
+	"C": {"1", "1"},
+	"H": {"1", "2"},
+	"M": {"2", "2"},
+	"L": {"2", "3"},
+	"I": {"3", "3"},
+}
+
+// Incident states set by ServiceNow. A resolved incident stays active until
+// it is closed, and closed or canceled incidents cannot be reopened.
+const (
+	serviceNowInProgress = "2"
+	serviceNowResolved   = "6"
+	serviceNowClosed     = "7"
+	serviceNowCanceled   = "8"
+)
+
+// ServiceNow opens an incident for each open risk at or above MinSeverity,
+// with the risk's DedupKey as its correlation ID. Until the incident is
+// resolved, severity changes update its impact and urgency, and it is resolved
+// when the risk is remediated, deleted or moved back to triage. When the risk
+// is open again, its latest incident is reopened if it is resolved, whether
+// by Chariot or by hand; only a closed or canceled incident, which ServiceNow
+// does not reopen, is followed by a new incident.
+type ServiceNow struct {
+	// URL is the instance URL, e.g. https://acme.service-now.com
+	URL string
+	// User and Password authenticate with basic authentication. Without a
+	// user, Password is sent as an OAuth bearer token.
+	User            string
+	Password        string
+	AssignmentGroup string
+	MinSeverity     string
+	// CloseCode is the resolution code required by the incident form
+	CloseCode  string
+	HTTPClient *http.Client
+}
+
+// NewServiceNow reads url, user, password, assignment_group, min_severity
+// (critical by default) and close_code from an integration config
+func NewServiceNow(config map[string]string) (*ServiceNow, error) {
+	if err := required("servicenow", config, "url", "password"); err != nil {
+		return nil, err
+	}
+	sn := &ServiceNow{
+		URL:             strings.TrimRight(config["url"], "/"),
+		User:            config["user"],
+		Password:        config["password"],
+		AssignmentGroup: config["assignment_group"],
+		MinSeverity:     "C",
+		CloseCode:       config["close_code"],
+		HTTPClient:      &http.Client{},
+	}
+	if value := config["min_severity"]; value != "" {
+		code, ok := severityCode(value)
+		if !ok {
+			return nil, fmt.Errorf("servicenow: invalid min_severity %q, expected critical, high, medium, low or info", value)
+		}
+		sn.MinSeverity = code
+	}
+	if sn.CloseCode == "" {
+		sn.CloseCode = "Solved (Permanently)"
+	}
+	return sn, nil
+}
+
+func (s *ServiceNow) Push(risk model.Risk) {
+	if err := s.Sync(context.Background(), risk); err != nil {
+		slog.Error("servicenow sync failed", "risk", risk.Key, "error", err)
+	}
+}
+
+type serviceNowIncident struct {
+	SysID   string `json:"sys_id"`
+	Number  string `json:"number"`
+	State   string `json:"state"`
+	Impact  string `json:"impact"`
+	Urgency string `json:"urgency"`
+}
+
+func (s *ServiceNow) Sync(ctx context.Context, risk model.Risk) error {
+	incident, ok, err := s.find(ctx, risk)
+	if err != nil {
+		return fmt.Errorf("failed to search incidents: %v", err)
+	}
+	open := isOpen(risk) && atLeast(risk, s.MinSeverity)
+	resolved := ok && incident.State == serviceNowResolved
+	ended := ok && (incident.State == serviceNowClosed || incident.State == serviceNowCanceled)
+	// a live incident is still being worked, neither resolved nor ended
+	live := ok && !resolved && !ended
+
+	switch {
+	case resolved && open:
+		reopen := map[string]string{
+			"state":      serviceNowInProgress,
+			"work_notes": fmt.Sprintf("Reopened, Chariot status is now %s", describe(risk.Status)),
+		}
+		if err := s.do(ctx, "PATCH", "/"+incident.SysID, reopen, nil); err != nil {
+			return fmt.Errorf("failed to reopen %s: %v", incident.Number, err)
+		}
+	case (!ok || ended) && open:
+		if err := s.do(ctx, "POST", "", s.incident(risk), nil); err != nil {
+			return fmt.Errorf("failed to create incident: %v", err)
+		}
+	case live && isClosed(risk):
+		resolve := map[string]string{
+			"state":       serviceNowResolved,
+			"close_code":  s.CloseCode,
+			"close_notes": fmt.Sprintf("%s in Chariot: %s", state(risk), risk.Link()),
+		}
+		if err := s.do(ctx, "PATCH", "/"+incident.SysID, resolve, nil); err != nil {
+			return fmt.Errorf("failed to resolve %s: %v", incident.Number, err)
+		}
+	case live && risk.Status != "":
+		mapped := ServiceNowSeverities[risk.Severity()]
+		if incident.Impact == mapped[0] && incident.Urgency == mapped[1] {
+			return nil
+		}
+		update := map[string]string{
+			"impact":     mapped[0],
+			"urgency":    mapped[1],
+			"work_notes": fmt.Sprintf("Chariot status is now %s", describe(risk.Status)),
+		}
+		if err := s.do(ctx, "PATCH", "/"+incident.SysID, update, nil); err != nil {
+			return fmt.Errorf("failed to update %s: %v", incident.Number, err)
+		}
+	}
+	return nil
+}
+
+func (s *ServiceNow) incident(risk model.Risk) map[string]string {
+	var description strings.Builder
+	fmt.Fprintf(&description, "Chariot found %s on %s.\n\n", risk.Name, risk.DNS)
+	fmt.Fprintf(&description, "Status: %s\n", describe(risk.Status))
+	if risk.Source != "" {
+		fmt.Fprintf(&description, "Source: %s\n", risk.Source)
+	}
+	fmt.Fprintf(&description, "Created: %s\n", risk.Created)
+	fmt.Fprintf(&description, "Proof: %s\n", risk.Link())
+
+	mapped := ServiceNowSeverities[risk.Severity()]
+	incident := map[string]string{
+		"short_description":   fmt.Sprintf("[Chariot] %s on %s", risk.Name, risk.DNS),
+		"description":         description.String(),
+		"correlation_id":      DedupKey(risk),
+		"correlation_display": "Chariot",
+		"category":            "security",
+		"impact":              mapped[0],
+		"urgency":             mapped[1],
+	}
+	if s.AssignmentGroup != "" {
+		incident["assignment_group"] = s.AssignmentGroup
+	}
+	return incident
+}
+
+// find returns the latest incident of a risk, active or not
+func (s *ServiceNow) find(ctx context.Context, risk model.Risk) (serviceNowIncident, bool, error) {
+	query := url.Values{}
+	query.Set("sysparm_query", fmt.Sprintf("correlation_id=%s^ORDERBYDESCsys_created_on", DedupKey(risk)))
+	query.Set("sysparm_fields", "sys_id,number,state,impact,urgency")
+	query.Set("sysparm_limit", "1")
+
+	var result struct {
+		Result []serviceNowIncident `json:"result"`
+	}
+	if err := s.do(ctx, "GET", "?"+query.Encode(), nil, &result); err != nil {
+		return serviceNowIncident{}, false, err
+	}
+	if len(result.Result) == 0 {
+		return serviceNowIncident{}, false, nil
+	}
+	return result.Result[0], true, nil
+}
+
+func (s *ServiceNow) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.URL+"/api/now/table/incident"+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.User != "" {
+		req.SetBasicAuth(s.User, s.Password)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+s.Password)
+	}
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d, %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,173 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+// incidentTable fakes the ServiceNow incident table API, newest incident last.
+// As in ServiceNow, resolved incidents stay active until they are closed.
+type incidentTable struct {
+	mu        sync.Mutex
+	incidents []map[string]string
+	patches   int
+}
+
+func active(state string) string {
+	return fmt.Sprint(state != "7" && state != "8")
+}
+
+func (t *incidentTable) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	id := strings.TrimPrefix(r.URL.Path, "/api/now/table/incident/")
+
+	var body map[string]string
+	json.NewDecoder(r.Body).Decode(&body)
+	switch r.Method {
+	case "GET":
+		correlation, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Query().Get("sysparm_query"), "correlation_id="), "^")
+		result := make([]map[string]string, 0)
+		for i := len(t.incidents) - 1; i >= 0; i-- {
+			if t.incidents[i]["correlation_id"] == correlation {
+				result = append(result, t.incidents[i])
+				break
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"result": result})
+	case "POST":
+		body["sys_id"] = fmt.Sprint(len(t.incidents) + 1)
+		body["number"] = "INC" + body["sys_id"]
+		body["state"] = "1"
+		body["active"] = "true"
+		t.incidents = append(t.incidents, body)
+	case "PATCH":
+		t.patches++
+		for _, incident := range t.incidents {
+			if incident["sys_id"] == id {
+				for key, value := range body {
+					incident[key] = value
+				}
+				incident["active"] = active(incident["state"])
+			}
+		}
+	}
+}
+
+// set changes an incident as a person working it in ServiceNow would
+func (t *incidentTable) set(index int, state string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.incidents[index]["state"] = state
+	t.incidents[index]["active"] = active(state)
+}
+
+func newTestServiceNow(t *testing.T) (*ServiceNow, *incidentTable) {
+	table := &incidentTable{}
+	server := httptest.NewServer(table)
+	t.Cleanup(server.Close)
+	sn, err := NewServiceNow(map[string]string{"url": server.URL, "user": "chariot", "password": "secret", "min_severity": "high"})
+	if err != nil {
+		t.Fatalf("NewServiceNow: %v", err)
+	}
+	return sn, table
+}
+
+func TestServiceNowLifecycle(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []string
+		incidents int
+		state     string
+	}{
+		{"open creates", []string{model.OpenHigh}, 1, "1"},
+		{"below minimum is ignored", []string{model.OpenMedium}, 0, ""},
+		{"triage is ignored", []string{model.TriageCritical}, 0, ""},
+		{"remediated resolves", []string{model.OpenHigh, model.RemediatedHigh}, 1, "6"},
+		{"deleted resolves", []string{model.OpenHigh, model.Deleted + "H"}, 1, "6"},
+		{"machine deleted resolves", []string{model.MachineOpenHigh, model.MachineDeletedHigh}, 1, "6"},
+		{"back to triage resolves", []string{model.OpenHigh, model.TriageHigh}, 1, "6"},
+		{"reopened risk reopens the incident", []string{model.OpenHigh, model.RemediatedHigh, model.OpenHigh}, 1, "2"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sn, table := newTestServiceNow(t)
+			risk := testRisk(test.statuses[0])
+			for i, status := range test.statuses {
+				if i > 0 {
+					setStatus(&risk, status)
+				}
+				if err := sn.Sync(context.Background(), risk); err != nil {
+					t.Fatalf("Sync %s: %v", status, err)
+				}
+			}
+			if len(table.incidents) != test.incidents {
+				t.Fatalf("got %d incidents, want %d", len(table.incidents), test.incidents)
+			}
+			if test.incidents > 0 && table.incidents[0]["state"] != test.state {
+				t.Errorf("incident state = %s, want %s", table.incidents[0]["state"], test.state)
+			}
+		})
+	}
+}
+
+func TestServiceNowClosedIncident(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     string
+		incidents int
+	}{
+		{"resolved by hand is reopened", "6", 1},
+		{"closed is replaced once", "7", 2},
+		{"canceled is replaced once", "8", 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sn, table := newTestServiceNow(t)
+			risk := testRisk(model.OpenHigh)
+			sn.Sync(context.Background(), risk)
+			table.set(0, test.state)
+
+			for range 3 {
+				if err := sn.Sync(context.Background(), risk); err != nil {
+					t.Fatalf("Sync: %v", err)
+				}
+			}
+			if len(table.incidents) != test.incidents {
+				t.Fatalf("got %d incidents, want %d", len(table.incidents), test.incidents)
+			}
+		})
+	}
+}
+
+func TestServiceNowResolvedIncidentIsNotUpdated(t *testing.T) {
+	sn, table := newTestServiceNow(t)
+	risk := testRisk(model.OpenHigh)
+	sn.Sync(context.Background(), risk)
+	setStatus(&risk, model.RemediatedHigh)
+	for range 3 {
+		if err := sn.Sync(context.Background(), risk); err != nil {
+			t.Fatalf("Sync: %v", err)
+		}
+	}
+	if table.patches != 1 {
+		t.Errorf("resolved incident was patched %d times, want 1", table.patches)
+	}
+
+	// a severity change of a remediated risk does not touch the incident either
+	setStatus(&risk, model.RemediatedCritical)
+	if err := sn.Sync(context.Background(), risk); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if table.patches != 1 || table.incidents[0]["state"] != "6" {
+		t.Errorf("patched %d times, state %s", table.patches, table.incidents[0]["state"])
+	}
+}
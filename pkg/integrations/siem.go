@@ -0,0 +1,328 @@
+package integrations
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+const (
+	CEF  = "cef"
+	LEEF = "leef"
+	JSON = "json"
+
+	// local0
+	DefaultFacility = 16
+)
+
+// SIEM ships risks, and optionally assets, as CEF, LEEF or JSON records in
+// RFC 5424 syslog messages over UDP, TCP or TLS. Stream transports use octet
+// counting framing (RFC 6587, RFC 5425).
+type SIEM struct {
+	Format string
+	// Network is udp, tcp or tls
+	Network string
+	Address string
+	// Hostname is the syslog HOSTNAME field, the local hostname by default
+	Hostname  string
+	Facility  int
+	TLSConfig *tls.Config
+	// Assets ships asset changes passed to SyncAsset; they are ignored otherwise
+	Assets bool
+	// Timeout bounds connecting and each write
+	Timeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSIEM reads address, network, format, facility, hostname, ca_file and
+// assets from an integration config
+func NewSIEM(config map[string]string) (*SIEM, error) {
+	if err := required("siem", config, "address"); err != nil {
+		return nil, err
+	}
+	s := &SIEM{
+		Format:   strings.ToLower(config["format"]),
+		Network:  strings.ToLower(config["network"]),
+		Address:  config["address"],
+		Hostname: config["hostname"],
+		Facility: DefaultFacility,
+		Assets:   config["assets"] == "true",
+		Timeout:  10 * time.Second,
+	}
+	if s.Format == "" {
+		s.Format = CEF
+	}
+	if s.Format != CEF && s.Format != LEEF && s.Format != JSON {
+		return nil, fmt.Errorf("siem: invalid format %q, expected cef, leef or json", s.Format)
+	}
+	if s.Network == "" {
+		s.Network = "udp"
+	}
+	if s.Network != "udp" && s.Network != "tcp" && s.Network != "tls" {
+		return nil, fmt.Errorf("siem: invalid network %q, expected udp, tcp or tls", s.Network)
+	}
+	if value := config["facility"]; value != "" {
+		facility, err := strconv.Atoi(value)
+		if err != nil || facility < 0 || facility > 23 {
+			return nil, fmt.Errorf("siem: facility must be between 0 and 23")
+		}
+		s.Facility = facility
+	}
+	if s.Hostname == "" {
+		s.Hostname, _ = os.Hostname()
+	}
+
+	if s.Network == "tls" {
+		s.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		if path := config["ca_file"]; path != "" {
+			pem, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("siem: %v", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("siem: no certificates found in %s", path)
+			}
+			s.TLSConfig.RootCAs = pool
+		}
+	}
+	return s, nil
+}
+
+func (s *SIEM) Push(risk model.Risk) {
+	if err := s.Sync(context.Background(), risk); err != nil {
+		slog.Error("siem export failed", "risk", risk.Key, "error", err)
+	}
+}
+
+func (s *SIEM) Sync(ctx context.Context, risk model.Risk) error {
+	return s.send(ctx, riskRecord(risk))
+}
+
+// SyncAsset ships an asset change when Assets is set
+func (s *SIEM) SyncAsset(ctx context.Context, asset model.Asset) error {
+	if !s.Assets {
+		return nil
+	}
+	return s.send(ctx, assetRecord(asset))
+}
+
+func (s *SIEM) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// record is a format independent event. Fields keep their order in CEF and
+// LEEF extensions.
+type record struct {
+	Type     string
+	Event    string
+	Name     string
+	Severity int
+	Time     time.Time
+	Fields   [][2]string
+}
+
+// cefSeverities maps risk severities to the 0-10 scale of CEF and LEEF
+var cefSeverities = map[string]int{"I": 1, "L": 3, "M": 5, "H": 8, "C": 10}
+
+func eventTime(values ...string) time.Time {
+	for _, value := range values {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t
+		}
+	}
+	return time.Now().UTC()
+}
+
+func riskRecord(risk model.Risk) record {
+	r := record{
+		Type:  "risk",
+		Event: risk.Name,
+		Name:  fmt.Sprintf("%s on %s", risk.Name, risk.DNS),
+		Time:  eventTime(risk.Updated, risk.Created),
+		Fields: [][2]string{
+			{"key", risk.Key},
+			{"dns", risk.DNS},
+			{"risk", risk.Name},
+			{"status", risk.Status},
+			{"state", state(risk)},
+			{"severity", severity(risk)},
+			{"source", risk.Source},
+			{"created", risk.Created},
+			{"url", risk.Link()},
+		},
+	}
+	if risk.Status != "" {
+		r.Severity = cefSeverities[risk.Severity()]
+	}
+	return r
+}
+
+func assetRecord(asset model.Asset) record {
+	return record{
+		Type:     "asset",
+		Event:    "asset",
+		Name:     fmt.Sprintf("Asset %s (%s)", asset.Name, asset.DNS),
+		Severity: 1,
+		Time:     eventTime(asset.Updated, asset.Created),
+		Fields: [][2]string{
+			{"key", asset.Key},
+			{"dns", asset.DNS},
+			{"name", asset.Name},
+			{"status", asset.Status},
+			{"source", asset.Source},
+		},
+	}
+}
+
+// cefFields maps record fields to CEF extension keys
+var cefFields = map[string]string{
+	"dns":    "dhost",
+	"url":    "request",
+	"key":    "cs1",
+	"status": "cs2",
+	"source": "cs3",
+	"state":  "cs4",
+	"risk":   "cs5",
+	"name":   "cs5",
+}
+
+var cefEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+var cefHeaderEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`)
+
+func (r record) cef() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CEF:0|Praetorian|Chariot|1.0|%s|%s|%d|", cefHeaderEscaper.Replace(r.Event), cefHeaderEscaper.Replace(r.Name), r.Severity)
+	fmt.Fprintf(&b, "rt=%d cat=%s", r.Time.UnixMilli(), r.Type)
+	for _, field := range r.Fields {
+		key, ok := cefFields[field[0]]
+		if !ok || field[1] == "" {
+			continue
+		}
+		if strings.HasPrefix(key, "cs") {
+			fmt.Fprintf(&b, " %sLabel=%s", key, field[0])
+		}
+		fmt.Fprintf(&b, " %s=%s", key, cefEscaper.Replace(field[1]))
+	}
+	return b.String()
+}
+
+var leefEscaper = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+
+func (r record) leef() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "LEEF:1.0|Praetorian|Chariot|1.0|%s|", leefEscaper.Replace(strings.ReplaceAll(r.Event, "|", "_")))
+	fmt.Fprintf(&b, "devTime=%s\tdevTimeFormat=MMM dd yyyy HH:mm:ss\tsev=%d\tcat=%s", r.Time.UTC().Format("Jan 02 2006 15:04:05"), r.Severity, r.Type)
+	for _, field := range r.Fields {
+		if field[1] != "" {
+			fmt.Fprintf(&b, "\t%s=%s", field[0], leefEscaper.Replace(field[1]))
+		}
+	}
+	return b.String()
+}
+
+func (r record) json() string {
+	event := map[string]any{"type": r.Type, "time": r.Time.UTC().Format(time.RFC3339)}
+	for _, field := range r.Fields {
+		event[field[0]] = field[1]
+	}
+	data, _ := json.Marshal(event)
+	return string(data)
+}
+
+// syslogSeverity maps record severities to syslog severities, from
+// informational for info up to critical
+func syslogSeverity(severity int) int {
+	switch {
+	case severity >= 10:
+		return 2
+	case severity >= 8:
+		return 3
+	case severity >= 5:
+		return 4
+	case severity >= 3:
+		return 5
+	default:
+		return 6
+	}
+}
+
+func (s *SIEM) message(r record) []byte {
+	var msg string
+	switch s.Format {
+	case LEEF:
+		msg = r.leef()
+	case JSON:
+		msg = r.json()
+	default:
+		msg = r.cef()
+	}
+	hostname := s.Hostname
+	if hostname == "" {
+		hostname = "-"
+	}
+	priority := s.Facility*8 + syslogSeverity(r.Severity)
+	line := fmt.Sprintf("<%d>1 %s %s chariot - %s - %s", priority, time.Now().UTC().Format(time.RFC3339), hostname, r.Type, msg)
+	if s.Network == "udp" {
+		return []byte(line)
+	}
+	return []byte(fmt.Sprintf("%d %s", len(line), line))
+}
+
+func (s *SIEM) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: s.Timeout}
+	if s.Network == "tls" {
+		return (&tls.Dialer{NetDialer: dialer, Config: s.TLSConfig}).DialContext(ctx, "tcp", s.Address)
+	}
+	return dialer.DialContext(ctx, s.Network, s.Address)
+}
+
+// send writes a message, reconnecting once when a stream connection was closed
+// by the collector
+func (s *SIEM) send(ctx context.Context, r record) error {
+	message := s.message(r)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for attempt := 0; ; attempt++ {
+		if s.conn == nil {
+			conn, err := s.dial(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to connect to %s: %v", s.Address, err)
+			}
+			s.conn = conn
+		}
+		if s.Timeout > 0 {
+			s.conn.SetWriteDeadline(time.Now().Add(s.Timeout))
+		}
+		_, err := s.conn.Write(message)
+		if err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+		if attempt > 0 {
+			return fmt.Errorf("failed to write to %s: %v", s.Address, err)
+		}
+	}
+}
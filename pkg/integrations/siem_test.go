@@ -0,0 +1,243 @@
+package integrations
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+var testRecord = record{
+	Type:     "risk",
+	Event:    "sql|injection\\x\ty",
+	Name:     "sql|injection on acme.com",
+	Severity: 8,
+	Time:     time.UnixMilli(1700000000000),
+	Fields: [][2]string{
+		{"key", "#risk#acme.com#a=b"},
+		{"dns", "acme.com"},
+		{"severity", "high"},
+		{"source", "line1\nline2\\"},
+		{"state", "a|b\tc"},
+		{"url", ""},
+	},
+}
+
+func TestCEF(t *testing.T) {
+	want := `CEF:0|Praetorian|Chariot|1.0|sql\|injection\\x` + "\t" + `y|sql\|injection on acme.com|8|` +
+		`rt=1700000000000 cat=risk cs1Label=key cs1=#risk#acme.com#a\=b dhost=acme.com ` +
+		`cs3Label=source cs3=line1\nline2\\ cs4Label=state cs4=a|b` + "\tc"
+	if got := testRecord.cef(); got != want {
+		t.Errorf("cef() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLEEF(t *testing.T) {
+	want := strings.Join([]string{
+		"LEEF:1.0|Praetorian|Chariot|1.0|sql_injection\\x y|devTime=Nov 14 2023 22:13:20",
+		"devTimeFormat=MMM dd yyyy HH:mm:ss",
+		"sev=8",
+		"cat=risk",
+		"key=#risk#acme.com#a=b",
+		"dns=acme.com",
+		"severity=high",
+		"source=line1 line2\\",
+		"state=a|b c",
+	}, "\t")
+	if got := testRecord.leef(); got != want {
+		t.Errorf("leef() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestSyslogFrame(t *testing.T) {
+	frame := regexp.MustCompile(`^<(\d+)>1 (\S+) scanner chariot - (\S+) - (.*)$`)
+	tests := []struct {
+		name     string
+		facility int
+		record   record
+		priority int
+		msgid    string
+	}{
+		{"critical risk", DefaultFacility, riskRecord(testRisk(model.OpenCritical)), 16*8 + 2, "risk"},
+		{"high risk", DefaultFacility, riskRecord(testRisk(model.OpenHigh)), 16*8 + 3, "risk"},
+		{"info risk", 1, riskRecord(testRisk(model.OpenInfo)), 1*8 + 6, "risk"},
+		{"asset", DefaultFacility, assetRecord(model.NewAsset("acme.com", "acme.com")), 16*8 + 6, "asset"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, network := range []string{"udp", "tcp"} {
+				s := &SIEM{Format: JSON, Network: network, Hostname: "scanner", Facility: test.facility}
+				message := string(s.message(test.record))
+				if network == "tcp" {
+					length, rest, _ := strings.Cut(message, " ")
+					if n, err := strconv.Atoi(length); err != nil || n != len(rest) {
+						t.Fatalf("%s frame length %q does not match %d bytes", network, length, len(rest))
+					}
+					message = rest
+				}
+
+				match := frame.FindStringSubmatch(message)
+				if match == nil {
+					t.Fatalf("%s message is not an RFC 5424 frame: %q", network, message)
+				}
+				if priority, _ := strconv.Atoi(match[1]); priority != test.priority {
+					t.Errorf("PRI = %d, want %d", priority, test.priority)
+				}
+				if sent, err := time.Parse(time.RFC3339, match[2]); err != nil || time.Since(sent) > time.Minute {
+					t.Errorf("TIMESTAMP = %s, %v", match[2], err)
+				}
+				if match[3] != test.msgid {
+					t.Errorf("MSGID = %s, want %s", match[3], test.msgid)
+				}
+				if !strings.HasPrefix(match[4], `{"`) {
+					t.Errorf("MSG = %s, want a JSON record", match[4])
+				}
+			}
+		})
+	}
+}
+
+func TestSIEMOverUDP(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	s, err := NewSIEM(map[string]string{"address": listener.LocalAddr().String()})
+	if err != nil {
+		t.Fatalf("NewSIEM: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.Sync(context.Background(), testRisk(model.OpenHigh)); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	buf := make([]byte, 8192)
+	listener.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := listener.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("ReadFrom: %v", err)
+	}
+	if message := string(buf[:n]); !strings.HasPrefix(message, "<131>1 ") || !strings.Contains(message, " - risk - CEF:0|Praetorian|Chariot|") {
+		t.Errorf("received %q", message)
+	}
+}
+
+// collector accepts syslog connections and reports every octet counted frame
+// it reads, with the number of the connection it arrived on
+type collector struct {
+	net.Listener
+	frames chan string
+}
+
+func newCollector(t *testing.T) *collector {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &collector{Listener: listener, frames: make(chan string, 16)}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for connection := 1; ; connection++ {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go c.read(conn, connection)
+		}
+	}()
+	return c
+}
+
+func (c *collector) read(conn net.Conn, connection int) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		length, err := r.ReadString(' ')
+		if err != nil {
+			return
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(length))
+		if err != nil {
+			return
+		}
+		frame := make([]byte, n)
+		if _, err := io.ReadFull(r, frame); err != nil {
+			return
+		}
+		c.frames <- fmt.Sprintf("%d %s", connection, frame)
+	}
+}
+
+func (c *collector) next(t *testing.T) (int, string) {
+	t.Helper()
+	select {
+	case frame := <-c.frames:
+		connection, message, _ := strings.Cut(frame, " ")
+		n, _ := strconv.Atoi(connection)
+		return n, message
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the collector received nothing")
+		return 0, ""
+	}
+}
+
+func TestSIEMOverTCP(t *testing.T) {
+	collector := newCollector(t)
+	s, err := NewSIEM(map[string]string{"address": collector.Addr().String(), "network": "tcp", "format": "leef", "assets": "true"})
+	if err != nil {
+		t.Fatalf("NewSIEM: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.Sync(context.Background(), testRisk(model.OpenHigh)); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if err := s.SyncAsset(context.Background(), model.NewAsset("acme.com", "acme.com")); err != nil {
+		t.Fatalf("SyncAsset: %v", err)
+	}
+	for _, msgid := range []string{"risk", "asset"} {
+		connection, message := collector.next(t)
+		if connection != 1 {
+			t.Errorf("%s arrived on connection %d, want 1", msgid, connection)
+		}
+		if !strings.Contains(message, " - "+msgid+" - LEEF:1.0|Praetorian|Chariot|") {
+			t.Errorf("received %q", message)
+		}
+	}
+
+	// a write error on the broken connection is retried on a new one
+	s.conn.Close()
+	if err := s.Sync(context.Background(), testRisk(model.OpenCritical)); err != nil {
+		t.Fatalf("Sync after the connection broke: %v", err)
+	}
+	connection, message := collector.next(t)
+	if connection != 2 || !strings.HasPrefix(message, "<130>1 ") {
+		t.Errorf("received %q on connection %d, want a critical risk on connection 2", message, connection)
+	}
+}
+
+func TestSIEMUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	s, err := NewSIEM(map[string]string{"address": address, "network": "tcp"})
+	if err != nil {
+		t.Fatalf("NewSIEM: %v", err)
+	}
+	if err := s.Sync(context.Background(), testRisk(model.OpenHigh)); err == nil || !strings.Contains(err.Error(), "failed to connect") {
+		t.Errorf("Sync() = %v, want a connection error", err)
+	}
+}
@@ -0,0 +1,56 @@
+package integrations
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+const slackTemplate = `*{{.Severity}}* {{.Name}} on {{.DNS}}{{if .Source}} from {{.Source}}{{end}} ({{.State}})
+<{{.Link}}|View in Chariot>`
+
+// Slack posts risks to a Slack incoming webhook as Block Kit messages, one
+// section per risk
+type Slack struct {
+	*Notifier
+	URL        string
+	HTTPClient *http.Client
+}
+
+// NewSlack reads the webhook url and the Notifier settings from an
+// integration config. The template is Slack mrkdwn.
+func NewSlack(config map[string]string) (*Slack, error) {
+	if err := required("slack", config, "url"); err != nil {
+		return nil, err
+	}
+	notifier, err := newNotifier("slack", config, slackTemplate)
+	if err != nil {
+		return nil, err
+	}
+	slack := &Slack{Notifier: notifier, URL: config["url"], HTTPClient: &http.Client{}}
+	notifier.send = slack.send
+	notifier.escape = slackEscape
+	return slack, nil
+}
+
+// slackEscape escapes the characters Slack mrkdwn treats as control sequences
+var slackEscape = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace
+
+func (s *Slack) send(ctx context.Context, title string, _ []Notification, texts []string) error {
+	blocks := []map[string]any{{
+		"type": "header",
+		"text": map[string]string{"type": "plain_text", "text": title},
+	}}
+	for _, text := range texts {
+		blocks = append(blocks, map[string]any{
+			"type": "section",
+			"text": map[string]string{"type": "mrkdwn", "text": text},
+		})
+	}
+	message := map[string]any{
+		// text is shown in notifications, where blocks are not rendered
+		"text":   title + "\n" + strings.Join(texts, "\n"),
+		"blocks": blocks,
+	}
+	return postJSON(ctx, s.HTTPClient, s.URL, message)
+}
@@ -0,0 +1,66 @@
+package integrations
+
+import (
+	"context"
+	"net/http"
+)
+
+const teamsTemplate = `**{{.Severity}}** {{.Name}} on {{.DNS}}{{if .Source}} from {{.Source}}{{end}} ({{.State}}) [View in Chariot]({{.Link}})`
+
+// Teams posts risks to a Microsoft Teams incoming webhook or workflow as an
+// Adaptive Card, one text block per risk
+type Teams struct {
+	*Notifier
+	URL        string
+	HTTPClient *http.Client
+}
+
+// NewTeams reads the webhook url and the Notifier settings from an
+// integration config. The template is the markdown subset Adaptive Cards
+// support.
+func NewTeams(config map[string]string) (*Teams, error) {
+	if err := required("teams", config, "url"); err != nil {
+		return nil, err
+	}
+	notifier, err := newNotifier("teams", config, teamsTemplate)
+	if err != nil {
+		return nil, err
+	}
+	teams := &Teams{Notifier: notifier, URL: config["url"], HTTPClient: &http.Client{}}
+	notifier.send = teams.send
+	return teams, nil
+}
+
+func (t *Teams) send(ctx context.Context, title string, risks []Notification, texts []string) error {
+	body := []map[string]any{{
+		"type":   "TextBlock",
+		"text":   title,
+		"size":   "Medium",
+		"weight": "Bolder",
+	}}
+	for _, text := range texts {
+		body = append(body, map[string]any{
+			"type":      "TextBlock",
+			"text":      text,
+			"wrap":      true,
+			"separator": true,
+		})
+	}
+	card := map[string]any{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+	if len(risks) == 1 {
+		card["actions"] = []map[string]string{{"type": "Action.OpenUrl", "title": "View in Chariot", "url": risks[0].Link}}
+	}
+	message := map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content":     card,
+		}},
+	}
+	return postJSON(ctx, t.HTTPClient, t.URL, message)
+}
@@ -0,0 +1,301 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	SignatureHeader = "X-Chariot-Signature"
+	TimestampHeader = "X-Chariot-Timestamp"
+	EventHeader     = "X-Chariot-Event"
+	DeliveryHeader  = "X-Chariot-Delivery"
+
+	maxBackoff = time.Minute
+)
+
+// WebhookConfig configures a Webhook. It can be loaded from YAML, where values
+// may reference environment variables as ${NAME}:
+//
+//	url: https://tickets.acme.internal/chariot
+//	secret: ${CHARIOT_WEBHOOK_SECRET}
+//	headers:
+//	  X-Team: security
+//	retries: 5
+//	backoff: 2s
+//	dead_letter: /var/lib/chariot/webhook-failed.jsonl
+type WebhookConfig struct {
+	URL     string            `yaml:"url"`
+	Secret  string            `yaml:"secret,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// Retries is the number of attempts after the first one
+	Retries int           `yaml:"retries,omitempty"`
+	Backoff time.Duration `yaml:"backoff,omitempty"`
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// DeadLetter is a JSONL file that envelopes are appended to once every
+	// attempt has failed
+	DeadLetter string `yaml:"dead_letter,omitempty"`
+}
+
+func LoadWebhookConfig(path string) (WebhookConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return WebhookConfig{}, err
+	}
+	return ParseWebhookConfig(data)
+}
+
+func ParseWebhookConfig(data []byte) (WebhookConfig, error) {
+	config := WebhookConfig{Retries: -1}
+	decoder := yaml.NewDecoder(bytes.NewReader([]byte(os.ExpandEnv(string(data)))))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil {
+		return WebhookConfig{}, fmt.Errorf("invalid webhook config: %v", err)
+	}
+	return config, nil
+}
+
+// Envelope is the JSON body posted for every risk event
+type Envelope struct {
+	// ID is derived from the risk key and its Updated time, so every delivery
+	// of the same risk event has the same ID and receivers can deduplicate
+	ID string `json:"id"`
+	// Event is risk. followed by the state, e.g. risk.open or risk.remediated
+	Event    string     `json:"event"`
+	Time     string     `json:"time"`
+	Severity string     `json:"severity"`
+	State    string     `json:"state"`
+	Link     string     `json:"link"`
+	Risk     model.Risk `json:"risk"`
+}
+
+func NewEnvelope(risk model.Risk) Envelope {
+	return Envelope{
+		ID:       uuid.NewSHA1(uuid.NameSpaceURL, []byte(risk.Key+"#"+risk.Updated)).String(),
+		Event:    "risk." + strings.ReplaceAll(strings.ToLower(state(risk)), " ", "_"),
+		Time:     model.Now(),
+		Severity: severity(risk),
+		State:    state(risk),
+		Link:     risk.Link(),
+		Risk:     risk,
+	}
+}
+
+// Webhook posts an Envelope for each risk to an arbitrary URL, so internal
+// systems can consume Chariot events. With a secret, every request is signed:
+// X-Chariot-Signature is sha256= followed by the hex HMAC-SHA256 of the
+// X-Chariot-Timestamp header, a period and the body. Receivers should reject
+// old timestamps to prevent replays.
+type Webhook struct {
+	WebhookConfig
+	HTTPClient *http.Client
+
+	mu sync.Mutex
+}
+
+// NewWebhook reads url, secret, retries, backoff, timeout, dead_letter and
+// header.<name> keys from an integration config, or loads the YAML file named
+// by file
+func NewWebhook(config map[string]string) (*Webhook, error) {
+	if path := config["file"]; path != "" {
+		loaded, err := LoadWebhookConfig(path)
+		if err != nil {
+			return nil, fmt.Errorf("webhook: %v", err)
+		}
+		return NewWebhookFromConfig(loaded)
+	}
+
+	if err := required("webhook", config, "url"); err != nil {
+		return nil, err
+	}
+	parsed := WebhookConfig{
+		URL:        config["url"],
+		Secret:     config["secret"],
+		DeadLetter: config["dead_letter"],
+		Retries:    -1,
+		Headers:    make(map[string]string),
+	}
+	for key, value := range config {
+		if name, ok := strings.CutPrefix(key, "header."); ok {
+			parsed.Headers[name] = value
+		}
+	}
+	if value := config["retries"]; value != "" {
+		retries, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("webhook: invalid retries %q", value)
+		}
+		parsed.Retries = retries
+	}
+	for key, target := range map[string]*time.Duration{"backoff": &parsed.Backoff, "timeout": &parsed.Timeout} {
+		if value := config[key]; value != "" {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("webhook: invalid %s %q", key, value)
+			}
+			*target = duration
+		}
+	}
+	return NewWebhookFromConfig(parsed)
+}
+
+// NewWebhookFromConfig validates config and fills in defaults: 3 retries, a 1s
+// backoff that doubles with each retry, and a 30s timeout. Negative retries
+// stand for the default.
+func NewWebhookFromConfig(config WebhookConfig) (*Webhook, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("webhook: url is required")
+	}
+	if !strings.HasPrefix(config.URL, "https://") && !strings.HasPrefix(config.URL, "http://") {
+		return nil, fmt.Errorf("webhook: url must be http or https")
+	}
+	if config.Retries < 0 {
+		config.Retries = 3
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return &Webhook{WebhookConfig: config, HTTPClient: &http.Client{Timeout: config.Timeout}}, nil
+}
+
+func (w *Webhook) Push(risk model.Risk) {
+	if err := w.Sync(context.Background(), risk); err != nil {
+		slog.Error("webhook delivery failed", "risk", risk.Key, "error", err)
+	}
+}
+
+// Sync delivers an envelope for the risk, retrying network errors, 429 and 5xx
+// responses. When every attempt fails and a dead letter file is configured,
+// the envelope is written to it and the delivery counts as handled, so callers
+// do not retry it; otherwise the last error is returned.
+func (w *Webhook) Sync(ctx context.Context, risk model.Risk) error {
+	envelope := NewEnvelope(risk)
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+attempts:
+	for attempt := 0; attempt <= w.Retries; attempt++ {
+		var retry bool
+		var wait time.Duration
+		retry, wait, lastErr = w.deliver(ctx, envelope, body)
+		if lastErr == nil {
+			return nil
+		}
+		if !retry || attempt == w.Retries {
+			break
+		}
+		if wait <= 0 {
+			wait = min(w.Backoff<<attempt, maxBackoff)
+		}
+		select {
+		case <-ctx.Done():
+			lastErr = ctx.Err()
+			break attempts
+		case <-time.After(wait):
+		}
+	}
+
+	// a cancelled delivery is retried by the caller rather than dead lettered
+	if w.DeadLetter == "" || ctx.Err() != nil {
+		return lastErr
+	}
+	if err := w.deadLetter(envelope, lastErr); err != nil {
+		return fmt.Errorf("%v, and failed to write dead letter: %v", lastErr, err)
+	}
+	slog.Warn("webhook delivery failed, written to dead letter file", "risk", risk.Key, "file", w.DeadLetter, "error", lastErr)
+	return nil
+}
+
+// Sign returns the signature header value of a body sent at timestamp
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliver makes one attempt. It reports whether a failure is worth retrying,
+// and how long the server asked to wait.
+func (w *Webhook) deliver(ctx context.Context, envelope Envelope, body []byte) (bool, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, 0, err
+	}
+	for name, value := range w.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, envelope.Event)
+	req.Header.Set(DeliveryHeader, envelope.ID)
+	if w.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, Sign(w.Secret, timestamp, body))
+	}
+
+	resp, err := w.HTTPClient.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, 0, err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return false, 0, nil
+	}
+
+	err = fmt.Errorf("unexpected status code %d, %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	var wait time.Duration
+	if seconds, parseErr := strconv.Atoi(resp.Header.Get("Retry-After")); parseErr == nil {
+		wait = min(time.Duration(seconds)*time.Second, maxBackoff)
+	}
+	return retry, wait, err
+}
+
+func (w *Webhook) deadLetter(envelope Envelope, cause error) error {
+	line, err := json.Marshal(struct {
+		Failed   string   `json:"failed"`
+		URL      string   `json:"url"`
+		Error    string   `json:"error"`
+		Envelope Envelope `json:"envelope"`
+	}{model.Now(), w.URL, cause.Error(), envelope})
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := os.OpenFile(w.DeadLetter, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
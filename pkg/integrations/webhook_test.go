@@ -0,0 +1,124 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+func TestEnvelopeID(t *testing.T) {
+	risk := namedRisk("sql-injection")
+	risk.Updated = "2026-01-01T00:00:00Z"
+	updated := risk
+	updated.Updated = "2026-01-02T00:00:00Z"
+	other := namedRisk("xss")
+	other.Updated = risk.Updated
+
+	id := NewEnvelope(risk).ID
+	tests := []struct {
+		name string
+		risk model.Risk
+		same bool
+	}{
+		{"same event", risk, true},
+		{"updated risk", updated, false},
+		{"other risk", other, false},
+	}
+	for _, test := range tests {
+		if got := NewEnvelope(test.risk).ID; (got == id) != test.same {
+			t.Errorf("%s: ID %s, first ID %s, want same %v", test.name, got, id, test.same)
+		}
+	}
+}
+
+func TestWebhookSignsDeliveries(t *testing.T) {
+	var received atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(SignatureHeader) != Sign("secret", r.Header.Get(TimestampHeader), body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var envelope Envelope
+		if err := json.Unmarshal(body, &envelope); err != nil || envelope.ID != r.Header.Get(DeliveryHeader) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received.Store(true)
+	}))
+	defer server.Close()
+
+	webhook, err := NewWebhook(map[string]string{"url": server.URL, "secret": "secret"})
+	if err != nil {
+		t.Fatalf("NewWebhook: %v", err)
+	}
+	if err := webhook.Sync(context.Background(), namedRisk("sql-injection")); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if !received.Load() {
+		t.Fatal("the receiver did not accept the delivery")
+	}
+}
+
+func TestWebhookDeadLetter(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		attempts   int32
+		deadLetter bool
+	}{
+		{"server error is retried and dead lettered", http.StatusBadGateway, 3, true},
+		{"client error is not retried", http.StatusBadRequest, 1, true},
+		{"without a dead letter file the error is returned", http.StatusBadGateway, 3, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			config := WebhookConfig{URL: server.URL, Retries: 2, Backoff: time.Millisecond}
+			if test.deadLetter {
+				config.DeadLetter = filepath.Join(t.TempDir(), "failed.jsonl")
+			}
+			webhook, err := NewWebhookFromConfig(config)
+			if err != nil {
+				t.Fatalf("NewWebhookFromConfig: %v", err)
+			}
+
+			err = webhook.Sync(context.Background(), namedRisk("sql-injection"))
+			if got := attempts.Load(); got != test.attempts {
+				t.Errorf("got %d attempts, want %d", got, test.attempts)
+			}
+			if !test.deadLetter {
+				if err == nil {
+					t.Fatal("Sync succeeded without a dead letter file")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Sync of a dead lettered delivery returned %v", err)
+			}
+			data, err := os.ReadFile(config.DeadLetter)
+			if err != nil {
+				t.Fatalf("dead letter file: %v", err)
+			}
+			if lines := bytes.Count(data, []byte("\n")); lines != 1 {
+				t.Fatalf("got %d dead letters, want 1", lines)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package sdk
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+type BatchResult[T any] struct {
+	Item T
+	Err  error
+}
+
+// BatchReport holds one result per item, in the order the items were given
+type BatchReport[T any] struct {
+	Results []BatchResult[T]
+}
+
+func (r *BatchReport[T]) Failed() []BatchResult[T] {
+	failed := make([]BatchResult[T], 0)
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Err summarizes the failures, or returns nil when every item succeeded
+func (r *BatchReport[T]) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d items failed, first error: %w", len(failed), len(r.Results), failed[0].Err)
+}
+
+func batch[T any](client *Chariot, items []T, fn func(T) error) *BatchReport[T] {
+	errs := ForEach(context.Background(), items, client.Concurrency, func(_ context.Context, item T) error {
+		return fn(item)
+	})
+	report := &BatchReport[T]{Results: make([]BatchResult[T], len(items))}
+	for i, item := range items {
+		report.Results[i] = BatchResult[T]{Item: item, Err: errs[i]}
+	}
+	return report
+}
+
+func (s *Service[T]) AddMany(items []T) *BatchReport[T] {
+	return batch(s.Client, items, s.Add)
+}
+
+func (s *Service[T]) UpdateMany(items []T) *BatchReport[T] {
+	return batch(s.Client, items, s.Update)
+}
+
+func (s *Service[T]) DeleteMany(items []T) *BatchReport[T] {
+	return batch(s.Client, items, s.Delete)
+}
+
+func (s *RiskService) AddMany(items []model.Risk) *BatchReport[model.Risk] {
+	return batch(s.Client, items, s.Add)
+}
+
+func (s *AttributeService) AddMany(items []model.Attribute) *BatchReport[model.Attribute] {
+	return batch(s.Client, items, s.Add)
+}
+
+func (s *AssetService) DeleteMany(items []model.Asset) *BatchReport[model.Asset] {
+	return batch(s.Client, items, s.Delete)
+}
+
+func (s *AccountService) AddMany(items []model.Account) *BatchReport[model.Account] {
+	return batch(s.Client, items, s.Add)
+}
+
+func (s *AccountService) DeleteMany(items []model.Account) *BatchReport[model.Account] {
+	return batch(s.Client, items, s.Delete)
+}
+
+func (s *FileService) DeleteMany(items []model.File) *BatchReport[model.File] {
+	return batch(s.Client, items, s.Delete)
+}
+
+// ReadKeys returns the non-empty lines of r. Keys start with #, so there is no
+// comment syntax.
+func ReadKeys(r io.Reader) ([]string, error) {
+	var keys []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if key := strings.TrimSpace(scanner.Text()); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys, scanner.Err()
+}
+
+// ReadKeysFile reads keys from path, or from stdin when path is -
+func ReadKeysFile(path string) ([]string, error) {
+	if path == "-" {
+		return ReadKeys(os.Stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadKeys(f)
+}
@@ -0,0 +1,151 @@
+package sdk_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+// inFlight counts the requests sent through it at once
+type inFlight struct {
+	next http.RoundTripper
+
+	mu      sync.Mutex
+	current int
+	peak    int
+}
+
+func (f *inFlight) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	f.current++
+	f.peak = max(f.peak, f.current)
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.current--
+		f.mu.Unlock()
+	}()
+	// hold the request long enough for the others to pile up
+	time.Sleep(5 * time.Millisecond)
+	return f.next.RoundTrip(req)
+}
+
+func TestBatchCollectsErrors(t *testing.T) {
+	client, server := newTestClient(t)
+	assets := make([]model.Asset, 5)
+	for i, name := range []string{"1.2.3.4", "missing-1", "5.6.7.8", "missing-2", "9.9.9.9"} {
+		assets[i] = model.NewAsset("acme.com", name)
+	}
+	server.Seed("", assets[0], assets[2], assets[4])
+
+	report := client.Assets.DeleteMany(assets)
+	if len(report.Results) != len(assets) {
+		t.Fatalf("got %d results, want %d", len(report.Results), len(assets))
+	}
+	for i, result := range report.Results {
+		if result.Item.Key != assets[i].Key {
+			t.Errorf("result %d is for %s, want %s", i, result.Item.Key, assets[i].Key)
+		}
+		missing := strings.HasPrefix(result.Item.Name, "missing")
+		if (result.Err != nil) != missing {
+			t.Errorf("result for %s: err = %v", result.Item.Name, result.Err)
+		}
+	}
+	if failed := report.Failed(); len(failed) != 2 || failed[0].Item.Name != "missing-1" || failed[1].Item.Name != "missing-2" {
+		t.Errorf("Failed() = %v", failed)
+	}
+	if err := report.Err(); err == nil || !strings.HasPrefix(err.Error(), "2 of 5 items failed") {
+		t.Errorf("Err() = %v", err)
+	}
+	// deleting an asset flags it as deleted
+	for _, asset := range server.Store("").Assets {
+		if asset.Status != model.Deleted {
+			t.Errorf("%s is still %s", asset.Key, asset.Status)
+		}
+	}
+
+	if err := client.Assets.DeleteMany(nil).Err(); err != nil {
+		t.Errorf("empty batch: %v", err)
+	}
+}
+
+func TestBatchConcurrency(t *testing.T) {
+	tests := []struct {
+		concurrency int
+		want        int
+	}{
+		{1, 1},
+		{3, 3},
+		{0, sdk.DefaultConcurrency},
+	}
+	for _, test := range tests {
+		client, server := newTestClient(t)
+		recorder := &inFlight{next: client.HTTPClient.Transport}
+		client.HTTPClient = &http.Client{Transport: recorder}
+		client.Concurrency = test.concurrency
+
+		risks := make([]model.Risk, 20)
+		for i := range risks {
+			risks[i] = model.NewRisk(model.NewAsset("acme.com", "acme.com"), "risk-"+string(rune('a'+i)))
+			risks[i].Status = model.OpenHigh
+			server.Seed("", risks[i])
+		}
+		for i := range risks {
+			risks[i].Status = model.RemediatedHigh
+		}
+
+		if err := client.Risks.UpdateMany(risks).Err(); err != nil {
+			t.Fatalf("UpdateMany: %v", err)
+		}
+		if recorder.peak > test.want {
+			t.Errorf("concurrency %d sent %d requests at once", test.concurrency, recorder.peak)
+		}
+		if test.want > 1 && recorder.peak < 2 {
+			t.Errorf("concurrency %d never sent requests in parallel", test.concurrency)
+		}
+		for _, risk := range server.Store("").Risks {
+			if risk.Status != model.RemediatedHigh {
+				t.Errorf("%s is still %s", risk.Key, risk.Status)
+			}
+		}
+	}
+}
+
+func TestForEachCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int64
+	errs := sdk.ForEach(ctx, []int{1, 2, 3, 4, 5, 6}, 1, func(_ context.Context, item int) error {
+		calls.Add(1)
+		if item == 2 {
+			cancel()
+		}
+		return nil
+	})
+	if calls.Load() != 2 {
+		t.Errorf("fn was called %d times, want 2", calls.Load())
+	}
+	for i, err := range errs[2:] {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("errs[%d] = %v, want %v", i+2, err, context.Canceled)
+		}
+	}
+}
+
+func TestReadKeys(t *testing.T) {
+	keys, err := sdk.ReadKeys(strings.NewReader("#asset#acme.com#1.2.3.4\n\n  #asset#acme.com#5.6.7.8  \r\n\t\n#risk#acme.com#sqli"))
+	if err != nil {
+		t.Fatalf("ReadKeys: %v", err)
+	}
+	want := []string{"#asset#acme.com#1.2.3.4", "#asset#acme.com#5.6.7.8", "#risk#acme.com#sqli"}
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Errorf("ReadKeys() = %q, want %q", keys, want)
+	}
+}
@@ -2,6 +2,7 @@ package sdk
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -20,12 +21,25 @@ type Chariot struct {
 	Files      *FileService
 	Jobs       *JobService
 	Accounts   *AccountService
+
+	HTTPClient *http.Client
+	// Limiter throttles every API request when set
+	Limiter *RateLimiter
+	// Concurrency bounds how many requests batch operations send at once
+	Concurrency int
 }
 
 func NewClient(profile string) *Chariot {
+	return NewClientWithKeychain(NewKeychainFromIniFile(profile))
+}
+
+func NewClientWithKeychain(keychain *Keychain) *Chariot {
 	chariot := &Chariot{
-		Keychain: NewKeychainFromIniFile(profile),
+		Keychain:    keychain,
+		HTTPClient:  &http.Client{},
+		Concurrency: keychain.Concurrency,
 	}
+	chariot.SetRateLimit(keychain.Rate, keychain.Burst)
 
 	chariot.Accounts = NewAccountService(chariot)
 	chariot.Assets = NewAssetService(chariot)
@@ -59,42 +73,61 @@ func (c *Chariot) Search(term string) (*model.SearchResult, error) {
 }
 
 func (c *Chariot) request(method, url string, data []byte) ([]byte, error) {
-	token, err := c.GetToken()
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+
+	resp, err := c.do(context.Background(), method, url, body)
 	if err != nil {
 		return nil, err
 	}
 
-	client := http.Client{}
-	var req *http.Request
-	if data != nil {
-		req, err = http.NewRequest(method, url, bytes.NewBuffer(data))
-	} else {
-		req, err = http.NewRequest(method, url, nil)
-	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("Authorization", "Bearer "+token)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d, %s", resp.StatusCode, respBody)
+	}
 
-	if c.GetAccount() != "" {
-		req.Header.Add("account", c.GetAccount())
+	return respBody, nil
+}
+
+// do sends an authenticated request to the Chariot API. The caller owns the
+// response body, which is not read or checked for errors.
+func (c *Chariot) do(ctx context.Context, method, url string, body io.Reader) (*http.Response, error) {
+	return c.doWithHeader(ctx, method, url, body, nil)
+}
+
+// doWithHeader is do with extra request headers, such as Range
+func (c *Chariot) doWithHeader(ctx context.Context, method, url string, body io.Reader, header http.Header) (*http.Response, error) {
+	if c.Limiter != nil {
+		if err := c.Limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
 	}
 
-	resp, err := client.Do(req)
+	token, err := c.GetToken()
 	if err != nil {
 		return nil, err
 	}
 
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code %d, %s", resp.StatusCode, body)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	if c.GetAccount() != "" {
+		req.Header.Add("account", c.GetAccount())
 	}
 
-	return body, nil
+	return c.HTTPClient.Do(req)
 }
@@ -0,0 +1,589 @@
+// Package chariottest provides an in-process fake of the Chariot API so the
+// SDK and CLI can be exercised without Cognito or network access.
+//
+//	server := chariottest.NewServer()
+//	defer server.Close()
+//	client := server.NewClient()
+//	client.Assets.Add(model.NewAsset("example.com", "example.com"))
+package chariottest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/webhook"
+)
+
+const (
+	Username = "chariottest@praetorian.com"
+	Token    = "chariottest-token"
+	// Signature signs the presigned URLs the server hands out for file
+	// transfers, which then need no bearer token
+	Signature = "chariottest-signature"
+)
+
+// Store holds the records of a single account
+type Store struct {
+	Assets     map[string]model.Asset
+	Attributes map[string]model.Attribute
+	Risks      map[string]model.Risk
+	Jobs       map[string]model.Job
+	Accounts   map[string]model.Account
+	Files      map[string]model.File
+	// ContentTypes are the content types files were uploaded with, by name
+	ContentTypes map[string]string
+}
+
+func newStore() *Store {
+	return &Store{
+		Assets:       make(map[string]model.Asset),
+		Attributes:   make(map[string]model.Attribute),
+		Risks:        make(map[string]model.Risk),
+		Jobs:         make(map[string]model.Job),
+		Accounts:     make(map[string]model.Account),
+		Files:        make(map[string]model.File),
+		ContentTypes: make(map[string]string),
+	}
+}
+
+type Server struct {
+	*httptest.Server
+	Username string
+	Token    string
+	// PageSize is the number of items returned per /my page
+	PageSize int
+
+	mu     sync.Mutex
+	stores map[string]*Store
+}
+
+func NewServer() *Server {
+	s := &Server{
+		Username: Username,
+		Token:    Token,
+		PageSize: 100,
+		stores:   make(map[string]*Store),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/my", s.search)
+	mux.HandleFunc("/asset", s.asset)
+	mux.HandleFunc("/risk", s.risk)
+	mux.HandleFunc("/attribute", s.attribute)
+	mux.HandleFunc("/job", s.job)
+	mux.HandleFunc("/account/", s.account)
+	mux.HandleFunc("/file", s.file)
+	mux.HandleFunc("/upload", s.upload)
+	mux.HandleFunc("/download", s.download)
+	mux.HandleFunc("/hook/", s.hook)
+
+	s.Server = httptest.NewServer(s.authenticate(mux))
+	return s
+}
+
+// NewClient returns an SDK client that talks to the fake server
+func (s *Server) NewClient() *sdk.Chariot {
+	client := sdk.NewClientWithKeychain(sdk.NewKeychain(s.URL, s.Username, sdk.StaticToken(s.Token)))
+	client.HTTPClient = s.Client()
+	return client
+}
+
+// Store returns the records of an account, creating it if needed. An empty
+// account refers to the server's own user. Callers must not modify the store
+// while requests are in flight.
+func (s *Server) Store(account string) *Store {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.store(account)
+}
+
+func (s *Server) store(account string) *Store {
+	if account == "" {
+		account = s.Username
+	}
+	if _, ok := s.stores[account]; !ok {
+		s.stores[account] = newStore()
+	}
+	return s.stores[account]
+}
+
+// Seed adds assets, attributes, risks, jobs, accounts and files to an account
+func (s *Server) Seed(account string, items ...any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	store := s.store(account)
+	for _, item := range items {
+		switch v := item.(type) {
+		case model.Asset:
+			store.Assets[v.Key] = v
+		case model.Attribute:
+			store.Attributes[v.Key] = v
+		case model.Risk:
+			store.Risks[v.Key] = v
+		case model.Job:
+			store.Jobs[v.Key] = v
+		case model.Account:
+			store.Accounts[v.Key] = v
+		case model.File:
+			store.Files[v.Name] = v
+		default:
+			panic(fmt.Sprintf("chariottest: unsupported type %T", item))
+		}
+	}
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// presigned transfers and webhooks carry their own authorization
+		public := r.URL.Path == "/upload" || r.URL.Path == "/download" || strings.HasPrefix(r.URL.Path, "/hook/")
+		if !public && r.Header.Get("Authorization") != "Bearer "+s.Token {
+			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) caller(r *http.Request) (string, *Store) {
+	account := r.Header.Get("account")
+	if account == "" {
+		account = s.Username
+	}
+	return account, s.store(account)
+}
+
+func reply(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func notFound(w http.ResponseWriter, key string) {
+	http.Error(w, fmt.Sprintf(`{"error":"%s not found"}`, key), http.StatusNotFound)
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	caller, store := s.caller(r)
+	term := r.URL.Query().Get("key")
+
+	type entry struct {
+		key  string
+		item any
+	}
+	var entries []entry
+	collect := func(items any) {
+		v := reflect.ValueOf(items)
+		iter := v.MapRange()
+		for iter.Next() {
+			item := iter.Value().Interface()
+			if match(term, item) {
+				entries = append(entries, entry{key: reflect.ValueOf(item).FieldByName("Key").String(), item: item})
+			}
+		}
+	}
+	collect(store.Assets)
+	collect(store.Attributes)
+	collect(store.Risks)
+	collect(store.Jobs)
+	collect(store.Accounts)
+	collect(store.Files)
+	// links to accounts the caller is a member of are listed by both sides
+	for name, other := range s.stores {
+		if name == caller {
+			continue
+		}
+		for _, account := range other.Accounts {
+			if account.Member == caller && match(term, account) {
+				entries = append(entries, entry{key: account.Key, item: account})
+			}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	if offset := r.URL.Query().Get("offset"); offset != "" {
+		var last map[string]string
+		if err := json.Unmarshal([]byte(offset), &last); err != nil {
+			http.Error(w, `{"error":"invalid offset"}`, http.StatusBadRequest)
+			return
+		}
+		start := sort.Search(len(entries), func(i int) bool { return entries[i].key > last["key"] })
+		entries = entries[start:]
+	}
+
+	var result model.SearchResult
+	if s.PageSize > 0 && len(entries) > s.PageSize {
+		entries = entries[:s.PageSize]
+		result.Offset = map[string]string{"key": entries[len(entries)-1].key}
+	}
+	for _, e := range entries {
+		switch v := e.item.(type) {
+		case model.Asset:
+			result.Assets = append(result.Assets, v)
+		case model.Attribute:
+			result.Attributes = append(result.Attributes, v)
+		case model.Risk:
+			result.Risks = append(result.Risks, v)
+		case model.Job:
+			result.Jobs = append(result.Jobs, v)
+		case model.Account:
+			result.Accounts = append(result.Accounts, v)
+		case model.File:
+			result.Files = append(result.Files, v)
+		}
+	}
+	reply(w, result)
+}
+
+// match implements the search terms understood by /my: key prefixes such as
+// #asset, field:value prefixes for dns, name, source, status and key, and
+// otherwise a substring of the key
+func match(term string, item any) bool {
+	v := reflect.ValueOf(item)
+	field := func(name string) string {
+		f := v.FieldByName(name)
+		if f.IsValid() && f.Kind() == reflect.String {
+			return f.String()
+		}
+		return ""
+	}
+
+	if strings.HasPrefix(term, "#") {
+		return strings.HasPrefix(field("Key"), term)
+	}
+	if name, value, ok := strings.Cut(term, ":"); ok {
+		fields := map[string]string{"dns": "DNS", "name": "Name", "source": "Source", "status": "Status", "key": "Key"}
+		if f, ok := fields[name]; ok {
+			return strings.HasPrefix(field(f), value)
+		}
+		if name == "ip" {
+			return field("Name") == value
+		}
+	}
+	return strings.Contains(field("Key"), term)
+}
+
+func (s *Server) asset(w http.ResponseWriter, r *http.Request) {
+	account, store := s.caller(r)
+	switch r.Method {
+	case "POST", "PUT":
+		var asset model.Asset
+		if !decode(w, r, &asset) {
+			return
+		}
+		if !asset.Valid() {
+			asset.Key = fmt.Sprintf("#asset#%s#%s", asset.DNS, asset.Name)
+		}
+		if existing, ok := store.Assets[asset.Key]; ok {
+			existing.Merge(asset)
+			existing.Updated = model.Now()
+			asset = existing
+		} else if r.Method == "PUT" {
+			notFound(w, asset.Key)
+			return
+		}
+		asset.Username = account
+		store.Assets[asset.Key] = asset
+		reply(w, []model.Asset{asset})
+	case "DELETE":
+		key := r.URL.Query().Get("key")
+		if _, ok := store.Assets[key]; !ok {
+			notFound(w, key)
+			return
+		}
+		delete(store.Assets, key)
+		reply(w, map[string]string{"key": key})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) risk(w http.ResponseWriter, r *http.Request) {
+	account, store := s.caller(r)
+	switch r.Method {
+	case "POST", "PUT":
+		var risk model.Risk
+		if !decode(w, r, &risk) {
+			return
+		}
+		if !risk.Valid() {
+			risk.Key = fmt.Sprintf("#risk#%s#%s", risk.DNS, risk.Name)
+		}
+		if existing, ok := store.Risks[risk.Key]; ok {
+			existing.Merge(risk)
+			existing.Updated = model.Now()
+			risk = existing
+		} else if r.Method == "PUT" {
+			notFound(w, risk.Key)
+			return
+		}
+		risk.Username = account
+		store.Risks[risk.Key] = risk
+		reply(w, []model.Risk{risk})
+	case "DELETE":
+		key := r.URL.Query().Get("key")
+		risk, ok := store.Risks[key]
+		if !ok {
+			notFound(w, key)
+			return
+		}
+		risk.Merge(model.Risk{Status: model.Deleted + risk.Severity()})
+		store.Risks[key] = risk
+		reply(w, []model.Risk{risk})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) attribute(w http.ResponseWriter, r *http.Request) {
+	account, store := s.caller(r)
+	switch r.Method {
+	case "POST":
+		var attribute model.Attribute
+		if !decode(w, r, &attribute) {
+			return
+		}
+		// the SDK sends the key of the parent asset or risk
+		attribute = model.NewAttribute(attribute.Name, attribute.Value, attribute.Key)
+		attribute.Username = account
+		store.Attributes[attribute.Key] = attribute
+		reply(w, []model.Attribute{attribute})
+	case "DELETE":
+		key := r.URL.Query().Get("key")
+		if _, ok := store.Attributes[key]; !ok {
+			notFound(w, key)
+			return
+		}
+		delete(store.Attributes, key)
+		reply(w, map[string]string{"key": key})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) job(w http.ResponseWriter, r *http.Request) {
+	account, store := s.caller(r)
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var job model.Job
+	if !decode(w, r, &job) {
+		return
+	}
+	job.Username = account
+	job.Status = model.Queued
+	store.Jobs[job.Key] = job
+	reply(w, []model.Job{job})
+}
+
+func (s *Server) account(w http.ResponseWriter, r *http.Request) {
+	caller, store := s.caller(r)
+	target := strings.TrimPrefix(r.URL.Path, "/account/")
+
+	var link struct {
+		Config map[string]any `json:"config"`
+		Value  string         `json:"value"`
+		TTL    int64          `json:"ttl"`
+	}
+	if !decode(w, r, &link) {
+		return
+	}
+
+	// linking a user adds them as a member, anything else (such as a
+	// webhook) is stored as a member of the caller's account
+	member, value := link.Value, ""
+	if target != caller {
+		member, value = target, link.Value
+	}
+	config := make(map[string]string)
+	for k, v := range link.Config {
+		config[k] = fmt.Sprint(v)
+	}
+	account := model.NewAccount(caller, member, value, config)
+	account.TTL = link.TTL
+
+	switch r.Method {
+	case "POST":
+		account.Username = caller
+		store.Accounts[account.Key] = account
+		reply(w, []model.Account{account})
+	case "DELETE":
+		if _, ok := store.Accounts[account.Key]; !ok {
+			notFound(w, account.Key)
+			return
+		}
+		delete(store.Accounts, account.Key)
+		reply(w, []model.Account{account})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) file(w http.ResponseWriter, r *http.Request) {
+	account, store := s.caller(r)
+	name := r.URL.Query().Get("name")
+	switch r.Method {
+	case "PUT":
+		// like S3, the presigned URL is signed for the requested content type
+		query := url.Values{"account": {account}, "name": {name}, "contentType": {r.URL.Query().Get("contentType")}, "X-Amz-Signature": {Signature}}
+		presigned := fmt.Sprintf("%s/upload?%s", s.URL, query.Encode())
+		reply(w, map[string]string{"url": presigned})
+	case "GET":
+		if _, ok := store.Files[name]; !ok {
+			notFound(w, name)
+			return
+		}
+		// like the API, redirect to a presigned URL of the S3 object
+		query := url.Values{"account": {account}, "name": {name}, "X-Amz-Signature": {Signature}}
+		http.Redirect(w, r, fmt.Sprintf("%s/download?%s", s.URL, query.Encode()), http.StatusTemporaryRedirect)
+	case "DELETE":
+		if _, ok := store.Files[name]; !ok {
+			notFound(w, name)
+			return
+		}
+		delete(store.Files, name)
+		reply(w, map[string]string{"name": name})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) upload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "PUT" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if r.URL.Query().Get("X-Amz-Signature") != Signature {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+	store := s.store(r.URL.Query().Get("account"))
+	contentType := r.URL.Query().Get("contentType")
+	if contentType != "" && r.Header.Get("Content-Type") != contentType {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	file := model.NewFile(r.URL.Query().Get("name"))
+	file.Username = r.URL.Query().Get("account")
+	file.Bytes = data
+	store.Files[file.Name] = file
+	store.ContentTypes[file.Name] = r.Header.Get("Content-Type")
+	w.WriteHeader(http.StatusOK)
+}
+
+// download serves a file from a presigned URL, answering Range requests like
+// S3 does
+func (s *Server) download(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if r.URL.Query().Get("X-Amz-Signature") != Signature {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+	name := r.URL.Query().Get("name")
+	file, ok := s.store(r.URL.Query().Get("account")).Files[name]
+	if !ok {
+		http.Error(w, "NoSuchKey", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(file.Bytes))
+}
+
+// hook accepts webhook payloads for the user and pin in the path
+func (s *Server) hook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/hook/"), "/")
+	if len(parts) != 2 {
+		notFound(w, r.URL.Path)
+		return
+	}
+	username, err := base64.RawStdEncoding.DecodeString(parts[0])
+	if err != nil {
+		notFound(w, r.URL.Path)
+		return
+	}
+	store, ok := s.stores[string(username)]
+	if !ok || !validPin(store, parts[1]) {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var payload webhook.Payload
+	if !decode(w, r, &payload) {
+		return
+	}
+	if err := payload.Validate(); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	asset := model.NewAsset(payload.DNS, payload.Name)
+	asset.Username = string(username)
+	if existing, ok := store.Assets[asset.Key]; ok {
+		asset = existing
+	}
+	store.Assets[asset.Key] = asset
+
+	switch {
+	case payload.Finding != "":
+		risk := model.NewRisk(asset, payload.Finding)
+		risk.Username = string(username)
+		if existing, ok := store.Risks[risk.Key]; ok {
+			risk = existing
+		}
+		if payload.Status != "" {
+			risk.Merge(model.Risk{Status: payload.Status})
+		}
+		store.Risks[risk.Key] = risk
+		reply(w, []model.Risk{risk})
+	case payload.Attribute != "":
+		attribute := model.NewAttribute(payload.Attribute, payload.Value, asset.Key)
+		attribute.Username = string(username)
+		store.Attributes[attribute.Key] = attribute
+		reply(w, []model.Attribute{attribute})
+	default:
+		reply(w, []model.Asset{asset})
+	}
+}
+
+func validPin(store *Store, pin string) bool {
+	for _, account := range store.Accounts {
+		if account.Member == "hook" && account.Config["pin"] == pin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+// Package digest summarizes the risks of an account over a period, for a
+// periodic email: risks found since the last digest, open risks that breach
+// their remediation SLA, and how many risks were remediated.
+package digest
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	textTemplate "text/template"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/report"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/sla"
+)
+
+//go:embed templates/*
+var templates embed.FS
+
+const (
+	HTML = "html"
+	Text = "text"
+)
+
+type Options struct {
+	Since time.Time
+	// Now is the end of the period, the current time when zero
+	Now time.Time
+	// Policy sets the remediation deadlines, sla.DefaultPolicy when nil
+	Policy *sla.Policy
+}
+
+type Digest struct {
+	Account   string
+	Since     time.Time
+	Generated time.Time
+	// New are the risks created during the period, most severe first
+	New []Entry
+	// Breaching are the open risks older than their SLA, oldest first
+	Breaching  []Entry
+	Open       int
+	Remediated int
+	Severities []Severity
+}
+
+// Severity counts the risks of one severity
+type Severity struct {
+	Code       string
+	Name       string
+	New        int
+	Open       int
+	Breaching  int
+	Remediated int
+}
+
+type Entry struct {
+	model.Risk
+	// Age is the whole days since the SLA clock started
+	Age int
+	// SLA is the whole days allowed for the risk's severity, 0 without one
+	SLA int
+}
+
+func (e Entry) Overdue() int {
+	return e.Age - e.SLA
+}
+
+func newEntry(m sla.Measurement, now time.Time) Entry {
+	start := m.Due.Add(-m.Deadline)
+	if m.Due.IsZero() {
+		start = m.Created
+	}
+	return Entry{Risk: m.Risk, Age: int(now.Sub(start) / sla.Day), SLA: int(m.Deadline / sla.Day)}
+}
+
+func Build(client *sdk.Chariot, options Options) (*Digest, error) {
+	risks, err := client.Risks.List()
+	if err != nil {
+		return nil, err
+	}
+	digest := New(risks, options)
+	digest.Account = client.Username
+	if client.GetAccount() != "" {
+		digest.Account = client.GetAccount()
+	}
+	return digest, nil
+}
+
+func rank(risk model.Risk) int {
+	for i, code := range sla.Severities {
+		if risk.Severity() == code {
+			return i
+		}
+	}
+	return len(sla.Severities)
+}
+
+// New summarizes risks over the period. Deleted risks are left out.
+func New(risks []model.Risk, options Options) *Digest {
+	now := options.Now
+	if now.IsZero() {
+		now = time.Now().UTC()
+	}
+	policy := sla.DefaultPolicy
+	if options.Policy != nil {
+		policy = *options.Policy
+	}
+	digest := &Digest{Since: options.Since, Generated: now}
+
+	counts := make(map[string]*Severity)
+	for _, code := range sla.Severities {
+		counts[code] = &Severity{Code: code, Name: report.SeverityName(code)}
+	}
+
+	for _, risk := range risks {
+		if risk.Status == "" || risk.Is(model.Deleted) || risk.Is(model.MachineDeleted) {
+			continue
+		}
+		count, ok := counts[risk.Severity()]
+		if !ok {
+			continue
+		}
+		m := sla.Measure(risk, policy, now)
+		entry := newEntry(m, now)
+
+		if !m.Created.IsZero() && !m.Created.Before(options.Since) && !m.Created.After(now) {
+			digest.New = append(digest.New, entry)
+			count.New++
+		}
+
+		state := risk.State()
+		switch {
+		case state == model.Open || state == model.MachineOpen:
+			digest.Open++
+			count.Open++
+			if m.Overdue {
+				digest.Breaching = append(digest.Breaching, entry)
+				count.Breaching++
+			}
+		case !m.Remediated.IsZero() && !m.Remediated.Before(options.Since) && !m.Remediated.After(now):
+			digest.Remediated++
+			count.Remediated++
+		}
+	}
+
+	sort.SliceStable(digest.New, func(i, j int) bool {
+		if rank(digest.New[i].Risk) != rank(digest.New[j].Risk) {
+			return rank(digest.New[i].Risk) < rank(digest.New[j].Risk)
+		}
+		return digest.New[i].Key < digest.New[j].Key
+	})
+	sort.SliceStable(digest.Breaching, func(i, j int) bool {
+		if digest.Breaching[i].Overdue() != digest.Breaching[j].Overdue() {
+			return digest.Breaching[i].Overdue() > digest.Breaching[j].Overdue()
+		}
+		return digest.Breaching[i].Key < digest.Breaching[j].Key
+	})
+	for _, code := range sla.Severities {
+		digest.Severities = append(digest.Severities, *counts[code])
+	}
+	return digest
+}
+
+func (d *Digest) Subject() string {
+	return fmt.Sprintf("Chariot digest for %s: %d new, %d past SLA, %d remediated", d.Account, len(d.New), len(d.Breaching), d.Remediated)
+}
+
+var funcs = textTemplate.FuncMap{
+	"state":    report.StateName,
+	"severity": report.SeverityName,
+	"date": func(t time.Time) string {
+		return t.Format(time.DateOnly)
+	},
+}
+
+// Render writes the digest as html or text
+func (d *Digest) Render(w io.Writer, format string) error {
+	switch format {
+	case HTML:
+		tmpl, err := template.New("digest.html.tmpl").Funcs(template.FuncMap(funcs)).ParseFS(templates, "templates/digest.html.tmpl")
+		if err != nil {
+			return err
+		}
+		return tmpl.Execute(w, d)
+	case Text:
+		tmpl, err := textTemplate.New("digest.txt.tmpl").Funcs(funcs).ParseFS(templates, "templates/digest.txt.tmpl")
+		if err != nil {
+			return err
+		}
+		return tmpl.Execute(w, d)
+	}
+	return fmt.Errorf("unsupported format %s", format)
+}
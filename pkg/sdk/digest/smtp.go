@@ -0,0 +1,175 @@
+package digest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+const (
+	// StartTLS upgrades the connection, and fails when the server does not
+	// offer STARTTLS rather than continuing in plaintext
+	StartTLS = "starttls"
+	// ImplicitTLS connects over TLS, usually on port 465
+	ImplicitTLS = "tls"
+	// NoTLS never encrypts; credentials are only sent to localhost
+	NoTLS = "none"
+)
+
+type SMTPConfig struct {
+	// Address is host:port of the SMTP server
+	Address  string
+	Username string
+	Password string
+	From     string
+	TLS      string
+	Timeout  time.Duration
+}
+
+// Send emails the digest to every recipient as a multipart message with text
+// and HTML alternatives
+func Send(config SMTPConfig, to []string, d *Digest) error {
+	if len(to) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender %q: %v", config.From, err)
+	}
+	recipients := make([]string, len(to))
+	for i, address := range to {
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %v", address, err)
+		}
+		recipients[i] = parsed.Address
+	}
+
+	message, err := Message(from.String(), to, d)
+	if err != nil {
+		return err
+	}
+
+	client, err := dial(config)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if config.Username != "" {
+		host, _, _ := net.SplitHostPort(config.Address)
+		// PlainAuth refuses to send credentials unencrypted, except to localhost
+		if err := client.Auth(smtp.PlainAuth("", config.Username, config.Password, host)); err != nil {
+			return fmt.Errorf("failed to authenticate: %v", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("recipient %s rejected: %v", recipient, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func dial(config SMTPConfig) (*smtp.Client, error) {
+	host, _, err := net.SplitHostPort(config.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP address %q, expected host:port", config.Address)
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	tlsConfig := &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+
+	var conn net.Conn
+	switch config.TLS {
+	case ImplicitTLS:
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", config.Address, tlsConfig)
+	case "", StartTLS, NoTLS:
+		conn, err = net.DialTimeout("tcp", config.Address, timeout)
+	default:
+		return nil, fmt.Errorf("invalid TLS mode %q, expected starttls, tls or none", config.TLS)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %v", config.Address, err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if config.TLS == "" || config.TLS == StartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("%s does not offer STARTTLS", config.Address)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to start TLS: %v", err)
+		}
+	}
+	return client, nil
+}
+
+// Message renders the digest as a MIME message with text and HTML parts
+func Message(from string, to []string, d *Digest) ([]byte, error) {
+	var text, html bytes.Buffer
+	if err := d.Render(&text, Text); err != nil {
+		return nil, err
+	}
+	if err := d.Render(&html, HTML); err != nil {
+		return nil, err
+	}
+
+	random := make([]byte, 12)
+	rand.Read(random)
+	boundary := "chariot-" + hex.EncodeToString(random)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", d.Subject()))
+	fmt.Fprintf(&b, "Date: %s\r\n", d.Generated.Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+
+	for _, part := range []struct {
+		contentType string
+		body        []byte
+	}{{"text/plain", text.Bytes()}, {"text/html", html.Bytes()}} {
+		fmt.Fprintf(&b, "--%s\r\n", boundary)
+		fmt.Fprintf(&b, "Content-Type: %s; charset=utf-8\r\n", part.contentType)
+		fmt.Fprintf(&b, "Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		qp := quotedprintable.NewWriter(&b)
+		qp.Write(part.body)
+		qp.Close()
+		fmt.Fprintf(&b, "\r\n")
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return b.Bytes(), nil
+}
@@ -0,0 +1,162 @@
+package digest
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/digest/smtptest"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+func testDigest() *Digest {
+	now := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+	risk := model.NewRisk(model.NewAsset("acme.com", "acme.com"), "sql-injection-"+strings.Repeat("é", 60))
+	risk.Status = model.OpenCritical
+	risk.Created = now.Add(-24 * time.Hour).Format(time.RFC3339)
+	d := New([]model.Risk{risk}, Options{Since: now.Add(-7 * 24 * time.Hour), Now: now})
+	d.Account = "acme@praetorian.com"
+	return d
+}
+
+func TestMessage(t *testing.T) {
+	d := testDigest()
+	data, err := Message("Chariot <chariot@acme.com>", []string{"alice@acme.com", "bob@acme.com"}, d)
+	if err != nil {
+		t.Fatalf("Message: %v", err)
+	}
+	message, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+
+	headers := []struct {
+		name, want string
+	}{
+		{"From", "Chariot <chariot@acme.com>"},
+		{"To", "alice@acme.com, bob@acme.com"},
+		{"MIME-Version", "1.0"},
+	}
+	for _, header := range headers {
+		if got := message.Header.Get(header.name); got != header.want {
+			t.Errorf("%s = %q, want %q", header.name, got, header.want)
+		}
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	if err != nil || subject != d.Subject() {
+		t.Errorf("Subject = %q (%v), want %q", subject, err, d.Subject())
+	}
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %s (%v), want multipart/alternative", mediaType, err)
+	}
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	var types []string
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextRawPart: %v", err)
+		}
+		types = append(types, part.Header.Get("Content-Type"))
+		if encoding := part.Header.Get("Content-Transfer-Encoding"); encoding != "quoted-printable" {
+			t.Errorf("part %s is encoded as %q", part.Header.Get("Content-Type"), encoding)
+		}
+		raw, _ := io.ReadAll(part)
+		for _, line := range strings.Split(string(raw), "\r\n") {
+			if len(line) > 76 {
+				t.Errorf("encoded line of %d characters", len(line))
+			}
+		}
+		body, err := io.ReadAll(quotedprintable.NewReader(bytes.NewReader(raw)))
+		if err != nil {
+			t.Fatalf("quoted-printable: %v", err)
+		}
+		if !strings.Contains(string(body), d.New[0].Name) {
+			t.Errorf("part %s does not name the new risk", part.Header.Get("Content-Type"))
+		}
+	}
+	want := []string{"text/plain; charset=utf-8", "text/html; charset=utf-8"}
+	if !slices.Equal(types, want) {
+		t.Errorf("parts = %q, want %q", types, want)
+	}
+}
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   SMTPConfig
+		to       []string
+		wantTo   []string
+		username string
+		fails    bool
+	}{
+		{
+			name:   "recipients",
+			config: SMTPConfig{From: "chariot@acme.com", TLS: NoTLS},
+			to:     []string{"Alice <alice@acme.com>", "bob@acme.com"},
+			wantTo: []string{"alice@acme.com", "bob@acme.com"},
+		},
+		{
+			name:     "authenticated",
+			config:   SMTPConfig{From: "Chariot <chariot@acme.com>", Username: "chariot", Password: "secret", TLS: NoTLS},
+			to:       []string{"security@acme.com"},
+			wantTo:   []string{"security@acme.com"},
+			username: "chariot",
+		},
+		{
+			name:   "starttls is required",
+			config: SMTPConfig{From: "chariot@acme.com", TLS: StartTLS},
+			to:     []string{"security@acme.com"},
+			fails:  true,
+		},
+		{
+			name:   "invalid recipient",
+			config: SMTPConfig{From: "chariot@acme.com", TLS: NoTLS},
+			to:     []string{"not an address"},
+			fails:  true,
+		},
+		{
+			name:   "no recipients",
+			config: SMTPConfig{From: "chariot@acme.com", TLS: NoTLS},
+			fails:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := smtptest.NewServer()
+			defer server.Close()
+			test.config.Address = server.Addr()
+			test.config.Timeout = 5 * time.Second
+
+			err := Send(test.config, test.to, testDigest())
+			messages := server.Messages()
+			if test.fails {
+				if err == nil || len(messages) != 0 {
+					t.Fatalf("Send = %v with %d messages, want an error and none", err, len(messages))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+			if len(messages) != 1 {
+				t.Fatalf("got %d messages, want 1", len(messages))
+			}
+			message := messages[0]
+			if message.From != "chariot@acme.com" || !slices.Equal(message.To, test.wantTo) || message.Username != test.username {
+				t.Errorf("envelope from %s to %v as %q, want chariot@acme.com to %v as %q", message.From, message.To, message.Username, test.wantTo, test.username)
+			}
+		})
+	}
+}
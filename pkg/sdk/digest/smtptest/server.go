@@ -0,0 +1,163 @@
+// Package smtptest provides an in-process SMTP sink that accepts every message
+// and keeps it in memory, so digests can be sent without a mail server.
+//
+//	server := smtptest.NewServer()
+//	defer server.Close()
+//	digest.Send(digest.SMTPConfig{Address: server.Addr(), From: "chariot@acme.com", TLS: digest.NoTLS}, to, d)
+//	messages := server.Messages()
+package smtptest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+type Message struct {
+	From string
+	To   []string
+	// Username is the identity from AUTH PLAIN, if the client authenticated
+	Username string
+	Data     []byte
+}
+
+type Server struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	messages []Message
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a sink listening on a random localhost port
+func NewServer() *Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("smtptest: failed to listen: %v", err))
+	}
+	s := &Server{listener: listener}
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+// Addr is the host:port of the sink
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *Server) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+// Messages returns every message accepted so far
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			s.handle(textproto.NewConn(conn))
+		}()
+	}
+}
+
+func (s *Server) handle(conn *textproto.Conn) {
+	var current Message
+	var username string
+	conn.PrintfLine("220 smtptest ESMTP ready")
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			conn.PrintfLine("250-smtptest greets %s", arg)
+			conn.PrintfLine("250-8BITMIME")
+			conn.PrintfLine("250 AUTH PLAIN")
+		case "HELO":
+			conn.PrintfLine("250 smtptest")
+		case "AUTH":
+			mechanism, initial, _ := strings.Cut(arg, " ")
+			if !strings.EqualFold(mechanism, "PLAIN") {
+				conn.PrintfLine("504 unrecognized authentication type")
+				continue
+			}
+			if initial == "" {
+				conn.PrintfLine("334 ")
+				if initial, err = conn.ReadLine(); err != nil {
+					return
+				}
+			}
+			decoded, err := base64.StdEncoding.DecodeString(initial)
+			parts := bytes.Split(decoded, []byte{0})
+			if err != nil || len(parts) != 3 {
+				conn.PrintfLine("501 malformed credentials")
+				continue
+			}
+			username = string(parts[1])
+			conn.PrintfLine("235 authentication successful")
+		case "MAIL":
+			current = Message{From: address(arg), Username: username}
+			conn.PrintfLine("250 ok")
+		case "RCPT":
+			if current.From == "" {
+				conn.PrintfLine("503 need MAIL first")
+				continue
+			}
+			current.To = append(current.To, address(arg))
+			conn.PrintfLine("250 ok")
+		case "DATA":
+			if len(current.To) == 0 {
+				conn.PrintfLine("503 need RCPT first")
+				continue
+			}
+			conn.PrintfLine("354 end data with <CR><LF>.<CR><LF>")
+			data, err := conn.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			current.Data = data
+			s.mu.Lock()
+			s.messages = append(s.messages, current)
+			s.mu.Unlock()
+			current = Message{}
+			conn.PrintfLine("250 queued")
+		case "RSET":
+			current = Message{}
+			conn.PrintfLine("250 ok")
+		case "NOOP":
+			conn.PrintfLine("250 ok")
+		case "QUIT":
+			conn.PrintfLine("221 bye")
+			return
+		default:
+			conn.PrintfLine("502 command not implemented")
+		}
+	}
+}
+
+// address extracts the mailbox from FROM:<a@b> or TO:<a@b>
+func address(arg string) string {
+	_, value, _ := strings.Cut(arg, ":")
+	value, _, _ = strings.Cut(strings.TrimSpace(value), " ")
+	return strings.Trim(value, "<>")
+}
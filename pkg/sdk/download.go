@@ -1,12 +1,23 @@
 package sdk
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
+	"unicode/utf8"
 
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/envelope"
 	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
 )
 
+// ErrNotFound is returned when a downloaded file does not exist
+var ErrNotFound = errors.New("file not found")
+
 func (c *Chariot) DownloadPoE(risk model.Risk) ([]byte, error) {
 	return c.Download(fmt.Sprintf("proofs/%s/%s", risk.DNS, risk.Name))
 }
@@ -20,16 +31,103 @@ func (c *Chariot) DownloadFile(file model.File) ([]byte, error) {
 }
 
 func (c *Chariot) Download(name string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := c.DownloadTo(context.Background(), name, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DownloadTo streams a file from Chariot file storage into w. Encrypted files
+// are decrypted with the keychain's keys. When the checksum does not match, w
+// will already have received the data.
+func (c *Chariot) DownloadTo(ctx context.Context, name string, w io.Writer, options ...TransferOption) error {
+	t := newTransfer(options)
+
 	baseURL, err := url.Parse(c.API + "/file")
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	baseURL.RawQuery = url.Values{"name": {name}}.Encode()
-	body, err := c.request("GET", baseURL.String(), nil)
+	resp, err := c.do(ctx, "GET", baseURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("unexpected status code %d, %s", resp.StatusCode, body)
+	}
+
+	var source io.Reader = bufio.NewReader(resp.Body)
+	total := resp.ContentLength
+	if prefix, _ := source.(*bufio.Reader).Peek(len(envelope.Magic)); envelope.IsSealed(prefix) && !t.raw {
+		keyring, err := c.Keyring()
+		if err != nil {
+			return fmt.Errorf("%s is encrypted: %v", name, err)
+		}
+		opener, err := envelope.NewReader(source, keyring)
+		if err != nil {
+			return err
+		}
+		if total > 0 {
+			total = opener.PlainSize(total)
+		}
+		source = opener
+	}
+
+	m := t.meter(source, total)
+	if _, err := io.Copy(w, m); err != nil {
+		return err
+	}
+	return t.verify(m)
+}
+
+// peek returns up to n bytes from the start of a stored file with a ranged
+// request, so large files are not downloaded in full. Servers that ignore the
+// range still only have n bytes read.
+func (c *Chariot) peek(ctx context.Context, name string, n int) ([]byte, error) {
+	baseURL, err := url.Parse(c.API + "/file")
+	if err != nil {
+		return nil, err
+	}
+	baseURL.RawQuery = url.Values{"name": {name}}.Encode()
+
+	header := http.Header{"Range": {fmt.Sprintf("bytes=0-%d", n-1)}}
+	resp, err := c.doWithHeader(ctx, "GET", baseURL.String(), nil, header)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
-	return body, nil
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+	case http.StatusRequestedRangeNotSatisfiable:
+		// empty files have no first byte
+		return nil, nil
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("unexpected status code %d, %s", resp.StatusCode, body)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, int64(n)))
+}
+
+// Excerpt returns up to limit bytes of data, cut at a rune boundary and marked
+// as truncated. A limit of zero or less returns all of data.
+func Excerpt(data []byte, limit int) string {
+	if limit <= 0 || len(data) <= limit {
+		return string(data)
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(data[cut]) {
+		cut--
+	}
+	return string(data[:cut]) + "\n[truncated]"
 }
@@ -0,0 +1,78 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/envelope"
+)
+
+// headerPeek is how much of a file is fetched to read its envelope header,
+// which is well under 1KB in practice
+const headerPeek = 4096
+
+// Rewrap re-encrypts the data key of a stored file with key, leaving the
+// encrypted body untouched. It reports false for files that are not
+// encrypted or already use key; those are told apart by the envelope header,
+// fetched with a ranged request, so only files to rewrap are downloaded.
+func (c *Chariot) Rewrap(ctx context.Context, name string, key envelope.Key) (bool, error) {
+	keyring, err := c.Keyring()
+	if err != nil {
+		return false, err
+	}
+
+	head, err := c.peek(ctx, name, headerPeek)
+	if err != nil {
+		return false, err
+	}
+	if !envelope.IsSealed(head) {
+		return false, nil
+	}
+	// headers longer than the peek are checked after the full download
+	if opener, err := envelope.NewReader(bytes.NewReader(head), append(keyring, key)); err == nil && opener.Header().Key == key.ID {
+		return false, nil
+	}
+
+	sealed, err := os.CreateTemp("", "chariot-rewrap-*")
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(sealed.Name())
+	defer sealed.Close()
+
+	if err := c.DownloadTo(ctx, name, sealed, raw()); err != nil {
+		return false, err
+	}
+	if _, err := sealed.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+
+	opener, err := envelope.NewReader(sealed, append(keyring, key))
+	if err != nil {
+		return false, err
+	}
+	if opener.Header().Key == key.ID {
+		return false, nil
+	}
+	if _, err := sealed.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(envelope.Rewrap(pw, sealed, keyring, key))
+	}()
+	defer pr.Close()
+
+	info, err := sealed.Stat()
+	if err != nil {
+		return false, err
+	}
+	// the rewrapped header has the same length as the original
+	if err := c.UploadReader(ctx, name, pr, info.Size(), raw(), WithContentType("application/octet-stream")); err != nil {
+		return false, err
+	}
+	return true, nil
+}
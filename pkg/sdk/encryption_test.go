@@ -0,0 +1,172 @@
+package sdk_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/chariottest"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/envelope"
+)
+
+// downloads records the file downloads made through it, and whether each one
+// asked for a range
+type downloads struct {
+	next http.RoundTripper
+
+	mu     sync.Mutex
+	full   []string
+	ranged []string
+}
+
+func (d *downloads) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == "GET" && req.URL.Path == "/file" {
+		d.mu.Lock()
+		if req.Header.Get("Range") != "" {
+			d.ranged = append(d.ranged, req.URL.Query().Get("name"))
+		} else {
+			d.full = append(d.full, req.URL.Query().Get("name"))
+		}
+		d.mu.Unlock()
+	}
+	return d.next.RoundTrip(req)
+}
+
+// newEncryptingClient returns a client of server whose keychain profile has
+// key as its team key
+func newEncryptingClient(t *testing.T, server *chariottest.Server, key envelope.Key) *sdk.Chariot {
+	t.Helper()
+	return newProfileClient(t, server, "encrypt = true\nencryption_key = "+key.String()+"\n")
+}
+
+// newProfileClient returns a client of server read from a keychain profile
+// with the given settings
+func newProfileClient(t *testing.T, server *chariottest.Server, settings string) *sdk.Chariot {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	profile := "[United States]\nname = chariot\nusername = " + server.Username + "\napi = " + server.URL + "\n" + settings
+	if err := os.MkdirAll(filepath.Join(home, ".praetorian"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".praetorian", "keychain.ini"), []byte(profile), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	keychain := sdk.NewKeychainFromIniFile("")
+	keychain.Provider = sdk.StaticToken(server.Token)
+	client := sdk.NewClientWithKeychain(keychain)
+	client.HTTPClient = server.Client()
+	return client
+}
+
+func generateKey(t *testing.T) envelope.Key {
+	t.Helper()
+	key, err := envelope.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return key
+}
+
+func TestEncryptedUpload(t *testing.T) {
+	server := chariottest.NewServer()
+	defer server.Close()
+	client := newEncryptingClient(t, server, generateKey(t))
+	proof := []byte("<html>password=hunter2</html>")
+
+	if err := client.Upload("proofs/acme.com/sql-injection", proof); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	store := server.Store("")
+	if stored := store.Files["proofs/acme.com/sql-injection"].Bytes; !envelope.IsSealed(stored) || bytes.Contains(stored, []byte("hunter2")) {
+		t.Fatalf("the proof was stored in plaintext: %q", stored)
+	}
+	if got := store.ContentTypes["proofs/acme.com/sql-injection"]; got != "application/octet-stream" {
+		t.Errorf("content type = %q, want application/octet-stream", got)
+	}
+	if got, err := client.Download("proofs/acme.com/sql-injection"); err != nil || !bytes.Equal(got, proof) {
+		t.Fatalf("Download() = %q, %v", got, err)
+	}
+
+	// definitions are rendered by the UI, so they stay readable
+	if err := client.Upload("definitions/sql-injection", []byte("# SQL Injection")); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if stored := store.Files["definitions/sql-injection"].Bytes; string(stored) != "# SQL Injection" {
+		t.Errorf("definition was stored as %q", stored)
+	}
+}
+
+func TestRewrap(t *testing.T) {
+	server := chariottest.NewServer()
+	defer server.Close()
+	previous, current := generateKey(t), generateKey(t)
+
+	client := newEncryptingClient(t, server, previous)
+	large := bytes.Repeat([]byte("x"), 3*envelope.ChunkSize)
+	for name, data := range map[string][]byte{"proofs/old": []byte("old proof"), "proofs/large": large} {
+		if err := client.Upload(name, data); err != nil {
+			t.Fatalf("Upload: %v", err)
+		}
+	}
+	client.Encrypt = false
+	if err := client.Upload("proofs/plain", large); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if err := client.Upload("proofs/empty", nil); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+
+	recorder := &downloads{next: client.HTTPClient.Transport}
+	client.HTTPClient = &http.Client{Transport: recorder}
+
+	tests := []struct {
+		name string
+		want bool
+		// full is whether the whole file has to be downloaded
+		full bool
+	}{
+		{"proofs/old", true, true},
+		{"proofs/large", true, true},
+		{"proofs/plain", false, false},
+		{"proofs/empty", false, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder.full, recorder.ranged = nil, nil
+			ok, err := client.Rewrap(context.Background(), test.name, current)
+			if err != nil {
+				t.Fatalf("Rewrap: %v", err)
+			}
+			if ok != test.want {
+				t.Errorf("Rewrap() = %v, want %v", ok, test.want)
+			}
+			if len(recorder.ranged) != 1 {
+				t.Errorf("made %d ranged requests, want 1", len(recorder.ranged))
+			}
+			if full := len(recorder.full) > 0; full != test.full {
+				t.Errorf("downloaded the whole file = %v, want %v", full, test.full)
+			}
+		})
+	}
+
+	// files already rewrapped are recognized from their header alone
+	recorder.full = nil
+	if ok, err := client.Rewrap(context.Background(), "proofs/large", current); ok || err != nil {
+		t.Fatalf("second Rewrap() = %v, %v, want false", ok, err)
+	}
+	if len(recorder.full) != 0 {
+		t.Errorf("a rewrapped file was downloaded again")
+	}
+
+	rotated := newEncryptingClient(t, server, current)
+	if got, err := rotated.Download("proofs/large"); err != nil || !bytes.Equal(got, large) {
+		t.Fatalf("Download with the new key = %d bytes, %v", len(got), err)
+	}
+}
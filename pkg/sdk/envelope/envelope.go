@@ -0,0 +1,399 @@
+// Package envelope implements client-side envelope encryption for files
+// stored in Chariot. Every file is encrypted with its own random data key
+// using chunked AES-256-GCM, and the data key is wrapped with a team key.
+// Rotating the team key only rewrites the header, never the file body.
+//
+// Layout: Magic | uint32 header length | JSON header | sealed chunks
+package envelope
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	Magic     = "CHARIOT-ENVELOPE\n"
+	Version   = 1
+	Algorithm = "AES-256-GCM"
+	ChunkSize = 64 * 1024
+	KeySize   = 32
+
+	maxHeader = 64 * 1024
+)
+
+var ErrUnknownKey = errors.New("file was encrypted with a key that is not in the keychain")
+
+type Header struct {
+	Version   int    `json:"version"`
+	Algorithm string `json:"algorithm"`
+	Chunk     int    `json:"chunk"`
+	ID        string `json:"id"`
+	Key       string `json:"key"`
+	Wrapped   string `json:"wrapped"`
+}
+
+// additional data binds every chunk to the file without covering the
+// wrapped key, so the key can be rewrapped without re-encrypting
+func (h Header) aad() []byte {
+	return []byte(fmt.Sprintf("%s%d|%d|%s", Magic, h.Version, h.Chunk, h.ID))
+}
+
+func (h Header) marshal() ([]byte, error) {
+	body, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 0, len(Magic)+4+len(body))
+	buf = append(buf, Magic...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(body)))
+	return append(buf, body...), nil
+}
+
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+func NewKey(secret []byte) (Key, error) {
+	if len(secret) != KeySize {
+		return Key{}, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(secret))
+	}
+	sum := sha256.Sum256(secret)
+	return Key{ID: hex.EncodeToString(sum[:8]), Secret: secret}, nil
+}
+
+// ParseKey decodes a base64 encoded team key, as stored in the keychain
+func ParseKey(encoded string) (Key, error) {
+	secret, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return Key{}, fmt.Errorf("invalid encryption key: %v", err)
+	}
+	return NewKey(secret)
+}
+
+func GenerateKey() (Key, error) {
+	secret := make([]byte, KeySize)
+	if _, err := rand.Read(secret); err != nil {
+		return Key{}, err
+	}
+	return NewKey(secret)
+}
+
+func (k Key) String() string {
+	return base64.StdEncoding.EncodeToString(k.Secret)
+}
+
+func (k Key) wrap(dataKey []byte, id string) (string, error) {
+	aead, err := gcm(k.Secret)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, dataKey, []byte(id))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (k Key) unwrap(wrapped, id string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := gcm(k.Secret)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("wrapped key is truncated")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, []byte(id))
+}
+
+// Keyring holds the current team key first, followed by previous keys that
+// are still accepted for decryption
+type Keyring []Key
+
+func (k Keyring) find(id string) (Key, bool) {
+	for _, key := range k {
+		if key.ID == id {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+func gcm(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func nonce(counter uint64, final bool) []byte {
+	n := make([]byte, 12)
+	binary.BigEndian.PutUint64(n[3:11], counter)
+	if final {
+		n[11] = 1
+	}
+	return n
+}
+
+// IsSealed reports whether data starts with an envelope header
+func IsSealed(prefix []byte) bool {
+	return bytes.HasPrefix(prefix, []byte(Magic))
+}
+
+// Writer encrypts everything written to it. Close must be called to seal the
+// final chunk; it does not close the underlying writer.
+type Writer struct {
+	w       io.Writer
+	header  []byte
+	aad     []byte
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+	started bool
+	closed  bool
+}
+
+func NewWriter(w io.Writer, key Key) (*Writer, error) {
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	h := Header{
+		Version:   Version,
+		Algorithm: Algorithm,
+		Chunk:     ChunkSize,
+		ID:        base64.StdEncoding.EncodeToString(id),
+		Key:       key.ID,
+	}
+	wrapped, err := key.wrap(dataKey, h.ID)
+	if err != nil {
+		return nil, err
+	}
+	h.Wrapped = wrapped
+
+	header, err := h.marshal()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := gcm(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, header: header, aad: h.aad(), aead: aead, buf: make([]byte, 0, ChunkSize)}, nil
+}
+
+// SealedSize returns how many bytes the writer produces for size bytes of input
+func (w *Writer) SealedSize(size int64) int64 {
+	chunks := (size + ChunkSize - 1) / ChunkSize
+	if chunks == 0 {
+		chunks = 1
+	}
+	return int64(len(w.header)) + size + chunks*int64(w.aead.Overhead())
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("write to closed envelope")
+	}
+	written := 0
+	for len(p) > 0 {
+		// a full buffer is only sealed once more data arrives, so the last
+		// chunk is always the one sealed as final by Close
+		if len(w.buf) == ChunkSize {
+			if err := w.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(w.buf[len(w.buf):ChunkSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.seal(true)
+}
+
+func (w *Writer) seal(final bool) error {
+	if !w.started {
+		if _, err := w.w.Write(w.header); err != nil {
+			return err
+		}
+		w.started = true
+	}
+	sealed := w.aead.Seal(nil, nonce(w.counter, final), w.buf, w.aad)
+	w.counter++
+	w.buf = w.buf[:0]
+	_, err := w.w.Write(sealed)
+	return err
+}
+
+// Reader decrypts an envelope, failing on any modified or truncated chunk
+type Reader struct {
+	r       *bufio.Reader
+	header  Header
+	aad     []byte
+	aead    cipher.AEAD
+	size    int64
+	plain   []byte
+	counter uint64
+	done    bool
+}
+
+func readHeader(r *bufio.Reader) (Header, []byte, error) {
+	prefix := make([]byte, len(Magic)+4)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return Header{}, nil, fmt.Errorf("invalid envelope: %v", err)
+	}
+	if !IsSealed(prefix) {
+		return Header{}, nil, errors.New("invalid envelope: missing header")
+	}
+	length := binary.BigEndian.Uint32(prefix[len(Magic):])
+	if length > maxHeader {
+		return Header{}, nil, errors.New("invalid envelope: header too large")
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return Header{}, nil, fmt.Errorf("invalid envelope: %v", err)
+	}
+
+	var h Header
+	if err := json.Unmarshal(body, &h); err != nil {
+		return Header{}, nil, fmt.Errorf("invalid envelope header: %v", err)
+	}
+	if h.Version != Version || h.Algorithm != Algorithm || h.Chunk <= 0 {
+		return Header{}, nil, fmt.Errorf("unsupported envelope version %d (%s)", h.Version, h.Algorithm)
+	}
+	return h, append(prefix, body...), nil
+}
+
+func NewReader(r io.Reader, keys Keyring) (*Reader, error) {
+	buffered := bufio.NewReaderSize(r, ChunkSize+64)
+	h, raw, err := readHeader(buffered)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := keys.find(h.Key)
+	if !ok {
+		return nil, fmt.Errorf("%w (key %s)", ErrUnknownKey, h.Key)
+	}
+	dataKey, err := key.unwrap(h.Wrapped, h.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %v", err)
+	}
+	aead, err := gcm(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{r: buffered, header: h, aad: h.aad(), aead: aead, size: int64(len(raw))}, nil
+}
+
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// PlainSize returns the decrypted size of an envelope that is sealed bytes long
+func (r *Reader) PlainSize(sealed int64) int64 {
+	body := sealed - r.size
+	chunk := int64(r.header.Chunk + r.aead.Overhead())
+	chunks := (body + chunk - 1) / chunk
+	return body - chunks*int64(r.aead.Overhead())
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+func (r *Reader) open() error {
+	sealed := make([]byte, r.header.Chunk+r.aead.Overhead())
+	n, err := io.ReadFull(r.r, sealed)
+	switch {
+	case err == io.ErrUnexpectedEOF || err == io.EOF:
+		r.done = true
+	case err != nil:
+		return err
+	default:
+		if _, err := r.r.Peek(1); err == io.EOF {
+			r.done = true
+		}
+	}
+	plain, err := r.aead.Open(sealed[:0], nonce(r.counter, r.done), sealed[:n], r.aad)
+	if err != nil {
+		return errors.New("envelope is corrupt or truncated")
+	}
+	r.counter++
+	r.plain = plain
+	return nil
+}
+
+// Rewrap copies an envelope from r to w with its data key wrapped by key
+// instead of the key it was sealed with. The encrypted body is not modified.
+func Rewrap(w io.Writer, r io.Reader, keys Keyring, key Key) error {
+	buffered := bufio.NewReader(r)
+	h, _, err := readHeader(buffered)
+	if err != nil {
+		return err
+	}
+	current, ok := keys.find(h.Key)
+	if !ok {
+		return fmt.Errorf("%w (key %s)", ErrUnknownKey, h.Key)
+	}
+	dataKey, err := current.unwrap(h.Wrapped, h.ID)
+	if err != nil {
+		return fmt.Errorf("failed to unwrap data key: %v", err)
+	}
+
+	h.Key = key.ID
+	if h.Wrapped, err = key.wrap(dataKey, h.ID); err != nil {
+		return err
+	}
+	header, err := h.marshal()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(w, buffered)
+	return err
+}
@@ -0,0 +1,136 @@
+package envelope
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func generateKey(t *testing.T) Key {
+	t.Helper()
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return key
+}
+
+func seal(t *testing.T, key Key, plain []byte) []byte {
+	t.Helper()
+	var sealed bytes.Buffer
+	w, err := NewWriter(&sealed, key)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	if _, err := w.Write(plain); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got, want := int64(sealed.Len()), w.SealedSize(int64(len(plain))); got != want {
+		t.Errorf("sealed %d bytes, SealedSize() = %d", got, want)
+	}
+	return sealed.Bytes()
+}
+
+func open(sealed []byte, keys Keyring) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(sealed), keys)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestRoundTrip(t *testing.T) {
+	key := generateKey(t)
+	sizes := []int{0, 1, ChunkSize - 1, ChunkSize, ChunkSize + 1, 3*ChunkSize + 10}
+	for _, size := range sizes {
+		plain := bytes.Repeat([]byte{'x'}, size)
+		sealed := seal(t, key, plain)
+		if !IsSealed(sealed) {
+			t.Fatalf("size %d: sealed data has no envelope header", size)
+		}
+
+		r, err := NewReader(bytes.NewReader(sealed), Keyring{key})
+		if err != nil {
+			t.Fatalf("size %d: NewReader: %v", size, err)
+		}
+		if got := r.PlainSize(int64(len(sealed))); got != int64(size) {
+			t.Errorf("size %d: PlainSize() = %d", size, got)
+		}
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("size %d: ReadAll: %v", size, err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Errorf("size %d: decrypted %d bytes that do not match", size, len(got))
+		}
+	}
+}
+
+func TestTruncation(t *testing.T) {
+	key := generateKey(t)
+	plain := bytes.Repeat([]byte{'x'}, 2*ChunkSize+10)
+	sealed := seal(t, key, plain)
+	overhead := 16
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"final chunk removed", sealed[:len(sealed)-(10+overhead)]},
+		{"final chunks removed", sealed[:len(sealed)-(10+overhead)-(ChunkSize+overhead)]},
+		{"last byte removed", sealed[:len(sealed)-1]},
+		{"chunk modified", append(append([]byte{}, sealed[:len(sealed)-5]...), 0, 0, 0, 0, 0)},
+		{"data appended", append(append([]byte{}, sealed...), 'x')},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := open(test.data, Keyring{key}); err == nil {
+				t.Fatal("a damaged envelope was decrypted without an error")
+			}
+		})
+	}
+
+	header := bytes.Index(sealed, []byte("}")) + 1
+	if _, err := open(sealed[:header], Keyring{key}); err == nil {
+		t.Error("an envelope without chunks was decrypted without an error")
+	}
+	if _, err := open(sealed[:header-1], Keyring{key}); err == nil {
+		t.Error("an envelope with a truncated header was decrypted without an error")
+	}
+}
+
+func TestKeyring(t *testing.T) {
+	current, previous := generateKey(t), generateKey(t)
+	plain := []byte("proof of exploit")
+	sealed := seal(t, previous, plain)
+
+	if _, err := open(sealed, Keyring{current}); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("open with the wrong key = %v, want ErrUnknownKey", err)
+	}
+	if got, err := open(sealed, Keyring{current, previous}); err != nil || !bytes.Equal(got, plain) {
+		t.Fatalf("open with a previous key = %q, %v", got, err)
+	}
+
+	var rewrapped bytes.Buffer
+	if err := Rewrap(&rewrapped, bytes.NewReader(sealed), Keyring{current, previous}, current); err != nil {
+		t.Fatalf("Rewrap: %v", err)
+	}
+	if got, err := open(rewrapped.Bytes(), Keyring{current}); err != nil || !bytes.Equal(got, plain) {
+		t.Fatalf("open after Rewrap = %q, %v", got, err)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	key := generateKey(t)
+	parsed, err := ParseKey(key.String())
+	if err != nil || parsed.ID != key.ID {
+		t.Fatalf("ParseKey(%s) = %v, %v", key, parsed.ID, err)
+	}
+	if _, err := ParseKey("c2hvcnQ="); err == nil {
+		t.Error("ParseKey accepted a short key")
+	}
+}
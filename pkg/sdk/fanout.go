@@ -0,0 +1,68 @@
+package sdk
+
+import (
+	"context"
+	"sort"
+)
+
+// WithAccount returns a client acting in account. The clone shares this
+// client's credentials, token, transport and rate limit, so it is safe to use
+// alongside the original and other clones.
+func (c *Chariot) WithAccount(account string) *Chariot {
+	config := c.Keychain.KeychainConfig
+	config.account = account
+	keychain := newKeychain(config)
+	keychain.Provider = c.Keychain
+
+	clone := NewClientWithKeychain(keychain)
+	clone.HTTPClient = c.HTTPClient
+	clone.Limiter = c.Limiter
+	clone.Concurrency = c.Concurrency
+	return clone
+}
+
+// LinkedAccounts returns the accounts the current user is a member of
+func (c *Chariot) LinkedAccounts() ([]string, error) {
+	accounts, err := c.Accounts.List()
+	if err != nil {
+		return nil, err
+	}
+	linked := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, account := range accounts {
+		if account.Member != c.Username || account.Name == c.Username || seen[account.Name] {
+			continue
+		}
+		seen[account.Name] = true
+		linked = append(linked, account.Name)
+	}
+	sort.Strings(linked)
+	return linked, nil
+}
+
+type AccountResult[T any] struct {
+	Account string
+	Value   T
+	Err     error
+}
+
+// FanOut calls fn with a client for every account, in parallel, and returns
+// the results in the order of accounts. An empty account runs fn with c itself.
+func FanOut[T any](c *Chariot, accounts []string, fn func(*Chariot) (T, error)) []AccountResult[T] {
+	results := make([]AccountResult[T], len(accounts))
+	pending := make([]*AccountResult[T], len(accounts))
+	for i, account := range accounts {
+		results[i].Account = account
+		pending[i] = &results[i]
+	}
+
+	ForEach(context.Background(), pending, c.Concurrency, func(_ context.Context, result *AccountResult[T]) error {
+		client := c
+		if result.Account != "" {
+			client = c.WithAccount(result.Account)
+		}
+		result.Value, result.Err = fn(client)
+		return nil
+	})
+	return results
+}
@@ -0,0 +1,315 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+const (
+	DomainNode    = "domain"
+	IPNode        = "ip"
+	AssetNode     = "asset"
+	PortNode      = "port"
+	ProtocolNode  = "protocol"
+	AttributeNode = "attribute"
+	RiskNode      = "risk"
+)
+
+var nodeOrder = map[string]int{
+	IPNode:        0,
+	AssetNode:     1,
+	PortNode:      2,
+	ProtocolNode:  3,
+	AttributeNode: 4,
+	RiskNode:      5,
+}
+
+type Node struct {
+	Key      string  `json:"key"`
+	Kind     string  `json:"kind"`
+	Label    string  `json:"label"`
+	Status   string  `json:"status,omitempty"`
+	Children []*Node `json:"children,omitempty"`
+}
+
+func (n *Node) add(child *Node) {
+	for _, c := range n.Children {
+		if c == child {
+			return
+		}
+	}
+	n.Children = append(n.Children, child)
+}
+
+func (n *Node) sort() {
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		if nodeOrder[a.Kind] != nodeOrder[b.Kind] {
+			return nodeOrder[a.Kind] < nodeOrder[b.Kind]
+		}
+		return a.Label < b.Label
+	})
+	for _, c := range n.Children {
+		c.sort()
+	}
+}
+
+// Graph links assets, attributes and risks into a tree per domain:
+// domain -> ip -> port -> protocol -> risk
+type Graph struct {
+	Roots   []*Node
+	keys    map[string]*Node
+	domains map[string]*Node
+	names   map[string][]*Node
+	ports   map[string][]*Node
+}
+
+func (c *Chariot) Graph() (*Graph, error) {
+	assets, err := c.Assets.List()
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := c.Attributes.List()
+	if err != nil {
+		return nil, err
+	}
+	risks, err := c.Risks.List()
+	if err != nil {
+		return nil, err
+	}
+	return NewGraph(assets, attributes, risks), nil
+}
+
+func NewGraph(assets []model.Asset, attributes []model.Attribute, risks []model.Risk) *Graph {
+	g := &Graph{
+		keys:    make(map[string]*Node),
+		domains: make(map[string]*Node),
+		names:   make(map[string][]*Node),
+		ports:   make(map[string][]*Node),
+	}
+
+	for _, asset := range assets {
+		g.addAsset(asset)
+	}
+
+	bySource := make(map[string][]model.Attribute)
+	for _, attribute := range attributes {
+		bySource[attribute.Source] = append(bySource[attribute.Source], attribute)
+	}
+	sources := make([]string, 0, len(bySource))
+	for source := range bySource {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		if parent, ok := g.keys[source]; ok {
+			g.addAttributes(parent, source, bySource[source])
+		}
+	}
+
+	for _, risk := range risks {
+		g.addRisk(risk, bySource[risk.Key])
+	}
+
+	sort.Slice(g.Roots, func(i, j int) bool { return g.Roots[i].Label < g.Roots[j].Label })
+	for _, root := range g.Roots {
+		root.sort()
+	}
+	return g
+}
+
+func (g *Graph) domain(dns string) *Node {
+	if node, ok := g.domains[dns]; ok {
+		return node
+	}
+	node := &Node{Key: "#asset#" + dns, Kind: DomainNode, Label: dns}
+	g.domains[dns] = node
+	g.keys[node.Key] = node
+	g.Roots = append(g.Roots, node)
+	return node
+}
+
+func (g *Graph) addAsset(asset model.Asset) {
+	domain := g.domain(asset.DNS)
+	if asset.Name == asset.DNS {
+		domain.Key = asset.Key
+		domain.Status = asset.Status
+		g.keys[asset.Key] = domain
+		return
+	}
+
+	kind := AssetNode
+	switch asset.Class() {
+	case "ipv4", "ipv6", "private":
+		kind = IPNode
+	}
+	node := &Node{Key: asset.Key, Kind: kind, Label: asset.Name, Status: asset.Status}
+	domain.add(node)
+	g.keys[asset.Key] = node
+	g.names[asset.Name] = append(g.names[asset.Name], node)
+}
+
+func (g *Graph) addAttributes(parent *Node, source string, attributes []model.Attribute) {
+	dns := source
+	if asset, err := model.GetAssetFromKey(source); err == nil {
+		dns = asset.DNS
+	}
+
+	ports := make(map[string]*Node)
+	for _, attribute := range attributes {
+		if attribute.Name != "port" {
+			continue
+		}
+		node := &Node{Key: attribute.Key, Kind: PortNode, Label: "port " + attribute.Value}
+		parent.add(node)
+		ports[attribute.Value] = node
+		g.keys[attribute.Key] = node
+		g.ports[dns+"#"+attribute.Value] = append(g.ports[dns+"#"+attribute.Value], node)
+	}
+
+	// protocols are named after the protocol with the port as value, so only
+	// attributes whose value is a known port are protocols, not e.g. asn=13335
+	for _, attribute := range attributes {
+		if attribute.Name == "port" {
+			continue
+		}
+		port, ok := ports[attribute.Value]
+		if !ok {
+			node := &Node{Key: attribute.Key, Kind: AttributeNode, Label: attribute.Name + "=" + attribute.Value}
+			parent.add(node)
+			g.keys[attribute.Key] = node
+			continue
+		}
+
+		node := &Node{Key: attribute.Key, Kind: ProtocolNode, Label: attribute.Name}
+		port.add(node)
+		g.keys[attribute.Key] = node
+		g.ports[dns+"#"+attribute.Value] = append(g.ports[dns+"#"+attribute.Value], node)
+	}
+}
+
+func (g *Graph) addRisk(risk model.Risk, attributes []model.Attribute) {
+	node := &Node{Key: risk.Key, Kind: RiskNode, Label: risk.Name, Status: risk.Status}
+	for _, attribute := range attributes {
+		node.add(&Node{Key: attribute.Key, Kind: AttributeNode, Label: attribute.Name + "=" + attribute.Value})
+	}
+	g.keys[risk.Key] = node
+
+	if parent := g.exposure(risk.DNS, attributes); parent != nil {
+		parent.add(node)
+		return
+	}
+	if domain, ok := g.domains[risk.DNS]; ok {
+		domain.add(node)
+		return
+	}
+	if named, ok := g.names[risk.DNS]; ok {
+		named[0].add(node)
+		return
+	}
+	g.domain(risk.DNS).add(node)
+}
+
+// exposure finds the most specific port or protocol node a risk hangs off,
+// based on the port and protocol attributes recorded against the risk
+func (g *Graph) exposure(dns string, attributes []model.Attribute) *Node {
+	for _, attribute := range attributes {
+		if _, err := strconv.Atoi(attribute.Value); err != nil {
+			continue
+		}
+		candidates := slices.Clone(g.ports[dns+"#"+attribute.Value])
+		if len(candidates) == 0 {
+			continue
+		}
+		// ports before protocols, then by key, so every run picks the same node
+		sort.Slice(candidates, func(i, j int) bool {
+			if nodeOrder[candidates[i].Kind] != nodeOrder[candidates[j].Kind] {
+				return nodeOrder[candidates[i].Kind] < nodeOrder[candidates[j].Kind]
+			}
+			return candidates[i].Key < candidates[j].Key
+		})
+		for _, candidate := range candidates {
+			if candidate.Kind == ProtocolNode && candidate.Label == attribute.Name {
+				return candidate
+			}
+		}
+		return candidates[0]
+	}
+	return nil
+}
+
+// Find returns the node for an asset, attribute or risk key. Plain DNS names
+// and asset keys without a matching asset resolve to their domain.
+func (g *Graph) Find(key string) (*Node, bool) {
+	if node, ok := g.keys[key]; ok {
+		return node, true
+	}
+	if node, ok := g.domains[key]; ok {
+		return node, true
+	}
+	if asset, err := model.GetAssetFromKey(key); err == nil && strings.HasPrefix(key, "#asset#") {
+		node, ok := g.domains[asset.DNS]
+		return node, ok
+	}
+	return nil, false
+}
+
+func (g *Graph) Tree(w io.Writer, root *Node) {
+	fmt.Fprintln(w, root.describe())
+	tree(w, root, "")
+}
+
+func tree(w io.Writer, node *Node, prefix string) {
+	for i, child := range node.Children {
+		branch, indent := "├── ", "│   "
+		if i == len(node.Children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		fmt.Fprintf(w, "%s%s%s\n", prefix, branch, child.describe())
+		tree(w, child, prefix+indent)
+	}
+}
+
+func (n *Node) describe() string {
+	if n.Status != "" {
+		return fmt.Sprintf("%s [%s] (%s)", n.Label, n.Kind, n.Status)
+	}
+	return fmt.Sprintf("%s [%s]", n.Label, n.Kind)
+}
+
+func (g *Graph) DOT(w io.Writer, root *Node) {
+	fmt.Fprintln(w, "digraph chariot {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box];")
+	dot(w, root, make(map[*Node]bool))
+	fmt.Fprintln(w, "}")
+}
+
+func dot(w io.Writer, node *Node, seen map[*Node]bool) {
+	if seen[node] {
+		return
+	}
+	seen[node] = true
+	label := node.Label
+	if node.Status != "" {
+		label += "\n" + node.Status
+	}
+	fmt.Fprintf(w, "  %q [label=%q, class=%q];\n", node.Key, label, node.Kind)
+	for _, child := range node.Children {
+		fmt.Fprintf(w, "  %q -> %q;\n", node.Key, child.Key)
+		dot(w, child, seen)
+	}
+}
+
+func (g *Graph) JSON(w io.Writer, root *Node) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(root)
+}
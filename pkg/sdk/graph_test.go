@@ -0,0 +1,92 @@
+package sdk_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+func render(t *testing.T, g *sdk.Graph, key string) string {
+	t.Helper()
+	root, ok := g.Find(key)
+	if !ok {
+		t.Fatalf("Find(%s) found nothing", key)
+	}
+	var buf bytes.Buffer
+	g.Tree(&buf, root)
+	return buf.String()
+}
+
+func TestNewGraph(t *testing.T) {
+	domain := model.NewAsset("api.acme.com", "api.acme.com")
+	ip := model.NewAsset("api.acme.com", "1.2.3.4")
+	risk := model.NewRisk(ip, "weak-tls")
+	risk.Status = model.OpenHigh
+	unexposed := model.NewRisk(domain, "spf-missing")
+	unexposed.Status = model.OpenLow
+
+	attributes := []model.Attribute{
+		model.NewAttribute("port", "443", ip.Key),
+		model.NewAttribute("https", "443", ip.Key),
+		model.NewAttribute("asn", "13335", ip.Key),
+		model.NewAttribute("https", "443", risk.Key),
+	}
+	g := sdk.NewGraph([]model.Asset{domain, ip}, attributes, []model.Risk{risk, unexposed})
+
+	want := `api.acme.com [domain] (A)
+├── 1.2.3.4 [ip] (A)
+│   ├── port 443 [port]
+│   │   └── https [protocol]
+│   │       └── weak-tls [risk] (OH)
+│   │           └── https=443 [attribute]
+│   └── asn=13335 [attribute]
+└── spf-missing [risk] (OL)
+`
+	if got := render(t, g, "api.acme.com"); got != want {
+		t.Errorf("tree =\n%s\nwant\n%s", got, want)
+	}
+	if got := render(t, g, risk.Key); got != "weak-tls [risk] (OH)\n└── https=443 [attribute]\n" {
+		t.Errorf("risk tree =\n%s", got)
+	}
+	if _, ok := g.Find("#asset#other.com#other.com"); ok {
+		t.Errorf("Find returned a node for an unknown domain")
+	}
+}
+
+func TestNewGraphIsDeterministic(t *testing.T) {
+	assets := []model.Asset{
+		model.NewAsset("acme.com", "acme.com"),
+		model.NewAsset("acme.com", "1.2.3.4"),
+		model.NewAsset("acme.com", "5.6.7.8"),
+		model.NewAsset("acme.com", "www.acme.com"),
+	}
+	var attributes []model.Attribute
+	for _, asset := range assets[1:] {
+		attributes = append(attributes,
+			model.NewAttribute("port", "443", asset.Key),
+			model.NewAttribute("https", "443", asset.Key),
+			model.NewAttribute("port", "22", asset.Key),
+		)
+	}
+	risk := model.NewRisk(assets[0], "exposed-admin")
+	risk.Status = model.OpenHigh
+	ssh := model.NewRisk(assets[0], "weak-ssh")
+	ssh.Status = model.OpenMedium
+	attributes = append(attributes,
+		model.NewAttribute("https", "443", risk.Key),
+		model.NewAttribute("port", "22", ssh.Key),
+	)
+
+	want := render(t, sdk.NewGraph(assets, attributes, []model.Risk{risk, ssh}), "acme.com")
+	random := rand.New(rand.NewSource(1))
+	for range 50 {
+		shuffled := append([]model.Attribute{}, attributes...)
+		random.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		if got := render(t, sdk.NewGraph(assets, shuffled, []model.Risk{risk, ssh}), "acme.com"); got != want {
+			t.Fatalf("tree changed with the attribute order:\n%s\nwant\n%s", got, want)
+		}
+	}
+}
@@ -2,14 +2,19 @@ package sdk
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/envelope"
 	"gopkg.in/ini.v1"
 )
 
@@ -21,6 +26,13 @@ type KeychainConfig struct {
 	password string
 	region   string
 	account  string
+	// Encrypt seals uploads with the team encryption key
+	Encrypt        bool
+	encryptionKeys []string
+	// Rate and Burst limit requests per second, zero is unlimited
+	Rate        float64
+	Burst       int
+	Concurrency int
 }
 
 func (k *Keychain) GetAccount() string {
@@ -31,8 +43,34 @@ func (k *Keychain) SetAccount(account string) {
 	k.account = account
 }
 
+// Keyring returns the team encryption key from the profile, followed by any
+// previous keys that are still accepted for decryption
+func (k *Keychain) Keyring() (envelope.Keyring, error) {
+	if len(k.encryptionKeys) == 0 {
+		return nil, fmt.Errorf("no encryption_key set in the keychain profile")
+	}
+	var keyring envelope.Keyring
+	for _, encoded := range k.encryptionKeys {
+		key, err := envelope.ParseKey(encoded)
+		if err != nil {
+			return nil, err
+		}
+		keyring = append(keyring, key)
+	}
+	return keyring, nil
+}
+
 func NewKeychainFromIniFile(profile string) *Keychain {
-	cfg, err := ini.Load(os.ExpandEnv("$HOME/.praetorian/keychain.ini"))
+	path := os.ExpandEnv("$HOME/.praetorian/keychain.ini")
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		// commands that need no credentials, such as webhook send, still
+		// work; anything else fails on its first request
+		keychain := newKeychain(KeychainConfig{region: "us-east-2", Concurrency: DefaultConcurrency})
+		keychain.Provider = missingKeychain(path)
+		return keychain
+	}
+
+	cfg, err := ini.Load(path)
 	if err != nil {
 		log.Fatalf("Failed to read file: %v", err)
 	}
@@ -61,6 +99,19 @@ func NewKeychainFromIniFile(profile string) *Keychain {
 		password: section.Key("password").String(),
 		account:  "",
 		region:   "us-east-2",
+		Encrypt:  section.Key("encrypt").MustBool(false),
+
+		Rate:        section.Key("rate").MustFloat64(0),
+		Burst:       section.Key("burst").MustInt(1),
+		Concurrency: section.Key("concurrency").MustInt(DefaultConcurrency),
+	}
+	if key := section.Key("encryption_key").String(); key != "" {
+		config.encryptionKeys = append(config.encryptionKeys, key)
+	}
+	for _, key := range strings.Split(section.Key("previous_encryption_keys").String(), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			config.encryptionKeys = append(config.encryptionKeys, key)
+		}
 	}
 	return newKeychain(config)
 }
@@ -69,6 +120,26 @@ type Keychain struct {
 	KeychainConfig
 	tokenCache  string
 	tokenExpiry int64
+	tokenMu     sync.Mutex
+	// Provider replaces Cognito authentication when set
+	Provider TokenProvider
+}
+
+type TokenProvider interface {
+	GetToken() (string, error)
+}
+
+// StaticToken authenticates every request with the same token
+type StaticToken string
+
+func (t StaticToken) GetToken() (string, error) {
+	return string(t), nil
+}
+
+type missingKeychain string
+
+func (m missingKeychain) GetToken() (string, error) {
+	return "", fmt.Errorf("no keychain found at %s", string(m))
 }
 
 func newKeychain(config KeychainConfig) *Keychain {
@@ -77,7 +148,22 @@ func newKeychain(config KeychainConfig) *Keychain {
 	}
 }
 
+// NewKeychain builds a keychain for the given API and user that obtains
+// tokens from provider rather than from keychain.ini credentials
+func NewKeychain(api, username string, provider TokenProvider) *Keychain {
+	keychain := newKeychain(KeychainConfig{API: api, Username: username, region: "us-east-2", Concurrency: DefaultConcurrency})
+	keychain.Provider = provider
+	return keychain
+}
+
 func (k *Keychain) GetToken() (string, error) {
+	if k.Provider != nil {
+		return k.Provider.GetToken()
+	}
+
+	// concurrent requests share one token rather than each authenticating
+	k.tokenMu.Lock()
+	defer k.tokenMu.Unlock()
 	if k.tokenCache == "" || time.Now().Unix() >= k.tokenExpiry {
 		cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(k.region))
 		if err != nil {
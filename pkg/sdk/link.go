@@ -1,18 +1,13 @@
 package sdk
 
 import (
-	"encoding/base64"
 	"encoding/json"
-	"fmt"
 	"net/url"
-	"strings"
 
 	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
-
-	"github.com/google/uuid"
 )
 
-func (s *AccountService) linkHelper(method, username, id string, config interface{}) error {
+func (s *AccountService) linkHelper(method, username, id string, config interface{}, ttl int64) error {
 	baseURL, err := url.Parse(s.Client.API + "/account/" + username)
 	if err != nil {
 		return err
@@ -21,9 +16,11 @@ func (s *AccountService) linkHelper(method, username, id string, config interfac
 	linkRequest := &struct {
 		Config interface{} `json:"config"`
 		Value  string      `json:"value"`
+		TTL    int64       `json:"ttl,omitempty"`
 	}{
 		Config: config,
 		Value:  id,
+		TTL:    ttl,
 	}
 	body, err := json.Marshal(linkRequest)
 	if err != nil {
@@ -37,19 +34,16 @@ func (s *AccountService) linkHelper(method, username, id string, config interfac
 	return nil
 }
 
-func (s *AccountService) AddWebhook() (string, error) {
-	pin := uuid.New().String()
-	err := s.Link("hook", "", map[string]interface{}{"pin": pin})
-	if err != nil {
-		return "", err
-	}
-	username := base64.StdEncoding.EncodeToString([]byte(s.Client.Keychain.Username))
-	encodedUsername := strings.TrimRight(username, "=")
-	return fmt.Sprintf("%s/hook/%s/%s", s.Client.API, encodedUsername, pin), nil
-}
-
 func (s *AccountService) Add(item model.Account) error {
-	return s.Link(s.Client.Username, item.Member, nil)
+	var config map[string]interface{}
+	if len(item.Config) > 0 {
+		config = make(map[string]interface{})
+		for key, value := range item.Config {
+			config[key] = value
+		}
+	}
+	// the TTL lets the backend expire the link, e.g. for a temporary collaborator
+	return s.linkHelper("POST", s.Client.Username, item.Member, config, item.TTL)
 }
 
 func (s *AccountService) Delete(item model.Account) error {
@@ -57,9 +51,9 @@ func (s *AccountService) Delete(item model.Account) error {
 }
 
 func (s *AccountService) Unlink(username, id string) error {
-	return s.linkHelper("DELETE", username, id, nil)
+	return s.linkHelper("DELETE", username, id, nil, 0)
 }
 
 func (s *AccountService) Link(username, id string, config map[string]interface{}) error {
-	return s.linkHelper("POST", username, id, config)
+	return s.linkHelper("POST", username, id, config, 0)
 }
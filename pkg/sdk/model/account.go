@@ -1,6 +1,18 @@
 package model
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
+
+// Collaborator roles stored in Account.Config. A role is a label for audits;
+// every collaborator has full access regardless of it.
+const (
+	RoleAdmin    = "admin"
+	RoleReadOnly = "read-only"
+)
+
+var Roles = []string{RoleAdmin, RoleReadOnly}
 
 type Account struct {
 	Username string `dynamodbav:"username" json:"username"`
@@ -24,3 +36,38 @@ func NewAccount(name, member, value string, config map[string]string) Account {
 		Key:     fmt.Sprintf("#account#%s#%s#%s", name, member, value),
 	}
 }
+
+// NewCollaborator links member to name with a role, expiring at expires
+// unless it is zero
+func NewCollaborator(name, member, role string, expires time.Time) Account {
+	config := map[string]string{"role": role}
+	account := NewAccount(name, member, "", config)
+	if !expires.IsZero() {
+		config["expires"] = expires.UTC().Format(time.RFC3339)
+		account.TTL = expires.Unix()
+	}
+	return account
+}
+
+func (a *Account) Role() string {
+	if role := a.Config["role"]; role != "" {
+		return role
+	}
+	return RoleAdmin
+}
+
+// Expires returns when access ends, from the config or the TTL
+func (a *Account) Expires() (time.Time, bool) {
+	if expires, err := time.Parse(time.RFC3339, a.Config["expires"]); err == nil {
+		return expires, true
+	}
+	if a.TTL > 0 {
+		return time.Unix(a.TTL, 0).UTC(), true
+	}
+	return time.Time{}, false
+}
+
+func (a *Account) Expired(now time.Time) bool {
+	expires, ok := a.Expires()
+	return ok && !now.Before(expires)
+}
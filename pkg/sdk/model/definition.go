@@ -0,0 +1,214 @@
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DefinitionSections must appear, in any order, as level two headings of
+// every risk definition
+var DefinitionSections = []string{"Description", "Impact", "Recommendation", "References"}
+
+// definitionPlaceholders are the bodies NewDefinition scaffolds, which must be
+// replaced before a definition is valid
+var definitionPlaceholders = map[string]string{
+	"Description":    "TODO: describe the vulnerability and how it was identified.",
+	"Impact":         "TODO: describe what an attacker could achieve by exploiting it.",
+	"Recommendation": "TODO: describe how to remediate it.",
+	"References":     "- TODO: https://",
+}
+
+// Definition is the markdown document the Chariot UI renders for a risk. It
+// may start with a front-matter block of key: value pairs, e.g. severity: H
+type Definition struct {
+	Name     string
+	Title    string
+	Meta     map[string]string
+	Sections map[string]string
+	order    []string
+	problems []string
+}
+
+func ParseDefinition(name string, data []byte) Definition {
+	definition := Definition{
+		Name:     name,
+		Meta:     make(map[string]string),
+		Sections: make(map[string]string),
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+
+	if len(lines) > 0 && lines[0] == "---" {
+		closed := false
+		for i := 1; i < len(lines); i++ {
+			if lines[i] == "---" {
+				lines = lines[i+1:]
+				closed = true
+				break
+			}
+			key, value, ok := strings.Cut(lines[i], ":")
+			if !ok {
+				definition.problems = append(definition.problems, fmt.Sprintf("invalid front-matter line %q", lines[i]))
+				continue
+			}
+			definition.Meta[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		}
+		if !closed {
+			definition.problems = append(definition.problems, "front-matter is not closed with ---")
+			lines = nil
+		}
+	}
+
+	section := ""
+	fence := ""
+	var body []string
+	flush := func() {
+		if section != "" {
+			definition.Sections[section] = strings.TrimSpace(strings.Join(body, "\n"))
+		}
+		body = nil
+	}
+	for _, line := range lines {
+		// headings inside fenced code blocks are code, not structure
+		if marker := codeFence(line); marker != "" && (fence == "" || strings.HasPrefix(marker, fence)) {
+			if fence == "" {
+				fence = marker
+			} else {
+				fence = ""
+			}
+			body = append(body, line)
+			continue
+		}
+		switch {
+		case fence != "":
+			body = append(body, line)
+		case strings.HasPrefix(line, "# ") && definition.Title == "":
+			definition.Title = strings.TrimSpace(line[2:])
+		case strings.HasPrefix(line, "## "):
+			flush()
+			section = strings.TrimSpace(line[3:])
+			if _, ok := definition.Sections[section]; ok {
+				definition.problems = append(definition.problems, fmt.Sprintf("duplicate section %q", section))
+			} else {
+				definition.order = append(definition.order, section)
+			}
+		default:
+			body = append(body, line)
+		}
+	}
+	flush()
+
+	return definition
+}
+
+// codeFence returns the ``` or ~~~ run opening or closing a fenced code block
+func codeFence(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return ""
+	}
+	for _, char := range []string{"`", "~"} {
+		n := len(trimmed) - len(strings.TrimLeft(trimmed, char))
+		if n >= 3 {
+			return strings.Repeat(char, n)
+		}
+	}
+	return ""
+}
+
+func (d *Definition) Severity() string {
+	return strings.ToUpper(d.Meta["severity"])
+}
+
+// Lint returns every problem found in the definition, or nil when it is valid
+func (d *Definition) Lint() []error {
+	var errs []error
+	for _, problem := range d.problems {
+		errs = append(errs, fmt.Errorf("%s: %s", d.Name, problem))
+	}
+
+	if strings.ContainsAny(d.Name, "/#") || strings.TrimSpace(d.Name) == "" {
+		errs = append(errs, fmt.Errorf("%s: invalid risk name", d.Name))
+	}
+	if d.Title == "" {
+		errs = append(errs, fmt.Errorf("%s: missing title heading", d.Name))
+	}
+	if severity, ok := d.Meta["severity"]; ok {
+		switch d.Severity() {
+		case "I", "L", "M", "H", "C":
+		default:
+			errs = append(errs, fmt.Errorf("%s: invalid severity %q, expected I, L, M, H or C", d.Name, severity))
+		}
+	}
+
+	for _, name := range DefinitionSections {
+		body, ok := d.Sections[name]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("%s: missing section %q", d.Name, name))
+		case body == "":
+			errs = append(errs, fmt.Errorf("%s: section %q is empty", d.Name, name))
+		case strings.Contains(body, definitionPlaceholders[name]):
+			errs = append(errs, fmt.Errorf("%s: section %q still contains the scaffold placeholder", d.Name, name))
+		}
+	}
+	return errs
+}
+
+func (d *Definition) Markdown() []byte {
+	var buf bytes.Buffer
+	if len(d.Meta) > 0 {
+		keys := make([]string, 0, len(d.Meta))
+		for key := range d.Meta {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buf.WriteString("---\n")
+		for _, key := range keys {
+			fmt.Fprintf(&buf, "%s: %s\n", key, d.Meta[key])
+		}
+		buf.WriteString("---\n")
+	}
+	fmt.Fprintf(&buf, "# %s\n", d.Title)
+
+	sections := append([]string{}, DefinitionSections...)
+	for _, name := range d.order {
+		if !slices.Contains(sections, name) {
+			sections = append(sections, name)
+		}
+	}
+	for _, name := range sections {
+		if body, ok := d.Sections[name]; ok {
+			fmt.Fprintf(&buf, "\n## %s\n\n%s\n", name, body)
+		}
+	}
+	return buf.Bytes()
+}
+
+// NewDefinition scaffolds a definition with placeholder text in every required
+// section. The placeholders fail linting until they are replaced.
+func NewDefinition(name, title, severity string) Definition {
+	definition := Definition{
+		Name:     name,
+		Title:    title,
+		Meta:     make(map[string]string),
+		Sections: make(map[string]string),
+	}
+	for name, placeholder := range definitionPlaceholders {
+		definition.Sections[name] = placeholder
+	}
+	if severity != "" {
+		definition.Meta["severity"] = severity
+	}
+	return definition
+}
@@ -0,0 +1,111 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+const validDefinition = `---
+severity: H
+---
+# SQL Injection
+
+## Description
+
+User input reaches a SQL query.
+
+## Impact
+
+An attacker can read the database.
+
+## Recommendation
+
+Use parameterized queries.
+
+## References
+
+- https://owasp.org/www-community/attacks/SQL_Injection
+`
+
+func TestParseDefinition(t *testing.T) {
+	definition := ParseDefinition("sql-injection", []byte(validDefinition))
+	if definition.Title != "SQL Injection" {
+		t.Errorf("Title = %q", definition.Title)
+	}
+	if definition.Severity() != "H" {
+		t.Errorf("Severity() = %q, want H", definition.Severity())
+	}
+	if got := definition.Sections["Impact"]; got != "An attacker can read the database." {
+		t.Errorf("Impact = %q", got)
+	}
+	if errs := definition.Lint(); errs != nil {
+		t.Errorf("Lint() = %v, want no problems", errs)
+	}
+
+	again := ParseDefinition("sql-injection", definition.Markdown())
+	if string(again.Markdown()) != string(definition.Markdown()) {
+		t.Errorf("Markdown() does not round trip:\n%s\n---\n%s", definition.Markdown(), again.Markdown())
+	}
+}
+
+func TestParseDefinitionCodeFences(t *testing.T) {
+	data := strings.Replace(validDefinition, "Use parameterized queries.", "Use parameterized queries:\n\n```\n## not a section\n~~~\n```\n\n~~~~\n# not a title\n~~~~", 1)
+	definition := ParseDefinition("sql-injection", []byte(data))
+	if _, ok := definition.Sections["not a section"]; ok {
+		t.Errorf("heading inside a code fence was parsed as a section")
+	}
+	if !strings.Contains(definition.Sections["Recommendation"], "## not a section") || !strings.Contains(definition.Sections["Recommendation"], "# not a title") {
+		t.Errorf("Recommendation lost its code blocks: %q", definition.Sections["Recommendation"])
+	}
+	if errs := definition.Lint(); errs != nil {
+		t.Errorf("Lint() = %v, want no problems", errs)
+	}
+}
+
+func TestDefinitionLint(t *testing.T) {
+	tests := []struct {
+		name    string
+		risk    string
+		data    string
+		problem string
+	}{
+		{"invalid name", "a/b", validDefinition, "invalid risk name"},
+		{"missing title", "sql-injection", strings.Replace(validDefinition, "# SQL Injection\n", "", 1), "missing title heading"},
+		{"invalid severity", "sql-injection", strings.Replace(validDefinition, "severity: H", "severity: X", 1), "invalid severity"},
+		{"missing section", "sql-injection", strings.Replace(validDefinition, "## Impact", "## Effect", 1), `missing section "Impact"`},
+		{"empty section", "sql-injection", strings.Replace(validDefinition, "Use parameterized queries.", "", 1), `section "Recommendation" is empty`},
+		{"duplicate section", "sql-injection", validDefinition + "\n## Impact\n\nMore.\n", `duplicate section "Impact"`},
+		{"unclosed front-matter", "sql-injection", strings.Replace(validDefinition, "---\n#", "#", 1), "front-matter is not closed"},
+		{"invalid front-matter", "sql-injection", strings.Replace(validDefinition, "severity: H", "severity: H\nnot a pair", 1), "invalid front-matter line"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			definition := ParseDefinition(test.risk, []byte(test.data))
+			errs := definition.Lint()
+			for _, err := range errs {
+				if strings.Contains(err.Error(), test.problem) {
+					return
+				}
+			}
+			t.Errorf("Lint() = %v, want a problem containing %q", errs, test.problem)
+		})
+	}
+}
+
+func TestNewDefinitionPlaceholders(t *testing.T) {
+	definition := NewDefinition("sql-injection", "SQL Injection", "H")
+	errs := definition.Lint()
+	if len(errs) != len(DefinitionSections) {
+		t.Fatalf("Lint() = %v, want one placeholder problem per section", errs)
+	}
+	for _, err := range errs {
+		if !strings.Contains(err.Error(), "scaffold placeholder") {
+			t.Errorf("unexpected problem %v", err)
+		}
+	}
+
+	parsed := ParseDefinition("sql-injection", definition.Markdown())
+	if len(parsed.Lint()) != len(DefinitionSections) {
+		t.Errorf("placeholders were lost when parsing the scaffold: %v", parsed.Lint())
+	}
+}
@@ -0,0 +1,148 @@
+package model
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scorer ranks risks by combining severity (or CVSS when available) with the
+// priority of the affected asset, how exposed it is, how long the risk has
+// been known and its EPSS exploit probability.
+type Scorer struct {
+	Severity map[string]float64
+	Priority map[string]float64
+	Exposure map[string]float64
+	// points added per day since the risk was created, capped at MaxAge
+	AgeWeight float64
+	MaxAge    float64
+	Now       time.Time
+}
+
+type ScoredRisk struct {
+	Risk
+	Score float64 `json:"score"`
+}
+
+func NewScorer() Scorer {
+	return Scorer{
+		Severity: map[string]float64{
+			"I": 0.5,
+			"L": 2.5,
+			"M": 5.0,
+			"H": 7.5,
+			"C": 9.5,
+		},
+		Priority: map[string]float64{
+			ActiveHigh: 1.5,
+			Active:     1.0,
+			ActiveLow:  0.75,
+			Frozen:     0.5,
+			FrozenHigh: 0.5,
+			FrozenLow:  0.5,
+		},
+		Exposure: map[string]float64{
+			"port":     1.0,
+			"protocol": 1.0,
+			"cloud":    0.5,
+		},
+		AgeWeight: 1.0 / 30,
+		MaxAge:    2.0,
+		Now:       time.Now().UTC(),
+	}
+}
+
+// Score returns a numeric priority for the risk. Remediated and deleted risks
+// always score zero. The asset may be nil when it is not known.
+func (s Scorer) Score(risk Risk, asset *Asset, attributes []Attribute) float64 {
+	if risk.Status == "" || risk.Is(Remediated) || risk.Is(Deleted) || risk.Is(MachineDeleted) {
+		return 0
+	}
+
+	base := s.Severity[risk.Severity()]
+	epss := 0.0
+	exposure := 0.0
+	seen := make(map[string]bool)
+	for _, attribute := range attributes {
+		switch strings.ToLower(attribute.Name) {
+		case "cvss":
+			if cvss, err := strconv.ParseFloat(attribute.Value, 64); err == nil && cvss >= 0 && cvss <= 10 {
+				base = cvss
+			}
+			continue
+		case "epss":
+			if p, err := strconv.ParseFloat(attribute.Value, 64); err == nil && p >= 0 && p <= 1 {
+				epss = p
+			}
+			continue
+		}
+
+		name := strings.ToLower(attribute.Name)
+		if _, ok := s.Exposure[name]; !ok {
+			// protocol attributes are named after the protocol with the port as value
+			if _, err := strconv.Atoi(attribute.Value); err == nil {
+				name = "protocol"
+			}
+		}
+		if !seen[name] {
+			seen[name] = true
+			exposure += s.Exposure[name]
+		}
+	}
+
+	age := 0.0
+	if created, err := time.Parse(time.RFC3339, risk.Created); err == nil {
+		days := s.Now.Sub(created).Hours() / 24
+		age = math.Min(math.Max(days, 0)*s.AgeWeight, s.MaxAge)
+	}
+
+	priority := 1.0
+	if asset != nil {
+		if p, ok := s.Priority[asset.Status]; ok {
+			priority = p
+		}
+	}
+
+	score := (base + exposure + age) * priority * (1 + epss)
+	if risk.Is(Triage) {
+		score *= 0.8
+	}
+	return math.Round(score*100) / 100
+}
+
+// Rank scores every risk against its asset and the attributes attached to
+// either the risk or the asset, highest score first.
+func (s Scorer) Rank(risks []Risk, assets []Asset, attributes []Attribute) []ScoredRisk {
+	byDNS := make(map[string]*Asset)
+	keys := make(map[string][]string)
+	for i := range assets {
+		asset := &assets[i]
+		keys[asset.DNS] = append(keys[asset.DNS], asset.Key)
+		current, ok := byDNS[asset.DNS]
+		if !ok || asset.Name == asset.DNS || s.Priority[asset.Status] > s.Priority[current.Status] {
+			byDNS[asset.DNS] = asset
+		}
+	}
+
+	bySource := make(map[string][]Attribute)
+	for _, attribute := range attributes {
+		bySource[attribute.Source] = append(bySource[attribute.Source], attribute)
+	}
+
+	scored := make([]ScoredRisk, 0, len(risks))
+	for _, risk := range risks {
+		asset := byDNS[risk.DNS]
+		related := append([]Attribute{}, bySource[risk.Key]...)
+		for _, key := range keys[risk.DNS] {
+			related = append(related, bySource[key]...)
+		}
+		scored = append(scored, ScoredRisk{Risk: risk, Score: s.Score(risk, asset, related)})
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+	return scored
+}
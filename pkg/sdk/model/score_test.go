@@ -0,0 +1,75 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScorerScore(t *testing.T) {
+	now := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	scorer := NewScorer()
+	scorer.Now = now
+
+	asset := NewAsset("acme.com", "acme.com")
+	important := asset
+	important.Status = ActiveHigh
+	frozen := asset
+	frozen.Status = Frozen
+
+	risk := func(status, created string) Risk {
+		r := NewRisk(asset, "sql-injection")
+		r.Status = status
+		r.Created = created
+		return r
+	}
+	attribute := func(name, value string) Attribute {
+		return NewAttribute(name, value, asset.Key)
+	}
+
+	today := now.Format(time.RFC3339)
+	tests := []struct {
+		name       string
+		risk       Risk
+		asset      *Asset
+		attributes []Attribute
+		want       float64
+	}{
+		{"severity", risk(OpenHigh, today), nil, nil, 7.5},
+		{"critical", risk(OpenCritical, today), nil, nil, 9.5},
+		{"remediated", risk(RemediatedHigh, today), nil, nil, 0},
+		{"deleted", risk(Deleted+"H", today), nil, nil, 0},
+		{"no status", risk("", today), nil, nil, 0},
+		{"triage", risk(TriageHigh, today), nil, nil, 6},
+		{"thirty days old", risk(OpenHigh, "2026-01-01T00:00:00Z"), nil, nil, 8.5},
+		{"age is capped", risk(OpenHigh, "2025-01-01T00:00:00Z"), nil, nil, 9.5},
+		{"created in the future", risk(OpenHigh, "2026-03-01T00:00:00Z"), nil, nil, 7.5},
+		{"cvss", risk(OpenHigh, today), nil, []Attribute{attribute("cvss", "9.8")}, 9.8},
+		{"invalid cvss", risk(OpenHigh, today), nil, []Attribute{attribute("cvss", "11")}, 7.5},
+		{"epss", risk(OpenHigh, today), nil, []Attribute{attribute("epss", "0.5")}, 11.25},
+		{"high priority asset", risk(OpenHigh, today), &important, nil, 11.25},
+		{"frozen asset", risk(OpenHigh, today), &frozen, nil, 3.75},
+		{"exposure", risk(OpenHigh, today), nil, []Attribute{attribute("port", "22"), attribute("https", "443")}, 9.5},
+		{"exposure counted once", risk(OpenHigh, today), nil, []Attribute{attribute("port", "22"), attribute("port", "80")}, 8.5},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := scorer.Score(test.risk, test.asset, test.attributes); got != test.want {
+				t.Errorf("Score() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScorerRank(t *testing.T) {
+	scorer := NewScorer()
+	asset := NewAsset("acme.com", "acme.com")
+	low := NewRisk(asset, "banner")
+	low.Status = OpenLow
+	high := NewRisk(asset, "sql-injection")
+	high.Status = OpenHigh
+
+	ranked := scorer.Rank([]Risk{low, high}, []Asset{asset}, nil)
+	if len(ranked) != 2 || ranked[0].Key != high.Key || ranked[1].Key != low.Key {
+		t.Fatalf("Rank() = %v, want %s first", ranked, high.Name)
+	}
+}
@@ -3,7 +3,9 @@ package model
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 )
 
 func GetRiskFromKey(key string) (*Risk, error) {
@@ -61,3 +63,18 @@ func FilterFilesByName(files []File, filter string) []File {
 	}
 	return filtered
 }
+
+// ParseDuration accepts Go durations and whole days or weeks, e.g. 30d or 6w
+func ParseDuration(value string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	if value != "" {
+		if unit, ok := units[value[len(value)-1:]]; ok {
+			n, err := strconv.Atoi(value[:len(value)-1])
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	return time.ParseDuration(value)
+}
@@ -0,0 +1,94 @@
+package sdk
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultConcurrency bounds batch operations when the profile does not set one
+const DefaultConcurrency = 8
+
+// RateLimiter is a token bucket allowing rate requests per second on average,
+// with bursts of up to burst requests. A rate of zero or less does not limit.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	burst = max(burst, 1)
+	return &RateLimiter{rate: rate, burst: burst, tokens: float64(burst), last: time.Now()}
+}
+
+// Wait blocks until a request may be sent or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l.rate <= 0 {
+		return ctx.Err()
+	}
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(float64(l.burst), l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	// the token is taken up front, so later callers queue behind this one
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// SetRateLimit limits the client to rate requests per second with bursts of
+// up to burst requests. A rate of zero or less removes the limit.
+func (c *Chariot) SetRateLimit(rate float64, burst int) {
+	if rate <= 0 {
+		c.Limiter = nil
+		return
+	}
+	c.Limiter = NewRateLimiter(rate, burst)
+}
+
+// ForEach calls fn for every item with at most concurrency calls in flight.
+// Every item is attempted; errs[i] holds the error returned for items[i].
+func ForEach[T any](ctx context.Context, items []T, concurrency int, fn func(context.Context, T) error) []error {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	errs := make([]error, len(items))
+
+	g := errgroup.Group{}
+	g.SetLimit(concurrency)
+	for i, item := range items {
+		g.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				errs[i] = err
+				return nil
+			}
+			errs[i] = fn(ctx, item)
+			return nil
+		})
+	}
+	g.Wait()
+	return errs
+}
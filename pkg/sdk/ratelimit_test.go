@@ -0,0 +1,127 @@
+package sdk_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+)
+
+// waited returns how long a Wait of l blocked
+func waited(t *testing.T, l *sdk.RateLimiter) time.Duration {
+	t.Helper()
+	start := time.Now()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	return time.Since(start)
+}
+
+func TestRateLimiterBurstAndRefill(t *testing.T) {
+	limiter := sdk.NewRateLimiter(20, 3)
+	for i := range 3 {
+		if d := waited(t, limiter); d > 20*time.Millisecond {
+			t.Errorf("request %d of the burst waited %s", i+1, d)
+		}
+	}
+	// the bucket is empty, so the next request waits for a token at 20/s
+	if d := waited(t, limiter); d < 30*time.Millisecond || d > time.Second {
+		t.Errorf("request after the burst waited %s, want about 50ms", d)
+	}
+
+	// two tokens come back after 100ms
+	time.Sleep(110 * time.Millisecond)
+	for i := range 2 {
+		if d := waited(t, limiter); d > 20*time.Millisecond {
+			t.Errorf("refilled request %d waited %s", i+1, d)
+		}
+	}
+	if d := waited(t, limiter); d < 20*time.Millisecond {
+		t.Errorf("request beyond the refill waited only %s", d)
+	}
+
+	// no matter how long the limiter is idle, it never holds more than burst
+	time.Sleep(300 * time.Millisecond)
+	for range 3 {
+		waited(t, limiter)
+	}
+	if d := waited(t, limiter); d < 30*time.Millisecond {
+		t.Errorf("request after an idle burst waited only %s", d)
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	limiter := sdk.NewRateLimiter(0, 1)
+	start := time.Now()
+	for range 1000 {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Errorf("1000 requests without a rate took %s", d)
+	}
+
+	client, _ := newTestClient(t)
+	client.SetRateLimit(1, 1)
+	if client.Limiter == nil {
+		t.Fatal("SetRateLimit(1, 1) installed no limiter")
+	}
+	client.SetRateLimit(0, 1)
+	if client.Limiter != nil {
+		t.Fatal("SetRateLimit(0, 1) kept the limiter")
+	}
+	start = time.Now()
+	for range 20 {
+		if _, err := client.Assets.List(); err != nil {
+			t.Fatalf("List: %v", err)
+		}
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("20 requests without a rate limit took %s", d)
+	}
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+	limiter := sdk.NewRateLimiter(2, 1)
+	waited(t, limiter)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if d := time.Since(start); d > 200*time.Millisecond {
+		t.Errorf("canceled Wait returned after %s", d)
+	}
+
+	// the canceled request gives its token back, so the next one waits for a
+	// single token rather than two
+	if d := waited(t, limiter); d > 700*time.Millisecond {
+		t.Errorf("request after a canceled one waited %s, want about 500ms", d)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.Wait(canceled); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait with a canceled context = %v", err)
+	}
+}
+
+func TestClientRateLimit(t *testing.T) {
+	client, _ := newTestClient(t)
+	client.SetRateLimit(20, 2)
+	start := time.Now()
+	for range 4 {
+		if _, err := client.Assets.List(); err != nil {
+			t.Fatalf("List: %v", err)
+		}
+	}
+	// two requests of the burst, then two more at 20/s
+	if d := time.Since(start); d < 80*time.Millisecond {
+		t.Errorf("4 requests at 20/s with a burst of 2 took only %s", d)
+	}
+}
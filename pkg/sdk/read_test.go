@@ -0,0 +1,73 @@
+package sdk_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/chariottest"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+func newTestClient(t *testing.T) (*sdk.Chariot, *chariottest.Server) {
+	t.Helper()
+	server := chariottest.NewServer()
+	t.Cleanup(server.Close)
+	return server.NewClient(), server
+}
+
+func TestList(t *testing.T) {
+	tests := []struct {
+		name     string
+		pageSize int
+		assets   int
+	}{
+		{"empty", 2, 0},
+		{"single page", 100, 5},
+		{"exact pages", 2, 4},
+		{"partial last page", 2, 5},
+		{"one per page", 1, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, server := newTestClient(t)
+			server.PageSize = test.pageSize
+			var want []string
+			for i := range test.assets {
+				asset := model.NewAsset(fmt.Sprintf("%d.acme.com", i), fmt.Sprintf("%d.acme.com", i))
+				server.Seed("", asset)
+				want = append(want, asset.Key)
+			}
+			// other record types share the pages but are not listed
+			server.Seed("", model.NewRisk(model.NewAsset("acme.com", "acme.com"), "sql-injection"))
+
+			assets, err := client.Assets.List()
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			var got []string
+			for _, asset := range assets {
+				got = append(got, asset.Key)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("List() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestListAccount(t *testing.T) {
+	client, server := newTestClient(t)
+	server.Seed("", model.NewAsset("mine.com", "mine.com"))
+	server.Seed("other@acme.com", model.NewAsset("theirs.com", "theirs.com"))
+
+	client.SetAccount("other@acme.com")
+	assets, err := client.Assets.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(assets) != 1 || assets[0].DNS != "theirs.com" {
+		t.Errorf("List() for other@acme.com = %v", assets)
+	}
+}
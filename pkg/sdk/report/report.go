@@ -0,0 +1,242 @@
+package report
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	textTemplate "text/template"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+//go:embed templates/*
+var templates embed.FS
+
+const (
+	HTML     = "html"
+	Markdown = "markdown"
+)
+
+var severities = []struct {
+	Code string
+	Name string
+}{
+	{"C", "Critical"},
+	{"H", "High"},
+	{"M", "Medium"},
+	{"L", "Low"},
+	{"I", "Info"},
+}
+
+var states = map[string]string{
+	model.Triage:         "Triage",
+	model.Open:           "Open",
+	model.Remediated:     "Remediated",
+	model.Deleted:        "Deleted",
+	model.MachineOpen:    "Machine Open",
+	model.MachineDeleted: "Machine Deleted",
+}
+
+type Options struct {
+	Definitions bool
+	Proofs      bool
+	// ProofLimit caps the number of proof bytes included per risk
+	ProofLimit int
+}
+
+type Report struct {
+	Account     string
+	Generated   string
+	TotalAssets int
+	TotalRisks  int
+	Classes     []Count
+	Severities  []Severity
+}
+
+type Count struct {
+	Name  string
+	Count int
+}
+
+type Severity struct {
+	Code   string
+	Name   string
+	Count  int
+	States []State
+}
+
+type State struct {
+	Name  string
+	Risks []Entry
+}
+
+type Entry struct {
+	model.Risk
+	Definition string
+	Proof      string
+}
+
+func Build(client *sdk.Chariot, options Options) (*Report, error) {
+	assets, err := client.Assets.List()
+	if err != nil {
+		return nil, err
+	}
+	risks, err := client.Risks.List()
+	if err != nil {
+		return nil, err
+	}
+
+	report := New(assets, risks)
+	report.Account = client.Username
+	if client.GetAccount() != "" {
+		report.Account = client.GetAccount()
+	}
+
+	definitions := make(map[string]string)
+	for s := range report.Severities {
+		for t := range report.Severities[s].States {
+			entries := report.Severities[s].States[t].Risks
+			for i := range entries {
+				entry := &entries[i]
+				if options.Definitions {
+					if _, ok := definitions[entry.Name]; !ok {
+						definition, err := client.DownloadDefinition(entry.Risk)
+						if err != nil && !errors.Is(err, sdk.ErrNotFound) {
+							return nil, fmt.Errorf("failed to download definition %s: %v", entry.Name, err)
+						}
+						definitions[entry.Name] = string(definition)
+					}
+					entry.Definition = definitions[entry.Name]
+				}
+				if options.Proofs {
+					proof, err := client.DownloadPoE(entry.Risk)
+					if err != nil && !errors.Is(err, sdk.ErrNotFound) {
+						return nil, fmt.Errorf("failed to download proof of %s: %v", entry.Key, err)
+					}
+					entry.Proof = sdk.Excerpt(proof, options.ProofLimit)
+				}
+			}
+		}
+	}
+	return report, nil
+}
+
+// New summarizes assets by class and groups risks by severity, then by state.
+// Deleted assets and risks are left out.
+func New(assets []model.Asset, risks []model.Risk) *Report {
+	report := &Report{Generated: model.Now()}
+
+	classes := make(map[string]int)
+	for _, asset := range assets {
+		if asset.Is(model.Deleted) {
+			continue
+		}
+		class := asset.Class()
+		if class == "" {
+			class = "other"
+		}
+		classes[class]++
+		report.TotalAssets++
+	}
+	for name, count := range classes {
+		report.Classes = append(report.Classes, Count{Name: name, Count: count})
+	}
+	sort.Slice(report.Classes, func(i, j int) bool {
+		if report.Classes[i].Count != report.Classes[j].Count {
+			return report.Classes[i].Count > report.Classes[j].Count
+		}
+		return report.Classes[i].Name < report.Classes[j].Name
+	})
+
+	grouped := make(map[string]map[string][]Entry)
+	for _, risk := range risks {
+		if risk.Status == "" || risk.Is(model.Deleted) || risk.Is(model.MachineDeleted) {
+			continue
+		}
+		severity := risk.Severity()
+		if grouped[severity] == nil {
+			grouped[severity] = make(map[string][]Entry)
+		}
+		state := StateName(risk.State())
+		grouped[severity][state] = append(grouped[severity][state], Entry{Risk: risk})
+		report.TotalRisks++
+	}
+
+	for _, severity := range severities {
+		group := Severity{Code: severity.Code, Name: severity.Name}
+		for state, entries := range grouped[severity.Code] {
+			sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+			group.States = append(group.States, State{Name: state, Risks: entries})
+			group.Count += len(entries)
+		}
+		sort.Slice(group.States, func(i, j int) bool { return group.States[i].Name < group.States[j].Name })
+		report.Severities = append(report.Severities, group)
+	}
+	return report
+}
+
+// Render writes the report as html or markdown. An override path replaces the
+// embedded template for that format.
+func (r *Report) Render(w io.Writer, format, override string) error {
+	name := "templates/report.html.tmpl"
+	if format == Markdown {
+		name = "templates/report.md.tmpl"
+	} else if format != HTML {
+		return fmt.Errorf("unsupported format %s", format)
+	}
+
+	source, err := templates.ReadFile(name)
+	if override != "" {
+		source, err = os.ReadFile(override)
+	}
+	if err != nil {
+		return err
+	}
+
+	if format == HTML {
+		tmpl, err := template.New(filepath.Base(name)).Funcs(template.FuncMap(Funcs)).Parse(string(source))
+		if err != nil {
+			return err
+		}
+		return tmpl.Execute(w, r)
+	}
+	tmpl, err := textTemplate.New(filepath.Base(name)).Funcs(Funcs).Parse(string(source))
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, r)
+}
+
+// Funcs are available to both the embedded and user supplied templates
+var Funcs = textTemplate.FuncMap{
+	"state":    StateName,
+	"severity": SeverityName,
+	"date": func(timestamp string) string {
+		if len(timestamp) >= 10 {
+			return timestamp[:10]
+		}
+		return timestamp
+	},
+}
+
+func StateName(state string) string {
+	if name, ok := states[state]; ok {
+		return name
+	}
+	return state
+}
+
+func SeverityName(code string) string {
+	for _, severity := range severities {
+		if severity.Code == code {
+			return severity.Name
+		}
+	}
+	return code
+}
@@ -0,0 +1,230 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	// proofs are truncated so a single large proof cannot bloat the log
+	sarifProofLimit = 4096
+)
+
+type SarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SarifRun `json:"runs"`
+}
+
+type SarifRun struct {
+	Tool       SarifTool      `json:"tool"`
+	Results    []SarifResult  `json:"results"`
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+type SarifTool struct {
+	Driver SarifDriver `json:"driver"`
+}
+
+type SarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []SarifRule `json:"rules"`
+}
+
+type SarifRule struct {
+	ID               string         `json:"id"`
+	Name             string         `json:"name"`
+	ShortDescription SarifMessage   `json:"shortDescription"`
+	Help             *SarifHelp     `json:"help,omitempty"`
+	Properties       map[string]any `json:"properties,omitempty"`
+}
+
+type SarifHelp struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+type SarifMessage struct {
+	Text string `json:"text"`
+}
+
+type SarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             SarifMessage      `json:"message"`
+	Locations           []SarifLocation   `json:"locations"`
+	RelatedLocations    []SarifLocation   `json:"relatedLocations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          map[string]any    `json:"properties,omitempty"`
+}
+
+type SarifLocation struct {
+	ID               int                   `json:"id,omitempty"`
+	PhysicalLocation SarifPhysicalLocation `json:"physicalLocation"`
+	Message          *SarifMessage         `json:"message,omitempty"`
+}
+
+type SarifPhysicalLocation struct {
+	ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+}
+
+type SarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type SarifOptions struct {
+	// Definitions adds definitions/<name> markdown as rule help
+	Definitions bool
+	// Proofs adds an excerpt of proofs/<dns>/<name> as a related location
+	Proofs bool
+}
+
+var sarifLevels = map[string]string{
+	"C": "error",
+	"H": "error",
+	"M": "warning",
+	"L": "note",
+	"I": "note",
+}
+
+// sarifSecuritySeverity is the CVSS-like score code-scanning dashboards rank
+// rules by
+var sarifSecuritySeverity = map[string]float64{
+	"C": 9.5,
+	"H": 7.5,
+	"M": 5.0,
+	"L": 2.5,
+	"I": 0.0,
+}
+
+// sarifSeverity is the severity code of a risk, empty for risks without a
+// status
+func sarifSeverity(risk model.Risk) string {
+	if risk.Status == "" {
+		return ""
+	}
+	return risk.Severity()
+}
+
+// NewSarifLog maps risks to a SARIF 2.1.0 log with one rule per risk name and
+// one result per risk.
+func NewSarifLog(risks []model.Risk) *SarifLog {
+	names := make(map[string]string)
+	for _, risk := range risks {
+		severity := sarifSeverity(risk)
+		if current, ok := names[risk.Name]; !ok || sarifSecuritySeverity[severity] > sarifSecuritySeverity[current] {
+			names[risk.Name] = severity
+		}
+	}
+
+	rules := make([]SarifRule, 0, len(names))
+	for name := range names {
+		rules = append(rules, SarifRule{ID: name})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	index := make(map[string]int)
+	for i := range rules {
+		rule := &rules[i]
+		index[rule.ID] = i
+		rule.Name = rule.ID
+		rule.ShortDescription = SarifMessage{Text: rule.ID}
+		rule.Properties = map[string]any{
+			"security-severity": fmt.Sprintf("%.1f", sarifSecuritySeverity[names[rule.ID]]),
+			"tags":              []string{"security"},
+		}
+	}
+
+	results := make([]SarifResult, 0, len(risks))
+	for _, risk := range risks {
+		results = append(results, SarifResult{
+			RuleID:    risk.Name,
+			RuleIndex: index[risk.Name],
+			Level:     sarifLevel(risk),
+			Message:   SarifMessage{Text: fmt.Sprintf("%s identified on %s", risk.Name, risk.DNS)},
+			Locations: []SarifLocation{{
+				PhysicalLocation: SarifPhysicalLocation{ArtifactLocation: SarifArtifactLocation{URI: risk.DNS}},
+			}},
+			PartialFingerprints: map[string]string{"chariotKey": risk.Key},
+			Properties: map[string]any{
+				"status":  risk.Status,
+				"source":  risk.Source,
+				"created": risk.Created,
+				"updated": risk.Updated,
+				"link":    risk.Link(),
+			},
+		})
+	}
+
+	return &SarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []SarifRun{{
+			Tool: SarifTool{Driver: SarifDriver{
+				Name:           "Chariot",
+				InformationURI: "https://preview.chariot.praetorian.com",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+}
+
+func sarifLevel(risk model.Risk) string {
+	if risk.Is(model.Remediated) || risk.Is(model.Deleted) || risk.Is(model.MachineDeleted) {
+		return "none"
+	}
+	if level, ok := sarifLevels[sarifSeverity(risk)]; ok {
+		return level
+	}
+	return "warning"
+}
+
+// SARIF builds a SARIF log for the given risks, optionally enriched with
+// definitions and proofs stored in Chariot. Missing files are skipped.
+func (c *Chariot) SARIF(risks []model.Risk, options SarifOptions) (*SarifLog, error) {
+	log := NewSarifLog(risks)
+	run := &log.Runs[0]
+
+	if options.Definitions {
+		for i := range run.Tool.Driver.Rules {
+			rule := &run.Tool.Driver.Rules[i]
+			definition, err := c.DownloadDefinition(model.Risk{Name: rule.ID})
+			if errors.Is(err, ErrNotFound) || len(definition) == 0 {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to download definition %s: %v", rule.ID, err)
+			}
+			rule.Help = &SarifHelp{Text: string(definition), Markdown: string(definition)}
+		}
+	}
+
+	if options.Proofs {
+		for i, risk := range risks {
+			proof, err := c.DownloadPoE(risk)
+			if errors.Is(err, ErrNotFound) || len(proof) == 0 {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to download proof of %s: %v", risk.Key, err)
+			}
+			run.Results[i].RelatedLocations = []SarifLocation{{
+				ID: 1,
+				PhysicalLocation: SarifPhysicalLocation{
+					ArtifactLocation: SarifArtifactLocation{URI: fmt.Sprintf("proofs/%s/%s", risk.DNS, risk.Name)},
+				},
+				Message: &SarifMessage{Text: Excerpt(proof, sarifProofLimit)},
+			}}
+		}
+	}
+
+	return log, nil
+}
@@ -0,0 +1,329 @@
+// Package sla measures risks against remediation deadlines per severity. The
+// policy can be loaded from YAML, with durations in Go syntax or whole days and
+// weeks:
+//
+//	# the clock starts when a risk is created, or when it is first opened
+//	start: created
+//	deadlines:
+//	  critical: 7d
+//	  high: 30d
+//	  medium: 90d
+//	  low: 180d
+package sla
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/report"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// Created starts the SLA clock when the risk is created
+	Created = "created"
+	// Opened starts the SLA clock when the risk is first opened, so time spent
+	// in triage does not count
+	Opened = "opened"
+)
+
+const Day = 24 * time.Hour
+
+// Severities are the severity codes, most severe first
+var Severities = []string{"C", "H", "M", "L", "I"}
+
+var codes = map[string]string{
+	"critical": "C",
+	"high":     "H",
+	"medium":   "M",
+	"low":      "L",
+	"info":     "I",
+}
+
+type Policy struct {
+	Start string
+	// Deadlines maps severity codes to the time allowed to remediate. Risks
+	// of severities without a deadline are never overdue.
+	Deadlines map[string]time.Duration
+}
+
+// DefaultPolicy gives critical risks 7 days, high 30, medium 90 and low 180,
+// counted from creation
+var DefaultPolicy = Policy{
+	Start: Created,
+	Deadlines: map[string]time.Duration{
+		"C": 7 * Day,
+		"H": 30 * Day,
+		"M": 90 * Day,
+		"L": 180 * Day,
+	},
+}
+
+func LoadPolicy(path string) (Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, err
+	}
+	return ParsePolicy(data)
+}
+
+// ParsePolicy reads a YAML policy. Deadlines are keyed by severity name or
+// code; severities left out keep no deadline.
+func ParsePolicy(data []byte) (Policy, error) {
+	var raw struct {
+		Start     string            `yaml:"start"`
+		Deadlines map[string]string `yaml:"deadlines"`
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&raw); err != nil {
+		return Policy{}, fmt.Errorf("invalid SLA policy: %v", err)
+	}
+
+	policy := Policy{Start: strings.ToLower(raw.Start), Deadlines: make(map[string]time.Duration)}
+	if policy.Start == "" {
+		policy.Start = Created
+	}
+	if policy.Start != Created && policy.Start != Opened {
+		return Policy{}, fmt.Errorf("invalid SLA policy: start must be created or opened")
+	}
+	for key, value := range raw.Deadlines {
+		code, ok := codes[strings.ToLower(key)]
+		if !ok && slices.Contains(Severities, strings.ToUpper(key)) {
+			code, ok = strings.ToUpper(key), true
+		}
+		if !ok {
+			return Policy{}, fmt.Errorf("invalid SLA policy: unknown severity %q", key)
+		}
+		deadline, err := model.ParseDuration(value)
+		if err != nil || deadline <= 0 {
+			return Policy{}, fmt.Errorf("invalid SLA policy: invalid deadline %q for %s", value, key)
+		}
+		policy.Deadlines[code] = deadline
+	}
+	return policy, nil
+}
+
+// Measurement is how one risk fares against the policy. Times are zero when
+// the event did not happen.
+type Measurement struct {
+	Risk    model.Risk
+	Created time.Time
+	// Opened is the first transition to open, or the creation time of risks
+	// created open
+	Opened time.Time
+	// Remediated is the last transition to remediated, for remediated risks
+	Remediated time.Time
+	// TimeToOpen is from creation until opened
+	TimeToOpen time.Duration
+	// TimeOpen is from opened until remediated, or until now for open risks
+	TimeOpen time.Duration
+	// TimeToRemediate is from creation until remediated
+	TimeToRemediate time.Duration
+	// Deadline is zero when the severity has no SLA
+	Deadline time.Duration
+	Due      time.Time
+	// Overdue is set for open risks past their due date
+	Overdue bool
+	// Late is set for remediated risks that were remediated after their due date
+	Late bool
+}
+
+// OverdueBy is how long an overdue risk is past its due date
+func (m Measurement) OverdueBy(now time.Time) time.Duration {
+	if !m.Overdue {
+		return 0
+	}
+	return now.Sub(m.Due)
+}
+
+func parse(timestamp string) time.Time {
+	t, _ := time.Parse(time.RFC3339, timestamp)
+	return t
+}
+
+func isOpen(status string) bool {
+	// the first transition of an imported risk may have no previous status
+	if status == "" {
+		return false
+	}
+	risk := model.Risk{Status: status}
+	state := risk.State()
+	return state == model.Open || state == model.MachineOpen
+}
+
+func isRemediated(status string) bool {
+	return strings.HasPrefix(status, model.Remediated)
+}
+
+// Measure computes the timeline of a risk from its Created time and History
+// transitions
+func Measure(risk model.Risk, policy Policy, now time.Time) Measurement {
+	m := Measurement{Risk: risk, Created: parse(risk.Created)}
+
+	// risks created open, or already remediated, have been open since creation
+	created := risk.Status
+	if len(risk.History) > 0 {
+		created = risk.History[0].From
+	}
+	if isOpen(created) || isRemediated(created) {
+		m.Opened = m.Created
+	}
+	for _, history := range risk.History {
+		if m.Opened.IsZero() && isOpen(history.To) {
+			m.Opened = parse(history.Updated)
+		}
+		if isRemediated(history.To) {
+			m.Remediated = parse(history.Updated)
+		}
+	}
+	if !isRemediated(risk.Status) {
+		m.Remediated = time.Time{}
+	} else if m.Remediated.IsZero() {
+		m.Remediated = parse(risk.Updated)
+	}
+
+	if !m.Opened.IsZero() {
+		m.TimeToOpen = m.Opened.Sub(m.Created)
+		end := now
+		if !m.Remediated.IsZero() {
+			end = m.Remediated
+		}
+		m.TimeOpen = end.Sub(m.Opened)
+	}
+	if !m.Remediated.IsZero() {
+		m.TimeToRemediate = m.Remediated.Sub(m.Created)
+	}
+
+	if risk.Status == "" {
+		return m
+	}
+	start := m.Created
+	if policy.Start == Opened {
+		start = m.Opened
+	}
+	deadline, ok := policy.Deadlines[risk.Severity()]
+	if !ok || start.IsZero() {
+		return m
+	}
+	m.Deadline = deadline
+	m.Due = start.Add(deadline)
+	m.Overdue = isOpen(risk.Status) && now.After(m.Due)
+	m.Late = !m.Remediated.IsZero() && m.Remediated.After(m.Due)
+	return m
+}
+
+// Group aggregates the measurements of one severity or source capability
+type Group struct {
+	Name       string
+	Open       int
+	Overdue    int
+	Remediated int
+	// Late counts remediated risks that missed their due date
+	Late int
+	// Opened counts the risks that were ever opened, MeanTimeToOpen's sample
+	Opened              int
+	MeanTimeToOpen      time.Duration
+	MeanTimeToRemediate time.Duration
+
+	toOpen      time.Duration
+	toRemediate time.Duration
+}
+
+func (g *Group) add(m Measurement) {
+	switch {
+	case isOpen(m.Risk.Status):
+		g.Open++
+		if m.Overdue {
+			g.Overdue++
+		}
+	case !m.Remediated.IsZero():
+		g.Remediated++
+		if m.Late {
+			g.Late++
+		}
+		g.toRemediate += m.TimeToRemediate
+		g.MeanTimeToRemediate = g.toRemediate / time.Duration(g.Remediated)
+	}
+	if !m.Opened.IsZero() {
+		g.Opened++
+		g.toOpen += m.TimeToOpen
+		g.MeanTimeToOpen = g.toOpen / time.Duration(g.Opened)
+	}
+}
+
+type Report struct {
+	Policy    Policy
+	Generated time.Time
+	// Risks are the measurements of every risk that is not deleted
+	Risks []Measurement
+	// Overdue are the open risks past their due date, most overdue first
+	Overdue []Measurement
+	// Severities are ordered from critical to info
+	Severities []Group
+	// Sources are the source capabilities, in alphabetical order
+	Sources []Group
+}
+
+func Build(client *sdk.Chariot, policy Policy) (*Report, error) {
+	risks, err := client.Risks.List()
+	if err != nil {
+		return nil, err
+	}
+	return Compute(risks, policy, time.Now().UTC()), nil
+}
+
+// Compute measures risks and aggregates them per severity and source
+// capability. Deleted risks are left out.
+func Compute(risks []model.Risk, policy Policy, now time.Time) *Report {
+	r := &Report{Policy: policy, Generated: now}
+	severities := make(map[string]*Group)
+	for _, code := range Severities {
+		severities[code] = &Group{Name: report.SeverityName(code)}
+	}
+	sources := make(map[string]*Group)
+
+	for _, risk := range risks {
+		if risk.Status == "" || risk.Is(model.Deleted) || risk.Is(model.MachineDeleted) {
+			continue
+		}
+		m := Measure(risk, policy, now)
+		r.Risks = append(r.Risks, m)
+		if m.Overdue {
+			r.Overdue = append(r.Overdue, m)
+		}
+		if group, ok := severities[risk.Severity()]; ok {
+			group.add(m)
+		}
+		source := risk.Source
+		if source == "" {
+			source = "unknown"
+		}
+		if _, ok := sources[source]; !ok {
+			sources[source] = &Group{Name: source}
+		}
+		sources[source].add(m)
+	}
+
+	sort.SliceStable(r.Overdue, func(i, j int) bool {
+		if !r.Overdue[i].Due.Equal(r.Overdue[j].Due) {
+			return r.Overdue[i].Due.Before(r.Overdue[j].Due)
+		}
+		return r.Overdue[i].Risk.Key < r.Overdue[j].Risk.Key
+	})
+	for _, code := range Severities {
+		r.Severities = append(r.Severities, *severities[code])
+	}
+	for _, group := range sources {
+		r.Sources = append(r.Sources, *group)
+	}
+	sort.Slice(r.Sources, func(i, j int) bool { return r.Sources[i].Name < r.Sources[j].Name })
+	return r
+}
@@ -0,0 +1,172 @@
+package sla
+
+import (
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+var now = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func day(n int) string {
+	return time.Date(2026, 1, n, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+}
+
+func risk(status string, history ...model.History) model.Risk {
+	r := model.NewRisk(model.NewAsset("acme.com", "acme.com"), "sql-injection")
+	r.Status = status
+	r.Created = day(1)
+	r.Updated = day(1)
+	r.History = history
+	if len(history) > 0 {
+		r.Updated = history[len(history)-1].Updated
+	}
+	return r
+}
+
+func transition(from, to string, updated string) model.History {
+	return model.History{From: from, To: to, Updated: updated}
+}
+
+func TestMeasure(t *testing.T) {
+	opened := DefaultPolicy
+	opened.Start = Opened
+
+	tests := []struct {
+		name            string
+		risk            model.Risk
+		policy          Policy
+		opened          string
+		remediated      string
+		due             string
+		overdue, late   bool
+		timeToRemediate time.Duration
+	}{
+		{
+			name:   "created open",
+			risk:   risk(model.OpenCritical),
+			policy: DefaultPolicy, opened: day(1), due: day(8), overdue: true,
+		},
+		{
+			name:   "triage is never due",
+			risk:   risk(model.TriageInfo),
+			policy: DefaultPolicy,
+		},
+		{
+			name:   "opened after triage",
+			risk:   risk(model.OpenHigh, transition(model.TriageHigh, model.OpenHigh, day(5))),
+			policy: opened, opened: day(5), due: day(5 + 30), overdue: true,
+		},
+		{
+			name: "remediated on time",
+			risk: risk(model.RemediatedHigh,
+				transition(model.TriageHigh, model.OpenHigh, day(2)),
+				transition(model.OpenHigh, model.RemediatedHigh, day(10))),
+			policy: DefaultPolicy, opened: day(2), remediated: day(10), due: day(31), timeToRemediate: 9 * Day,
+		},
+		{
+			name: "remediated late",
+			risk: risk(model.RemediatedCritical,
+				transition(model.OpenCritical, model.RemediatedCritical, day(20))),
+			policy: DefaultPolicy, opened: day(1), remediated: day(20), due: day(8), late: true, timeToRemediate: 19 * Day,
+		},
+		{
+			name: "reopened",
+			risk: risk(model.OpenCritical,
+				transition(model.OpenCritical, model.RemediatedCritical, day(3)),
+				transition(model.RemediatedCritical, model.OpenCritical, day(4))),
+			policy: DefaultPolicy, opened: day(1), due: day(8), overdue: true,
+		},
+		{
+			name:   "empty first transition",
+			risk:   risk(model.OpenHigh, transition("", model.OpenHigh, day(3))),
+			policy: DefaultPolicy, opened: day(3), due: day(31), overdue: true,
+		},
+		{
+			name:   "no status",
+			risk:   risk(""),
+			policy: DefaultPolicy,
+		},
+		{
+			name:   "severity without deadline",
+			risk:   risk(model.OpenInfo),
+			policy: DefaultPolicy, opened: day(1),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := Measure(test.risk, test.policy, now)
+			check := func(field string, got time.Time, want string) {
+				if (want == "" && !got.IsZero()) || (want != "" && !got.Equal(parse(want))) {
+					t.Errorf("%s = %v, want %q", field, got, want)
+				}
+			}
+			check("Opened", m.Opened, test.opened)
+			check("Remediated", m.Remediated, test.remediated)
+			check("Due", m.Due, test.due)
+			if m.Overdue != test.overdue || m.Late != test.late {
+				t.Errorf("Overdue, Late = %v, %v, want %v, %v", m.Overdue, m.Late, test.overdue, test.late)
+			}
+			if m.TimeToRemediate != test.timeToRemediate {
+				t.Errorf("TimeToRemediate = %v, want %v", m.TimeToRemediate, test.timeToRemediate)
+			}
+		})
+	}
+}
+
+func TestCompute(t *testing.T) {
+	deleted := risk(model.OpenHigh)
+	deleted.Key += "-deleted"
+	deleted.Status = model.Deleted + "H"
+	late := risk(model.RemediatedCritical, transition(model.OpenCritical, model.RemediatedCritical, day(20)))
+	late.Key += "-late"
+
+	report := Compute([]model.Risk{risk(model.OpenCritical), late, deleted, risk("")}, DefaultPolicy, now)
+	if len(report.Risks) != 2 || len(report.Overdue) != 1 {
+		t.Fatalf("got %d risks and %d overdue, want 2 and 1", len(report.Risks), len(report.Overdue))
+	}
+	critical := report.Severities[0]
+	if critical.Open != 1 || critical.Overdue != 1 || critical.Remediated != 1 || critical.Late != 1 {
+		t.Errorf("critical = %+v", critical)
+	}
+	if critical.MeanTimeToRemediate != 19*Day {
+		t.Errorf("MeanTimeToRemediate = %v, want %v", critical.MeanTimeToRemediate, 19*Day)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		want  Policy
+		valid bool
+	}{
+		{"names", "start: opened\ndeadlines:\n  critical: 7d\n  high: 2w\n", Policy{Start: Opened, Deadlines: map[string]time.Duration{"C": 7 * Day, "H": 14 * Day}}, true},
+		{"codes", "deadlines:\n  M: 36h\n", Policy{Start: Created, Deadlines: map[string]time.Duration{"M": 36 * time.Hour}}, true},
+		{"invalid start", "start: discovered\n", Policy{}, false},
+		{"unknown severity", "deadlines:\n  urgent: 1d\n", Policy{}, false},
+		{"invalid deadline", "deadlines:\n  high: soon\n", Policy{}, false},
+		{"negative deadline", "deadlines:\n  high: -1h\n", Policy{}, false},
+		{"unknown field", "deadline:\n  high: 1d\n", Policy{}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policy, err := ParsePolicy([]byte(test.data))
+			if (err == nil) != test.valid {
+				t.Fatalf("ParsePolicy() error = %v, want valid %v", err, test.valid)
+			}
+			if !test.valid {
+				return
+			}
+			if policy.Start != test.want.Start || len(policy.Deadlines) != len(test.want.Deadlines) {
+				t.Fatalf("ParsePolicy() = %+v, want %+v", policy, test.want)
+			}
+			for code, deadline := range test.want.Deadlines {
+				if policy.Deadlines[code] != deadline {
+					t.Errorf("deadline %s = %v, want %v", code, policy.Deadlines[code], deadline)
+				}
+			}
+		})
+	}
+}
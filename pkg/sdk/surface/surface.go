@@ -0,0 +1,412 @@
+// Package surface manages seed assets, collaborators and integrations from a
+// declarative YAML file, so account onboarding can be reviewed in git.
+//
+//	assets:
+//	  - dns: acme.com
+//	    priority: comprehensive
+//	collaborators:
+//	  - research@praetorian.com
+//	integrations:
+//	  - name: slack
+//	    value: "#security"
+//	    config:
+//	      token: ${SLACK_TOKEN}
+//
+// Apply records the declared items in a State file next to the surface file.
+// Pruning only removes items recorded there, so assets, collaborators and
+// integrations created in the UI or by other tools are never touched.
+package surface
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Priorities maps the priorities accepted by asset update to asset statuses
+var Priorities = map[string]string{
+	"comprehensive": model.ActiveHigh,
+	"standard":      model.Active,
+	"discover":      model.ActiveLow,
+	"frozen":        model.Frozen,
+}
+
+// the webhook is managed by the webhook commands and never pruned
+const webhook = "hook"
+
+var emailRegexp = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+
+type File struct {
+	Assets        []Asset       `yaml:"assets"`
+	Collaborators []string      `yaml:"collaborators"`
+	Integrations  []Integration `yaml:"integrations"`
+}
+
+type Asset struct {
+	DNS  string `yaml:"dns"`
+	Name string `yaml:"name,omitempty"`
+	// Priority is left unmanaged on existing assets when empty
+	Priority string `yaml:"priority,omitempty"`
+}
+
+type Integration struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value,omitempty"`
+	// Config values may reference environment variables as ${NAME}, so
+	// credentials do not need to be committed
+	Config map[string]string `yaml:"config,omitempty"`
+}
+
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+func Parse(data []byte) (*File, error) {
+	var file File
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid surface file: %v", err)
+	}
+
+	for i, asset := range file.Assets {
+		if asset.DNS == "" {
+			return nil, fmt.Errorf("asset %d: dns is required", i+1)
+		}
+		if asset.Name == "" {
+			file.Assets[i].Name = asset.DNS
+		}
+		if _, ok := Priorities[strings.ToLower(asset.Priority)]; asset.Priority != "" && !ok {
+			return nil, fmt.Errorf("asset %s: invalid priority %q, expected comprehensive, standard, discover or frozen", asset.DNS, asset.Priority)
+		}
+		file.Assets[i].Priority = strings.ToLower(asset.Priority)
+	}
+	for _, email := range file.Collaborators {
+		if !emailRegexp.MatchString(email) {
+			return nil, fmt.Errorf("collaborator %q: invalid email address", email)
+		}
+	}
+	for i, integration := range file.Integrations {
+		if integration.Name == "" {
+			return nil, fmt.Errorf("integration %d: name is required", i+1)
+		}
+		for key, value := range integration.Config {
+			file.Integrations[i].Config[key] = os.ExpandEnv(value)
+		}
+	}
+	return &file, nil
+}
+
+// State lists the assets, collaborators and integrations a surface file
+// manages, by asset key, email and integration ID
+type State struct {
+	Assets        []string `json:"assets"`
+	Collaborators []string `json:"collaborators"`
+	Integrations  []string `json:"integrations"`
+}
+
+// StatePath is the default state file of a surface file, e.g. surface.yaml
+// keeps its state in surface.state.json
+func StatePath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".state.json"
+}
+
+// LoadState reads a state file. A missing file is an empty state, which
+// manages nothing.
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid state file %s: %v", path, err)
+	}
+	return &state, nil
+}
+
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// NewState records the items declared by file and keeps the previously
+// recorded items, except those the applied plan deleted
+func NewState(file *File, previous *State, plan *Plan, errs []error) *State {
+	state := &State{}
+	if previous != nil {
+		state.Assets = append(state.Assets, previous.Assets...)
+		state.Collaborators = append(state.Collaborators, previous.Collaborators...)
+		state.Integrations = append(state.Integrations, previous.Integrations...)
+	}
+	for _, spec := range file.Assets {
+		state.Assets = append(state.Assets, model.NewAsset(spec.DNS, spec.Name).Key)
+	}
+	state.Collaborators = append(state.Collaborators, file.Collaborators...)
+	for _, spec := range file.Integrations {
+		state.Integrations = append(state.Integrations, integrationID(spec.Name, spec.Value))
+	}
+
+	deleted := make(map[string]bool)
+	for i, change := range plan.Changes {
+		if change.Action == Delete && i < len(errs) && errs[i] == nil {
+			deleted[change.Kind+"/"+change.ID] = true
+		}
+	}
+	for kind, ids := range map[string]*[]string{"asset": &state.Assets, "collaborator": &state.Collaborators, "integration": &state.Integrations} {
+		*ids = slices.DeleteFunc(*ids, func(id string) bool { return deleted[kind+"/"+id] })
+		sort.Strings(*ids)
+		*ids = slices.Compact(*ids)
+	}
+	return state
+}
+
+type Action string
+
+const (
+	Create Action = "+"
+	Update Action = "~"
+	Delete Action = "-"
+)
+
+type Change struct {
+	Action Action
+	Kind   string
+	ID     string
+	Detail string
+	apply  func(*sdk.Chariot) error
+}
+
+func (c Change) String() string {
+	if c.Detail == "" {
+		return fmt.Sprintf("%s %s %s", c.Action, c.Kind, c.ID)
+	}
+	return fmt.Sprintf("%s %s %s (%s)", c.Action, c.Kind, c.ID, c.Detail)
+}
+
+type Plan struct {
+	Changes []Change
+}
+
+// Summary counts the changes, e.g. "Plan: 2 to add, 1 to change, 0 to remove."
+func (p *Plan) Summary() string {
+	counts := map[Action]int{}
+	for _, change := range p.Changes {
+		counts[change.Action]++
+	}
+	return fmt.Sprintf("Plan: %d to add, %d to change, %d to remove.", counts[Create], counts[Update], counts[Delete])
+}
+
+// Apply runs every change and returns one error per change. Changes are
+// computed from the current state, so applying the same file twice is a no-op.
+func (p *Plan) Apply(client *sdk.Chariot) []error {
+	return sdk.ForEach(context.Background(), p.Changes, client.Concurrency, func(_ context.Context, change Change) error {
+		return change.apply(client)
+	})
+}
+
+// Build lists the account and plans the changes needed to match the file. With
+// prune, the assets, collaborators and integrations recorded in state that are
+// no longer declared are removed from the account.
+func Build(client *sdk.Chariot, file *File, state *State, prune bool) (*Plan, error) {
+	assets, err := client.Assets.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assets: %v", err)
+	}
+	accounts, err := client.Accounts.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %v", err)
+	}
+	return NewPlan(file, state, client.Username, assets, accounts, prune), nil
+}
+
+func NewPlan(file *File, state *State, username string, assets []model.Asset, accounts []model.Account, prune bool) *Plan {
+	if state == nil || !prune {
+		state = &State{}
+	}
+	plan := &Plan{}
+	plan.assets(file, assets, state.Assets)
+	plan.collaborators(file, username, accounts, state.Collaborators)
+	plan.integrations(file, username, accounts, state.Integrations)
+	sort.SliceStable(plan.Changes, func(i, j int) bool {
+		if plan.Changes[i].Kind != plan.Changes[j].Kind {
+			return plan.Changes[i].Kind < plan.Changes[j].Kind
+		}
+		return plan.Changes[i].ID < plan.Changes[j].ID
+	})
+	return plan
+}
+
+func priority(status string) string {
+	if strings.HasPrefix(status, model.Frozen) {
+		return "frozen"
+	}
+	for name, value := range Priorities {
+		if status == value {
+			return name
+		}
+	}
+	return status
+}
+
+func (p *Plan) assets(file *File, assets []model.Asset, managed []string) {
+	existing := make(map[string]model.Asset)
+	for _, asset := range assets {
+		existing[asset.Key] = asset
+	}
+
+	declared := make(map[string]bool)
+	for _, spec := range file.Assets {
+		asset := model.NewAsset(spec.DNS, spec.Name)
+		asset.Source = model.Provided
+		declared[asset.Key] = true
+
+		current, ok := existing[asset.Key]
+		if !ok || current.Is(model.Deleted) {
+			want := spec.Priority
+			if want == "" {
+				want = "standard"
+			}
+			asset.Status = Priorities[want]
+			p.Changes = append(p.Changes, Change{Action: Create, Kind: "asset", ID: asset.Key, Detail: want, apply: func(client *sdk.Chariot) error {
+				if err := client.Assets.Add(asset); err != nil {
+					return err
+				}
+				if asset.Status == model.Active {
+					return nil
+				}
+				return client.Assets.Update(asset)
+			}})
+			continue
+		}
+
+		if spec.Priority == "" || priority(current.Status) == spec.Priority {
+			continue
+		}
+		current.Status = Priorities[spec.Priority]
+		detail := fmt.Sprintf("%s -> %s", priority(existing[asset.Key].Status), spec.Priority)
+		p.Changes = append(p.Changes, Change{Action: Update, Kind: "asset", ID: asset.Key, Detail: detail, apply: func(client *sdk.Chariot) error {
+			return client.Assets.Update(current)
+		}})
+	}
+
+	for _, asset := range assets {
+		if declared[asset.Key] || !slices.Contains(managed, asset.Key) || asset.Is(model.Deleted) {
+			continue
+		}
+		p.Changes = append(p.Changes, Change{Action: Delete, Kind: "asset", ID: asset.Key, apply: func(client *sdk.Chariot) error {
+			return client.Assets.Delete(asset)
+		}})
+	}
+}
+
+func (p *Plan) collaborators(file *File, username string, accounts []model.Account, managed []string) {
+	existing := make(map[string]model.Account)
+	for _, account := range accounts {
+		if account.Name == username && emailRegexp.MatchString(account.Member) {
+			existing[account.Member] = account
+		}
+	}
+
+	declared := make(map[string]bool)
+	for _, email := range file.Collaborators {
+		declared[email] = true
+		if _, ok := existing[email]; ok {
+			continue
+		}
+		account := model.NewAccount(username, email, "", nil)
+		p.Changes = append(p.Changes, Change{Action: Create, Kind: "collaborator", ID: email, apply: func(client *sdk.Chariot) error {
+			return client.Accounts.Add(account)
+		}})
+	}
+
+	for email, account := range existing {
+		if declared[email] || !slices.Contains(managed, email) {
+			continue
+		}
+		p.Changes = append(p.Changes, Change{Action: Delete, Kind: "collaborator", ID: email, apply: func(client *sdk.Chariot) error {
+			return client.Accounts.Delete(account)
+		}})
+	}
+}
+
+func integrationID(name, value string) string {
+	if value == "" {
+		return name
+	}
+	return name + ":" + value
+}
+
+func (p *Plan) integrations(file *File, username string, accounts []model.Account, managed []string) {
+	existing := make(map[string]model.Account)
+	for _, account := range accounts {
+		if account.Name != username || account.Member == "settings" || account.Member == webhook || emailRegexp.MatchString(account.Member) {
+			continue
+		}
+		existing[integrationID(account.Member, account.Value)] = account
+	}
+
+	declared := make(map[string]bool)
+	for _, spec := range file.Integrations {
+		id := integrationID(spec.Name, spec.Value)
+		declared[id] = true
+		config := make(map[string]interface{})
+		for key, value := range spec.Config {
+			config[key] = value
+		}
+		link := func(client *sdk.Chariot) error {
+			return client.Accounts.Link(spec.Name, spec.Value, config)
+		}
+
+		current, ok := existing[id]
+		switch {
+		case !ok:
+			p.Changes = append(p.Changes, Change{Action: Create, Kind: "integration", ID: id, apply: link})
+		case !configMatches(current.Config, spec.Config):
+			// relinking replaces the stored configuration
+			p.Changes = append(p.Changes, Change{Action: Update, Kind: "integration", ID: id, Detail: "config", apply: link})
+		}
+	}
+
+	for id, account := range existing {
+		if declared[id] || !slices.Contains(managed, id) {
+			continue
+		}
+		p.Changes = append(p.Changes, Change{Action: Delete, Kind: "integration", ID: id, apply: func(client *sdk.Chariot) error {
+			return client.Accounts.Unlink(account.Member, account.Value)
+		}})
+	}
+}
+
+// configMatches ignores keys the server adds that the file does not declare
+func configMatches(current, desired map[string]string) bool {
+	subset := make(map[string]string)
+	for key := range desired {
+		if value, ok := current[key]; ok {
+			subset[key] = value
+		}
+	}
+	return maps.Equal(subset, desired)
+}
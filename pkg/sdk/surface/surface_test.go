@@ -0,0 +1,164 @@
+package surface
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+const username = "owner@acme.com"
+
+func changes(plan *Plan) []string {
+	var result []string
+	for _, change := range plan.Changes {
+		result = append(result, change.String())
+	}
+	return result
+}
+
+func TestNewPlan(t *testing.T) {
+	file := &File{
+		Assets:        []Asset{{DNS: "acme.com", Name: "acme.com", Priority: "comprehensive"}, {DNS: "new.acme.com", Name: "new.acme.com"}},
+		Collaborators: []string{"alice@acme.com"},
+		Integrations:  []Integration{{Name: "slack", Config: map[string]string{"url": "https://hooks.slack.com/b"}}},
+	}
+
+	managed := model.NewAsset("old.acme.com", "old.acme.com")
+	manual := model.NewAsset("manual.acme.com", "manual.acme.com")
+	assets := []model.Asset{model.NewAsset("acme.com", "acme.com"), managed, manual}
+	accounts := []model.Account{
+		model.NewAccount(username, "bob@acme.com", "", nil),
+		model.NewAccount(username, "carol@acme.com", "", nil),
+		model.NewAccount(username, "slack", "", map[string]string{"url": "https://hooks.slack.com/a"}),
+		model.NewAccount(username, "jira", "", nil),
+		model.NewAccount(username, "hook", "", map[string]string{"pin": "1234"}),
+	}
+	state := &State{
+		Assets:        []string{managed.Key},
+		Collaborators: []string{"bob@acme.com"},
+		Integrations:  []string{"jira", "hook"},
+	}
+
+	tests := []struct {
+		name  string
+		state *State
+		prune bool
+		want  []string
+	}{
+		{"without prune", state, false, []string{
+			"~ asset #asset#acme.com#acme.com (standard -> comprehensive)",
+			"+ asset #asset#new.acme.com#new.acme.com (standard)",
+			"+ collaborator alice@acme.com",
+			"~ integration slack (config)",
+		}},
+		{"prune", state, true, []string{
+			"~ asset #asset#acme.com#acme.com (standard -> comprehensive)",
+			"+ asset #asset#new.acme.com#new.acme.com (standard)",
+			"- asset #asset#old.acme.com#old.acme.com",
+			"+ collaborator alice@acme.com",
+			"- collaborator bob@acme.com",
+			"- integration jira",
+			"~ integration slack (config)",
+		}},
+		{"prune without state", nil, true, []string{
+			"~ asset #asset#acme.com#acme.com (standard -> comprehensive)",
+			"+ asset #asset#new.acme.com#new.acme.com (standard)",
+			"+ collaborator alice@acme.com",
+			"~ integration slack (config)",
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plan := NewPlan(file, test.state, username, assets, accounts, test.prune)
+			if got := changes(plan); !slices.Equal(got, test.want) {
+				t.Errorf("changes =\n%q\nwant\n%q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewPlanUpToDate(t *testing.T) {
+	file := &File{
+		Assets:        []Asset{{DNS: "acme.com", Name: "acme.com"}},
+		Collaborators: []string{"alice@acme.com"},
+		Integrations:  []Integration{{Name: "slack", Config: map[string]string{"url": "https://hooks.slack.com/a"}}},
+	}
+	assets := []model.Asset{model.NewAsset("acme.com", "acme.com")}
+	accounts := []model.Account{
+		model.NewAccount(username, "alice@acme.com", "", nil),
+		model.NewAccount(username, "slack", "", map[string]string{"url": "https://hooks.slack.com/a", "added": "by the server"}),
+	}
+	state := NewState(file, nil, &Plan{}, nil)
+
+	if plan := NewPlan(file, state, username, assets, accounts, true); len(plan.Changes) != 0 {
+		t.Errorf("changes = %q, want none", changes(plan))
+	}
+}
+
+func TestState(t *testing.T) {
+	file := &File{
+		Assets:        []Asset{{DNS: "acme.com", Name: "acme.com"}},
+		Collaborators: []string{"alice@acme.com"},
+	}
+	previous := &State{Collaborators: []string{"bob@acme.com", "carol@acme.com"}}
+	plan := &Plan{Changes: []Change{
+		{Action: Delete, Kind: "collaborator", ID: "bob@acme.com"},
+		{Action: Delete, Kind: "collaborator", ID: "carol@acme.com"},
+	}}
+	// carol could not be removed, so she is still managed
+	state := NewState(file, previous, plan, []error{nil, errors.New("forbidden")})
+
+	if want := []string{"#asset#acme.com#acme.com"}; !slices.Equal(state.Assets, want) {
+		t.Errorf("Assets = %q, want %q", state.Assets, want)
+	}
+	if want := []string{"alice@acme.com", "carol@acme.com"}; !slices.Equal(state.Collaborators, want) {
+		t.Errorf("Collaborators = %q, want %q", state.Collaborators, want)
+	}
+
+	path := StatePath(filepath.Join(t.TempDir(), "surface.yaml"))
+	if filepath.Base(path) != "surface.state.json" {
+		t.Errorf("StatePath() = %s", path)
+	}
+	if empty, err := LoadState(path); err != nil || len(empty.Assets)+len(empty.Collaborators)+len(empty.Integrations) != 0 {
+		t.Fatalf("LoadState of a missing file = %v, %v", empty, err)
+	}
+	if err := state.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	if !slices.Equal(loaded.Assets, state.Assets) || !slices.Equal(loaded.Collaborators, state.Collaborators) {
+		t.Errorf("LoadState() = %v, want %v", loaded, state)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		valid bool
+	}{
+		{"valid", "assets:\n  - dns: acme.com\n    priority: Frozen\ncollaborators:\n  - alice@acme.com\n", true},
+		{"missing dns", "assets:\n  - name: acme.com\n", false},
+		{"invalid priority", "assets:\n  - dns: acme.com\n    priority: urgent\n", false},
+		{"invalid email", "collaborators:\n  - alice\n", false},
+		{"unknown field", "asset:\n  - dns: acme.com\n", false},
+		{"missing integration name", "integrations:\n  - value: x\n", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file, err := Parse([]byte(test.data))
+			if (err == nil) != test.valid {
+				t.Fatalf("Parse() error = %v, want valid %v", err, test.valid)
+			}
+			if test.valid && (file.Assets[0].Name != "acme.com" || file.Assets[0].Priority != "frozen") {
+				t.Errorf("Parse() = %+v", file.Assets[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	SyncUp   = "up"
+	SyncDown = "down"
+
+	Upload       = "upload"
+	Download     = "download"
+	DeleteRemote = "delete-remote"
+	DeleteLocal  = "delete-local"
+	// Skip marks remote files that cannot be synced, with the reason why
+	Skip = "skip"
+)
+
+type SyncAction struct {
+	Op     string
+	Local  string
+	Remote string
+	// Modified is when the source copy last changed
+	Modified time.Time
+	Reason   string
+}
+
+func (a SyncAction) String() string {
+	switch a.Op {
+	case Upload:
+		return fmt.Sprintf("upload %s -> %s", a.Local, a.Remote)
+	case Download:
+		return fmt.Sprintf("download %s -> %s", a.Remote, a.Local)
+	case DeleteRemote:
+		return fmt.Sprintf("delete %s", a.Remote)
+	case Skip:
+		return fmt.Sprintf("skip %s: %s", a.Remote, a.Reason)
+	default:
+		return fmt.Sprintf("delete %s", a.Local)
+	}
+}
+
+// PlanSync compares the files under a local directory against the files
+// stored under a remote prefix and returns the transfers needed to bring the
+// destination up to date. A file is transferred when it is missing from the
+// destination or its source copy was modified more recently. With mirror set,
+// destination files missing from the source are deleted. Remote names that
+// would resolve outside the local directory are returned as Skip actions and
+// never transferred or deleted.
+func (s *FileService) PlanSync(local, prefix, direction string, mirror bool) ([]SyncAction, error) {
+	if direction != SyncUp && direction != SyncDown {
+		return nil, fmt.Errorf("invalid sync direction %s", direction)
+	}
+
+	locals := make(map[string]time.Time)
+	err := filepath.WalkDir(local, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == local && direction == SyncDown {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".chariot-download-") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(local, path)
+		if err != nil {
+			return err
+		}
+		locals[prefix+filepath.ToSlash(rel)] = info.ModTime()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	var actions []SyncAction
+	remotes := make(map[string]time.Time)
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name, prefix) || strings.HasSuffix(file.Name, "/") {
+			continue
+		}
+		if !filepath.IsLocal(filepath.FromSlash(strings.TrimPrefix(file.Name, prefix))) {
+			actions = append(actions, SyncAction{Op: Skip, Remote: file.Name, Reason: "name escapes the local directory"})
+			continue
+		}
+		updated, _ := time.Parse(time.RFC3339, file.Updated)
+		remotes[file.Name] = updated
+	}
+
+	path := func(name string) string {
+		return filepath.Join(local, filepath.FromSlash(strings.TrimPrefix(name, prefix)))
+	}
+
+	source, destination, op, remove := locals, remotes, Upload, DeleteRemote
+	if direction == SyncDown {
+		source, destination, op, remove = remotes, locals, Download, DeleteLocal
+	}
+	for name, modified := range source {
+		if existing, ok := destination[name]; ok && !modified.Truncate(time.Second).After(existing) {
+			continue
+		}
+		actions = append(actions, SyncAction{Op: op, Local: path(name), Remote: name, Modified: modified})
+	}
+	if mirror {
+		for name := range destination {
+			if _, ok := source[name]; !ok {
+				actions = append(actions, SyncAction{Op: remove, Local: path(name), Remote: name})
+			}
+		}
+	}
+
+	sort.Slice(actions, func(i, j int) bool { return actions[i].Remote < actions[j].Remote })
+	return actions, nil
+}
+
+// Sync runs the actions with at most concurrency transfers in flight. done is
+// called after each action with its result. The first error is returned once
+// every action has finished.
+func (c *Chariot) Sync(ctx context.Context, actions []SyncAction, concurrency int, done func(SyncAction, error)) error {
+	g := errgroup.Group{}
+	g.SetLimit(max(concurrency, 1))
+
+	for _, action := range actions {
+		g.Go(func() error {
+			err := c.syncOne(ctx, action)
+			if done != nil {
+				done(action, err)
+			}
+			return err
+		})
+	}
+	return g.Wait()
+}
+
+func (c *Chariot) syncOne(ctx context.Context, action SyncAction) error {
+	switch action.Op {
+	case Upload:
+		f, err := os.Open(action.Local)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		return c.UploadReader(ctx, action.Remote, f, info.Size())
+	case Download:
+		if err := os.MkdirAll(filepath.Dir(action.Local), 0755); err != nil {
+			return err
+		}
+		tmp, err := os.CreateTemp(filepath.Dir(action.Local), ".chariot-download-*")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		if err := c.DownloadTo(ctx, action.Remote, tmp); err != nil {
+			return err
+		}
+		if err := tmp.Close(); err != nil {
+			return err
+		}
+		if err := os.Chmod(tmp.Name(), 0644); err != nil {
+			return err
+		}
+		// match the remote timestamp so the next sync sees the copies as equal
+		if !action.Modified.IsZero() {
+			os.Chtimes(tmp.Name(), time.Now(), action.Modified)
+		}
+		return os.Rename(tmp.Name(), action.Local)
+	case DeleteRemote:
+		return c.Files.Delete(model.NewFile(action.Remote))
+	case DeleteLocal:
+		return os.Remove(action.Local)
+	case Skip:
+		return nil
+	default:
+		return fmt.Errorf("unsupported sync operation %s", action.Op)
+	}
+}
@@ -0,0 +1,116 @@
+package sdk_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+func TestPlanSync(t *testing.T) {
+	old := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	remote := func(name string, updated time.Time) model.File {
+		file := model.NewFile(name)
+		file.Updated = updated.Format(time.RFC3339)
+		return file
+	}
+	local := func(t *testing.T, dir, name string, modified time.Time) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		direction string
+		mirror    bool
+		want      []string
+	}{
+		{"up", sdk.SyncUp, false, []string{"upload new", "upload stale", "skip sub/../../escape"}},
+		{"up mirror", sdk.SyncUp, true, []string{"delete-remote missing", "upload new", "upload stale", "skip sub/../../escape"}},
+		{"down", sdk.SyncDown, false, []string{"download current", "download missing", "skip sub/../../escape"}},
+		{"down mirror", sdk.SyncDown, true, []string{"download current", "download missing", "delete-local new", "skip sub/../../escape"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, server := newTestClient(t)
+			dir := t.TempDir()
+			local(t, dir, "new", recent)
+			local(t, dir, "stale", recent)
+			local(t, dir, "current", old)
+			local(t, dir, "same", recent)
+			server.Seed("",
+				remote("proofs/stale", old),
+				remote("proofs/current", recent),
+				remote("proofs/same", recent),
+				remote("proofs/missing", recent),
+				remote("proofs/sub/../../escape", recent),
+				remote("definitions/other", recent),
+			)
+
+			actions, err := client.Files.PlanSync(dir, "proofs/", test.direction, test.mirror)
+			if err != nil {
+				t.Fatalf("PlanSync: %v", err)
+			}
+			var got []string
+			for _, action := range actions {
+				got = append(got, action.Op+" "+action.Remote[len("proofs/"):])
+			}
+			slices.Sort(got)
+			slices.Sort(test.want)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("PlanSync() = %q, want %q", got, test.want)
+			}
+		})
+	}
+
+	client, _ := newTestClient(t)
+	if _, err := client.Files.PlanSync(t.TempDir(), "proofs/", "sideways", false); err == nil {
+		t.Error("PlanSync accepted an invalid direction")
+	}
+}
+
+func TestSync(t *testing.T) {
+	client, server := newTestClient(t)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "report.txt"), []byte("report"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	actions, err := client.Files.PlanSync(dir, "proofs/", sdk.SyncUp, false)
+	if err != nil {
+		t.Fatalf("PlanSync: %v", err)
+	}
+	if err := client.Sync(context.Background(), actions, 2, nil); err != nil {
+		t.Fatalf("Sync up: %v", err)
+	}
+	if stored := server.Store("").Files["proofs/report.txt"]; string(stored.Bytes) != "report" {
+		t.Fatalf("server stored %q, want report", stored.Bytes)
+	}
+
+	down := t.TempDir()
+	actions, err = client.Files.PlanSync(down, "proofs/", sdk.SyncDown, false)
+	if err != nil {
+		t.Fatalf("PlanSync: %v", err)
+	}
+	if err := client.Sync(context.Background(), actions, 2, nil); err != nil {
+		t.Fatalf("Sync down: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(down, "report.txt")); err != nil || string(data) != "report" {
+		t.Fatalf("downloaded %q, %v", data, err)
+	}
+}
@@ -0,0 +1,368 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	redacted = "REDACTED"
+	// bodies are captured up to this size for logs and HAR entries
+	traceBodyLimit = 64 * 1024
+	traceLogLimit  = 4096
+)
+
+// presigned URLs carry temporary credentials in their query string
+var redactedParams = []string{"X-Amz-Signature", "X-Amz-Credential", "X-Amz-Security-Token"}
+
+// presignedParam finds the same credentials in presigned URLs quoted in bodies,
+// headers such as Location, and errors
+var presignedParam = regexp.MustCompile(`(X-Amz-(?:Signature|Credential|Security-Token)=)[^&"'\s\\]+`)
+
+type TraceOptions struct {
+	// Logger receives one record per request, slog.Default() when nil
+	Logger *slog.Logger
+	// Bodies logs request and response bodies, with credentials redacted
+	Bodies bool
+	// HAR records every exchange so it can be written with WriteHAR
+	HAR bool
+}
+
+// Tracer is an http.RoundTripper that logs every request the client sends.
+// The bearer token and keychain password never appear in its output.
+type Tracer struct {
+	TraceOptions
+	Transport http.RoundTripper
+	keychain  *Keychain
+
+	mu      sync.Mutex
+	entries []harEntry
+}
+
+// Trace installs a Tracer on the client's HTTP transport and returns it
+func (c *Chariot) Trace(options TraceOptions) *Tracer {
+	if options.Logger == nil {
+		options.Logger = slog.Default()
+	}
+	tracer := &Tracer{TraceOptions: options, Transport: c.HTTPClient.Transport, keychain: c.Keychain}
+	if tracer.Transport == nil {
+		tracer.Transport = http.DefaultTransport
+	}
+	c.HTTPClient.Transport = tracer
+	return tracer
+}
+
+func (t *Tracer) RoundTrip(req *http.Request) (*http.Response, error) {
+	secrets := []string{t.keychain.password}
+	if token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "); token != "" {
+		secrets = append(secrets, token)
+	}
+
+	var sent *capture
+	if req.Body != nil && req.Body != http.NoBody {
+		sent = &capture{ReadCloser: req.Body}
+		req = req.Clone(req.Context())
+		req.Body = sent
+	}
+
+	start := time.Now()
+	resp, err := t.Transport.RoundTrip(req)
+	latency := time.Since(start)
+
+	exchange := &exchange{tracer: t, req: req, sent: sent, start: start, latency: latency, secrets: secrets, presigned: presigned(req.URL)}
+	if err != nil {
+		exchange.finish(nil, err)
+		return nil, err
+	}
+	// the exchange is logged once the caller has read and closed the body
+	resp.Body = &capture{ReadCloser: resp.Body, done: func(received *capture) {
+		exchange.received = received
+		exchange.finish(resp, nil)
+	}}
+	return resp, nil
+}
+
+type exchange struct {
+	tracer   *Tracer
+	req      *http.Request
+	sent     *capture
+	received *capture
+	start    time.Time
+	latency  time.Duration
+	secrets  []string
+	// presigned exchanges move file content straight to and from S3
+	presigned bool
+}
+
+func presigned(u *url.URL) bool {
+	query := u.Query()
+	for _, param := range redactedParams {
+		if query.Has(param) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *exchange) finish(resp *http.Response, err error) {
+	t := e.tracer
+	attrs := []any{
+		"method", e.req.Method,
+		"url", e.redactURL(e.req.URL),
+		"account", e.req.Header.Get("account"),
+		"latency", e.latency,
+	}
+	level := slog.LevelDebug
+	switch {
+	case err != nil:
+		attrs = append(attrs, "error", e.redact(err.Error()))
+		level = slog.LevelError
+	case resp.StatusCode >= 400:
+		attrs = append(attrs, "status", resp.StatusCode)
+		level = slog.LevelWarn
+	default:
+		attrs = append(attrs, "status", resp.StatusCode)
+	}
+	if t.Bodies {
+		if e.sent != nil {
+			attrs = append(attrs, "request", Excerpt([]byte(e.body(e.sent, e.req.Header)), traceLogLimit))
+		}
+		if e.received != nil {
+			attrs = append(attrs, "response", Excerpt([]byte(e.body(e.received, resp.Header)), traceLogLimit))
+		}
+	}
+	t.Logger.Log(context.Background(), level, "chariot api", attrs...)
+
+	if t.HAR {
+		t.mu.Lock()
+		t.entries = append(t.entries, e.har(resp))
+		t.mu.Unlock()
+	}
+}
+
+func (e *exchange) redact(s string) string {
+	for _, secret := range e.secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, redacted)
+		}
+	}
+	return presignedParam.ReplaceAllString(s, "${1}"+redacted)
+}
+
+func (e *exchange) query(u *url.URL) url.Values {
+	query := u.Query()
+	for _, param := range redactedParams {
+		if query.Has(param) {
+			query.Set(param, redacted)
+		}
+	}
+	return query
+}
+
+func (e *exchange) redactURL(u *url.URL) string {
+	redactedURL := *u
+	redactedURL.RawQuery = e.query(u).Encode()
+	return e.redact(redactedURL.String())
+}
+
+func (e *exchange) headers(header http.Header) []harNameValue {
+	values := []harNameValue{}
+	for name, list := range header {
+		for _, value := range list {
+			if strings.EqualFold(name, "Authorization") {
+				value = "Bearer " + redacted
+			}
+			values = append(values, harNameValue{Name: name, Value: e.redact(value)})
+		}
+	}
+	return values
+}
+
+// body returns the captured text, or a placeholder for binary content and
+// the file content of presigned transfers
+func (e *exchange) body(c *capture, header http.Header) string {
+	if e.presigned {
+		return "[presigned transfer body omitted]"
+	}
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	textual := mediaType == "" || strings.HasPrefix(mediaType, "text/") || strings.Contains(mediaType, "json") || strings.Contains(mediaType, "xml")
+	if !textual {
+		return "[" + mediaType + " body omitted]"
+	}
+	text := e.redact(c.buf.String())
+	if c.size > int64(c.buf.Len()) {
+		text += "\n[truncated]"
+	}
+	return text
+}
+
+// capture copies the first traceBodyLimit bytes read through it
+type capture struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	size int64
+	done func(*capture)
+	once sync.Once
+}
+
+func (c *capture) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	if n > 0 {
+		c.size += int64(n)
+		if room := traceBodyLimit - c.buf.Len(); room > 0 {
+			c.buf.Write(p[:min(n, room)])
+		}
+	}
+	return n, err
+}
+
+func (c *capture) Close() error {
+	err := c.ReadCloser.Close()
+	if c.done != nil {
+		c.once.Do(func() { c.done(c) })
+	}
+	return err
+}
+
+// WriteHAR writes every recorded exchange as a HAR 1.2 document
+func (t *Tracer) WriteHAR(w io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	har := harFile{Log: harLog{
+		Version: "1.2",
+		Creator: harNameVersion{Name: "chariot-client", Version: "1"},
+		Entries: t.entries,
+	}}
+	if har.Log.Entries == nil {
+		har.Log.Entries = []harEntry{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(har)
+}
+
+func (e *exchange) har(resp *http.Response) harEntry {
+	entry := harEntry{
+		StartedDateTime: e.start.UTC().Format(time.RFC3339Nano),
+		Time:            float64(e.latency.Microseconds()) / 1000,
+		Request: harRequest{
+			Method:      e.req.Method,
+			URL:         e.redactURL(e.req.URL),
+			HTTPVersion: e.req.Proto,
+			Headers:     e.headers(e.req.Header),
+			QueryString: []harNameValue{},
+			Cookies:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Response: harResponse{
+			Headers:     []harNameValue{},
+			Cookies:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Cache:   struct{}{},
+		Timings: harTimings{Send: 0, Wait: float64(e.latency.Microseconds()) / 1000, Receive: 0},
+	}
+	for name, values := range e.query(e.req.URL) {
+		for _, value := range values {
+			entry.Request.QueryString = append(entry.Request.QueryString, harNameValue{Name: name, Value: e.redact(value)})
+		}
+	}
+	if e.sent != nil {
+		entry.Request.BodySize = e.sent.size
+		entry.Request.PostData = &harContent{
+			MimeType: e.req.Header.Get("Content-Type"),
+			Text:     e.body(e.sent, e.req.Header),
+		}
+	}
+	if resp != nil {
+		entry.Response.Status = resp.StatusCode
+		entry.Response.StatusText = http.StatusText(resp.StatusCode)
+		entry.Response.HTTPVersion = resp.Proto
+		entry.Response.Headers = e.headers(resp.Header)
+		entry.Response.Content = harContent{MimeType: resp.Header.Get("Content-Type")}
+		if e.received != nil {
+			entry.Response.BodySize = e.received.size
+			entry.Response.Content.Size = e.received.size
+			entry.Response.Content.Text = e.body(e.received, resp.Header)
+		}
+	}
+	return entry
+}
+
+type harFile struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string         `json:"version"`
+	Creator harNameVersion `json:"creator"`
+	Entries []harEntry     `json:"entries"`
+}
+
+type harNameVersion struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	Cookies     []harNameValue `json:"cookies"`
+	PostData    *harContent    `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	Cookies     []harNameValue `json:"cookies"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
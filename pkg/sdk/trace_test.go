@@ -0,0 +1,57 @@
+package sdk_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/chariottest"
+)
+
+func TestTraceRedactsCredentials(t *testing.T) {
+	server := chariottest.NewServer()
+	defer server.Close()
+	const password = "keychain-password"
+	client := newProfileClient(t, server, "password = "+password+"\n")
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	tracer := client.Trace(sdk.TraceOptions{Logger: logger, Bodies: true, HAR: true})
+
+	proof := "proof of exploit"
+	if err := client.UploadReader(context.Background(), "proofs/acme.com/sql-injection", strings.NewReader(proof), int64(len(proof))); err != nil {
+		t.Fatalf("UploadReader: %v", err)
+	}
+	if got, err := client.Download("proofs/acme.com/sql-injection"); err != nil || string(got) != proof {
+		t.Fatalf("Download() = %q, %v", got, err)
+	}
+	if _, err := client.Download("proofs/missing"); !errors.Is(err, sdk.ErrNotFound) {
+		t.Fatalf("Download of a missing file: %v", err)
+	}
+	if _, err := client.Assets.List(); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	var har bytes.Buffer
+	if err := tracer.WriteHAR(&har); err != nil {
+		t.Fatalf("WriteHAR: %v", err)
+	}
+	for name, output := range map[string]string{"log": logs.String(), "HAR": har.String()} {
+		for _, secret := range []string{server.Token, password, chariottest.Signature, proof} {
+			if strings.Contains(output, secret) {
+				t.Errorf("%s output contains %q:\n%s", name, secret, output)
+			}
+		}
+		if !strings.Contains(output, "presigned transfer body omitted") {
+			t.Errorf("%s output does not mention the omitted transfer bodies:\n%s", name, output)
+		}
+	}
+	// the exchanges themselves are still traced
+	if !strings.Contains(logs.String(), "X-Amz-Signature=REDACTED") {
+		t.Errorf("log output does not show the presigned requests:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,100 @@
+package sdk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+type TransferOption func(*transfer)
+
+type transfer struct {
+	progress    func(done, total int64)
+	checksum    string
+	contentType string
+	// raw transfers bytes as stored, without encrypting or decrypting them
+	raw bool
+	// inline is an in-memory upload payload, also sent with the presign request
+	inline []byte
+}
+
+// WithProgress calls fn as bytes are transferred. total is -1 when unknown.
+func WithProgress(fn func(done, total int64)) TransferOption {
+	return func(t *transfer) {
+		t.progress = fn
+	}
+}
+
+// WithSHA256 fails the transfer when the SHA-256 of the transferred bytes does
+// not match the given hex digest. Uploads are checked before they start.
+func WithSHA256(digest string) TransferOption {
+	return func(t *transfer) {
+		t.checksum = strings.ToLower(digest)
+	}
+}
+
+// WithContentType overrides content type detection on upload
+func WithContentType(contentType string) TransferOption {
+	return func(t *transfer) {
+		t.contentType = contentType
+	}
+}
+
+func raw() TransferOption {
+	return func(t *transfer) {
+		t.raw = true
+	}
+}
+
+func inline(data []byte) TransferOption {
+	return func(t *transfer) {
+		t.inline = data
+	}
+}
+
+func newTransfer(options []TransferOption) *transfer {
+	t := &transfer{}
+	for _, option := range options {
+		option(t)
+	}
+	return t
+}
+
+// meter counts and hashes the bytes read through it
+type meter struct {
+	reader   io.Reader
+	hash     hash.Hash
+	done     int64
+	total    int64
+	progress func(done, total int64)
+}
+
+func (t *transfer) meter(r io.Reader, total int64) *meter {
+	return &meter{reader: r, hash: sha256.New(), total: total, progress: t.progress}
+}
+
+func (m *meter) Read(p []byte) (int, error) {
+	n, err := m.reader.Read(p)
+	if n > 0 {
+		m.hash.Write(p[:n])
+		m.done += int64(n)
+		if m.progress != nil {
+			m.progress(m.done, m.total)
+		}
+	}
+	return n, err
+}
+
+func (m *meter) Sum() string {
+	return hex.EncodeToString(m.hash.Sum(nil))
+}
+
+func (t *transfer) verify(m *meter) error {
+	if t.checksum != "" && t.checksum != m.Sum() {
+		return fmt.Errorf("sha256 mismatch, expected %s got %s", t.checksum, m.Sum())
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package sdk_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+)
+
+func TestUploadDownload(t *testing.T) {
+	sizes := []int{0, 1, 5 << 20}
+	for _, size := range sizes {
+		t.Run(fmt.Sprint(size), func(t *testing.T) {
+			client, server := newTestClient(t)
+			data := bytes.Repeat([]byte{'x'}, size)
+			if err := client.Upload("proofs/acme.com/sql-injection", data); err != nil {
+				t.Fatalf("Upload: %v", err)
+			}
+			if stored := server.Store("").Files["proofs/acme.com/sql-injection"]; !bytes.Equal(stored.Bytes, data) {
+				t.Fatalf("server stored %d bytes, want %d", len(stored.Bytes), size)
+			}
+
+			got, err := client.Download("proofs/acme.com/sql-injection")
+			if err != nil {
+				t.Fatalf("Download: %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("Download() returned %d bytes that do not match", len(got))
+			}
+		})
+	}
+}
+
+func TestDownloadChecksum(t *testing.T) {
+	client, _ := newTestClient(t)
+	data := []byte("proof of exploit")
+	if err := client.Upload("proofs/acme.com/sql-injection", data); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	sum := sha256.Sum256(data)
+
+	tests := []struct {
+		name   string
+		digest string
+		valid  bool
+	}{
+		{"match", hex.EncodeToString(sum[:]), true},
+		{"mismatch", hex.EncodeToString(make([]byte, 32)), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := client.DownloadTo(context.Background(), "proofs/acme.com/sql-injection", &buf, sdk.WithSHA256(test.digest))
+			if (err == nil) != test.valid {
+				t.Fatalf("DownloadTo() error = %v, want valid %v", err, test.valid)
+			}
+		})
+	}
+}
+
+func TestDownloadNotFound(t *testing.T) {
+	client, _ := newTestClient(t)
+	if _, err := client.Download("proofs/missing"); !errors.Is(err, sdk.ErrNotFound) {
+		t.Fatalf("Download() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUploadContentType(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		options []sdk.TransferOption
+		want    string
+	}{
+		{"report.html", "<p>report</p>", nil, "text/html; charset=utf-8"},
+		{"capture.json", "{}", nil, "application/json"},
+		{"proofs/acme.com/sql-injection", "<html><body>dump</body></html>", nil, "text/html; charset=utf-8"},
+		{"proofs/acme.com/binary", "\x00\x01\x02", nil, "application/octet-stream"},
+		{"proofs/acme.com/override", "plain", []sdk.TransferOption{sdk.WithContentType("application/x-pcap")}, "application/x-pcap"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, server := newTestClient(t)
+			err := client.UploadReader(context.Background(), test.name, bytes.NewReader([]byte(test.data)), int64(len(test.data)), test.options...)
+			if err != nil {
+				t.Fatalf("UploadReader: %v", err)
+			}
+			if got := server.Store("").ContentTypes[test.name]; got != test.want {
+				t.Errorf("content type = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
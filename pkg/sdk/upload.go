@@ -1,12 +1,22 @@
 package sdk
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"net/url"
+	"os"
+	"path"
+	"strings"
 
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/envelope"
 	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
 )
 
@@ -19,30 +29,157 @@ func (c *Chariot) UploadDefinition(risk model.Risk, definition []byte) error {
 }
 
 func (c *Chariot) Upload(name string, data []byte) error {
-	baseURL, err := url.Parse(c.API + "/file")
+	return c.UploadReader(context.Background(), name, bytes.NewReader(data), int64(len(data)), inline(data))
+}
+
+// UploadReader streams size bytes from r into Chariot file storage. A negative
+// size spools r to a temporary file first, since the upload needs a length.
+// With WithSHA256, the input is hashed before anything is uploaded and the
+// stored file is left untouched on a mismatch; inputs that cannot seek are
+// spooled for this. The content type is taken from WithContentType, the name's
+// extension or the first 512 bytes, in that order. When encryption is enabled
+// the file is sealed with the team key on the way out and stored as
+// application/octet-stream; definitions are never encrypted since the Chariot
+// UI renders them.
+func (c *Chariot) UploadReader(ctx context.Context, name string, r io.Reader, size int64, options ...TransferOption) error {
+	t := newTransfer(options)
+
+	_, seekable := r.(io.ReadSeeker)
+	if size < 0 || (t.checksum != "" && !seekable) {
+		spool, err := os.CreateTemp("", "chariot-upload-*")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(spool.Name())
+		defer spool.Close()
+
+		if size, err = io.Copy(spool, r); err != nil {
+			return err
+		}
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		r = spool
+	}
+
+	if t.checksum != "" {
+		seeker := r.(io.ReadSeeker)
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		hash := sha256.New()
+		if _, err := io.CopyN(hash, seeker, size); err != nil {
+			return err
+		}
+		if sum := hex.EncodeToString(hash.Sum(nil)); sum != t.checksum {
+			return fmt.Errorf("sha256 mismatch, expected %s got %s, nothing was uploaded", t.checksum, sum)
+		}
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return err
+		}
+	}
+
+	buffered := bufio.NewReaderSize(r, 512)
+	contentType := t.contentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(name))
+	}
+	if contentType == "" {
+		head, _ := buffered.Peek(512)
+		contentType = http.DetectContentType(head)
+	}
+
+	m := t.meter(buffered, size)
+	var body io.ReadCloser = io.NopCloser(m)
+	length := size
+	// the presign request carries the payload, as it always has, unless the
+	// payload is sealed or too large to hold in memory
+	presignBody := t.inline
+
+	if c.Encrypt && !t.raw && !strings.HasPrefix(name, "definitions/") {
+		keyring, err := c.Keyring()
+		if err != nil {
+			return err
+		}
+		pr, pw := io.Pipe()
+		defer pr.Close()
+		sealer, err := envelope.NewWriter(pw, keyring[0])
+		if err != nil {
+			return err
+		}
+		go func() {
+			_, err := io.Copy(sealer, m)
+			if err == nil {
+				err = sealer.Close()
+			}
+			pw.CloseWithError(err)
+		}()
+		body, length, presignBody = pr, sealer.SealedSize(size), nil
+		contentType = "application/octet-stream"
+	}
+
+	// the presign request carries the content type so the URL can be signed
+	// for it, and the PUT must then send the same header
+	presigned, err := c.presign(ctx, name, contentType, presignBody)
 	if err != nil {
 		return err
 	}
 
-	baseURL.RawQuery = url.Values{"name": {name}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, "PUT", presigned, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = length
+	req.Header.Set("Content-Type", contentType)
+	if length == 0 {
+		req.Body = http.NoBody
+	}
 
-	resp, err := c.request("PUT", baseURL.String(), data)
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("unexpected status code %d, %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+func (c *Chariot) presign(ctx context.Context, name, contentType string, data []byte) (string, error) {
+	baseURL, err := url.Parse(c.API + "/file")
+	if err != nil {
+		return "", err
+	}
+
+	baseURL.RawQuery = url.Values{"name": {name}, "contentType": {contentType}}.Encode()
+
+	var payload io.Reader
+	if data != nil {
+		payload = bytes.NewReader(data)
+	}
+	resp, err := c.do(ctx, "PUT", baseURL.String(), payload)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %d, %s", resp.StatusCode, body)
+	}
 
 	var presigned struct {
 		URL string `json:"url"`
 	}
-	if err := json.Unmarshal(resp, &presigned); err != nil {
-		return err
-	}
-	req, _ := http.NewRequest("PUT", presigned.URL, bytes.NewReader(data))
-	client := &http.Client{}
-	upResp, _ := client.Do(req)
-	defer upResp.Body.Close()
-	if upResp.StatusCode == http.StatusOK {
-		return nil
+	if err := json.Unmarshal(body, &presigned); err != nil {
+		return "", err
 	}
-	return fmt.Errorf("unexpected status code: %d", upResp.StatusCode)
+	return presigned.URL, nil
 }
@@ -0,0 +1,168 @@
+// Package webhook reports assets, risks and attributes into Chariot through a
+// webhook URL generated with chariot webhook generate. The URL carries its own
+// credentials, so no keychain is needed.
+package webhook
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+// Payload is the JSON object accepted by the hook. DNS and Name identify an
+// asset, which is created when needed. With Finding, a risk of that name is
+// reported on the asset; with Attribute and Value, an attribute is added to it.
+type Payload struct {
+	DNS       string `json:"dns"`
+	Name      string `json:"name"`
+	Finding   string `json:"finding,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Comment   string `json:"comment,omitempty"`
+	Attribute string `json:"attribute,omitempty"`
+	Value     string `json:"value,omitempty"`
+}
+
+func AssetPayload(asset model.Asset) Payload {
+	return Payload{DNS: asset.DNS, Name: asset.Name}
+}
+
+func RiskPayload(risk model.Risk) Payload {
+	return Payload{DNS: risk.DNS, Name: risk.DNS, Finding: risk.Name, Status: risk.Status, Comment: risk.Comment}
+}
+
+// AttributePayload reports an attribute on the asset it was created for
+func AttributePayload(attribute model.Attribute) (Payload, error) {
+	// GetAssetFromKey parses any key, so check that the source is an asset
+	if !strings.HasPrefix(attribute.Source, "#asset#") {
+		return Payload{}, fmt.Errorf("attribute %s does not belong to an asset", attribute.Key)
+	}
+	parent, err := model.GetAssetFromKey(attribute.Source)
+	if err != nil {
+		return Payload{}, fmt.Errorf("attribute %s does not belong to an asset: %v", attribute.Key, err)
+	}
+	return Payload{DNS: parent.DNS, Name: parent.Name, Attribute: attribute.Name, Value: attribute.Value}, nil
+}
+
+func (p *Payload) Validate() error {
+	if p.DNS == "" {
+		return fmt.Errorf("dns is required")
+	}
+	if p.Name == "" {
+		p.Name = p.DNS
+	}
+	if (p.Attribute == "") != (p.Value == "") {
+		return fmt.Errorf("attribute and value must be set together")
+	}
+	if p.Finding != "" && p.Attribute != "" {
+		return fmt.Errorf("a payload reports either a finding or an attribute, not both")
+	}
+	return nil
+}
+
+type Client struct {
+	URL        string
+	HTTPClient *http.Client
+	// Limiter throttles every request when set
+	Limiter *sdk.RateLimiter
+	// Concurrency bounds how many payloads SendAll posts at once
+	Concurrency int
+}
+
+// New returns a client for a hook URL of the form <api>/hook/<user>/<pin>
+func New(hook string) (*Client, error) {
+	parsed, err := url.Parse(hook)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook URL: %v", err)
+	}
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if parsed.Scheme == "" || parsed.Host == "" || len(parts) < 3 || parts[len(parts)-3] != "hook" {
+		return nil, fmt.Errorf("invalid webhook URL %q, expected <api>/hook/<user>/<pin>", hook)
+	}
+	return &Client{URL: hook, HTTPClient: &http.Client{}, Concurrency: sdk.DefaultConcurrency}, nil
+}
+
+func (c *Client) Send(ctx context.Context, payload Payload) error {
+	if err := payload.Validate(); err != nil {
+		return err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if c.Limiter != nil {
+		if err := c.Limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d, %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+// SendAll posts every payload and returns one error per payload
+func (c *Client) SendAll(ctx context.Context, payloads []Payload) []error {
+	return sdk.ForEach(ctx, payloads, c.Concurrency, c.Send)
+}
+
+func (c *Client) SendAsset(ctx context.Context, asset model.Asset) error {
+	return c.Send(ctx, AssetPayload(asset))
+}
+
+func (c *Client) SendRisk(ctx context.Context, risk model.Risk) error {
+	return c.Send(ctx, RiskPayload(risk))
+}
+
+func (c *Client) SendAttribute(ctx context.Context, attribute model.Attribute) error {
+	payload, err := AttributePayload(attribute)
+	if err != nil {
+		return err
+	}
+	return c.Send(ctx, payload)
+}
+
+// ReadPayloads parses one JSON payload per line, skipping blank lines
+func ReadPayloads(r io.Reader) ([]Payload, error) {
+	var payloads []Payload
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var payload Payload
+		decoder := json.NewDecoder(strings.NewReader(text))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&payload); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if err := payload.Validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads, scanner.Err()
+}
@@ -0,0 +1,253 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk"
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+func TestPayloadEncoding(t *testing.T) {
+	asset := model.NewAsset("acme.com", "1.2.3.4")
+	risk := model.NewRisk(asset, "sql-injection")
+	risk.Status = model.OpenHigh
+	risk.Comment = "confirmed"
+
+	tests := []struct {
+		name    string
+		payload Payload
+		want    string
+	}{
+		{"asset", AssetPayload(asset), `{"dns":"acme.com","name":"1.2.3.4"}`},
+		// risks are reported on their domain
+		{"risk", RiskPayload(risk), `{"dns":"acme.com","name":"acme.com","finding":"sql-injection","status":"OH","comment":"confirmed"}`},
+		{"attribute", func() Payload {
+			payload, err := AttributePayload(model.NewAttribute("port", "443", asset.Key))
+			if err != nil {
+				t.Fatalf("AttributePayload: %v", err)
+			}
+			return payload
+		}(), `{"dns":"acme.com","name":"1.2.3.4","attribute":"port","value":"443"}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := json.Marshal(test.payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != test.want {
+				t.Errorf("payload = %s, want %s", data, test.want)
+			}
+		})
+	}
+
+	if _, err := AttributePayload(model.NewAttribute("https", "443", risk.Key)); err == nil {
+		t.Errorf("AttributePayload accepted an attribute of a risk")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload Payload
+		err     string
+	}{
+		{"asset", Payload{DNS: "acme.com", Name: "1.2.3.4"}, ""},
+		{"name defaults to dns", Payload{DNS: "acme.com"}, ""},
+		{"missing dns", Payload{Name: "1.2.3.4"}, "dns is required"},
+		{"attribute without value", Payload{DNS: "acme.com", Attribute: "port"}, "attribute and value must be set together"},
+		{"value without attribute", Payload{DNS: "acme.com", Value: "443"}, "attribute and value must be set together"},
+		{"finding and attribute", Payload{DNS: "acme.com", Finding: "sqli", Attribute: "port", Value: "443"}, "either a finding or an attribute"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.payload.Validate()
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				if test.payload.Name == "" {
+					t.Errorf("Validate left the name empty")
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("Validate() = %v, want %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{"https://api.chariot.praetorian.com/chariot/hook/dXNlcg/1234", true},
+		{"http://127.0.0.1:8080/hook/dXNlcg/1234", true},
+		{"https://api.chariot.praetorian.com/chariot/hook/dXNlcg", false},
+		{"https://api.chariot.praetorian.com/chariot/webhook/dXNlcg/1234", false},
+		{"/hook/dXNlcg/1234", false},
+		{"://bad", false},
+	}
+	for _, test := range tests {
+		client, err := New(test.url)
+		if (err == nil) != test.valid {
+			t.Errorf("New(%q) error = %v, want valid %v", test.url, err, test.valid)
+		}
+		if err == nil && client.Concurrency != sdk.DefaultConcurrency {
+			t.Errorf("New(%q) concurrency = %d", test.url, client.Concurrency)
+		}
+	}
+}
+
+// hook records the payloads posted to it, and answers payloads for the DNS
+// name rejected with 400
+type hook struct {
+	*httptest.Server
+	mu       sync.Mutex
+	payloads []Payload
+	types    []string
+}
+
+func newHook(t *testing.T) (*hook, *Client) {
+	h := &hook{}
+	h.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var payload Payload
+		if err := json.Unmarshal(data, &payload); err != nil || payload.DNS == "rejected" {
+			http.Error(w, `{"error":"bad payload"}`, http.StatusBadRequest)
+			return
+		}
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.payloads = append(h.payloads, payload)
+		h.types = append(h.types, r.Header.Get("Content-Type"))
+	}))
+	t.Cleanup(h.Close)
+	client, err := New(h.URL + "/hook/dXNlcg/1234")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return h, client
+}
+
+func TestSend(t *testing.T) {
+	h, client := newHook(t)
+	ctx := context.Background()
+	asset := model.NewAsset("acme.com", "1.2.3.4")
+	risk := model.NewRisk(asset, "sql-injection")
+	risk.Status = model.OpenHigh
+
+	if err := client.SendAsset(ctx, asset); err != nil {
+		t.Fatalf("SendAsset: %v", err)
+	}
+	if err := client.SendRisk(ctx, risk); err != nil {
+		t.Fatalf("SendRisk: %v", err)
+	}
+	if err := client.SendAttribute(ctx, model.NewAttribute("port", "443", asset.Key)); err != nil {
+		t.Fatalf("SendAttribute: %v", err)
+	}
+	want := []Payload{AssetPayload(asset), RiskPayload(risk), {DNS: "acme.com", Name: "1.2.3.4", Attribute: "port", Value: "443"}}
+	if len(h.payloads) != len(want) {
+		t.Fatalf("hook received %d payloads, want %d", len(h.payloads), len(want))
+	}
+	for i := range want {
+		if h.payloads[i] != want[i] {
+			t.Errorf("payload %d = %+v, want %+v", i, h.payloads[i], want[i])
+		}
+		if h.types[i] != "application/json" {
+			t.Errorf("payload %d was sent as %q", i, h.types[i])
+		}
+	}
+
+	err := client.Send(ctx, Payload{DNS: "rejected"})
+	if err == nil || !strings.Contains(err.Error(), "400") || !strings.Contains(err.Error(), "bad payload") {
+		t.Errorf("Send of a rejected payload = %v", err)
+	}
+	if err := client.Send(ctx, Payload{Name: "no-dns"}); err == nil {
+		t.Errorf("Send accepted a payload without dns")
+	}
+	if len(h.payloads) != len(want) {
+		t.Errorf("invalid payloads reached the hook")
+	}
+}
+
+func TestSendWaitsForTheLimiter(t *testing.T) {
+	h, client := newHook(t)
+	client.Limiter = sdk.NewRateLimiter(1, 1)
+	if err := client.Send(context.Background(), Payload{DNS: "acme.com"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	// the bucket is empty, so a canceled send never reaches the hook
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := client.Send(ctx, Payload{DNS: "acme.com"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Send() = %v, want %v", err, context.Canceled)
+	}
+	if len(h.payloads) != 1 {
+		t.Errorf("hook received %d payloads, want 1", len(h.payloads))
+	}
+}
+
+func TestSendAll(t *testing.T) {
+	h, client := newHook(t)
+	client.Concurrency = 3
+	payloads := []Payload{
+		{DNS: "a.acme.com"},
+		{DNS: "rejected"},
+		{DNS: "b.acme.com"},
+		{Name: "no-dns"},
+		{DNS: "c.acme.com", Attribute: "port", Value: "22"},
+	}
+
+	errs := client.SendAll(context.Background(), payloads)
+	if len(errs) != len(payloads) {
+		t.Fatalf("got %d errors, want %d", len(errs), len(payloads))
+	}
+	for i, err := range errs {
+		failed := i == 1 || i == 3
+		if (err != nil) != failed {
+			t.Errorf("payload %d: err = %v", i, err)
+		}
+	}
+	if len(h.payloads) != 3 {
+		t.Errorf("hook received %d payloads, want 3", len(h.payloads))
+	}
+}
+
+func TestReadPayloads(t *testing.T) {
+	payloads, err := ReadPayloads(strings.NewReader(`{"dns":"acme.com"}
+
+  {"dns":"acme.com","name":"1.2.3.4","attribute":"port","value":"443"}
+`))
+	if err != nil {
+		t.Fatalf("ReadPayloads: %v", err)
+	}
+	want := []Payload{{DNS: "acme.com", Name: "acme.com"}, {DNS: "acme.com", Name: "1.2.3.4", Attribute: "port", Value: "443"}}
+	if len(payloads) != len(want) || payloads[0] != want[0] || payloads[1] != want[1] {
+		t.Errorf("ReadPayloads() = %+v, want %+v", payloads, want)
+	}
+
+	tests := []struct {
+		name, input, err string
+	}{
+		{"unknown field", "{\"dns\":\"acme.com\"}\n{\"dns\":\"acme.com\",\"port\":443}", "line 2: json: unknown field"},
+		{"invalid payload", "\n\n{\"name\":\"1.2.3.4\"}", "line 3: dns is required"},
+		{"not json", "acme.com", "line 1:"},
+	}
+	for _, test := range tests {
+		if _, err := ReadPayloads(strings.NewReader(test.input)); err == nil || !strings.HasPrefix(err.Error(), test.err) {
+			t.Errorf("%s: ReadPayloads() = %v, want %q", test.name, err, test.err)
+		}
+	}
+}
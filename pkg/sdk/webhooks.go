@@ -0,0 +1,157 @@
+package sdk
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultWebhook is the name of the webhook created without a name
+const DefaultWebhook = "default"
+
+// previousWebhook suffixes the name of the webhook that keeps the old pin of a
+// rotated webhook working until its grace period ends
+const previousWebhook = ".previous"
+
+// Webhook is a hook URL stored as a "hook" member of the account, with the
+// webhook name as its value
+type Webhook struct {
+	Name    string
+	URL     string
+	Pin     string
+	Updated string
+	// Expires is set for the old pin of a webhook rotated with a grace period
+	Expires time.Time
+}
+
+func (s *AccountService) hookURL(pin string) string {
+	username := base64.StdEncoding.EncodeToString([]byte(s.Client.Keychain.Username))
+	encodedUsername := strings.TrimRight(username, "=")
+	return fmt.Sprintf("%s/hook/%s/%s", s.Client.API, encodedUsername, pin)
+}
+
+// the default webhook is stored without a value, as before webhooks had names
+func hookValue(name string) string {
+	if name == DefaultWebhook {
+		return ""
+	}
+	return name
+}
+
+func (s *AccountService) Webhooks() ([]Webhook, error) {
+	accounts, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	webhooks := make([]Webhook, 0)
+	for _, account := range accounts {
+		if account.Member != "hook" || account.Name != s.Client.Username {
+			continue
+		}
+		// the backend removes expired links lazily
+		if account.Expired(now) {
+			continue
+		}
+		webhook := Webhook{
+			Name:    account.Value,
+			Pin:     account.Config["pin"],
+			URL:     s.hookURL(account.Config["pin"]),
+			Updated: account.Updated,
+		}
+		webhook.Expires, _ = account.Expires()
+		if webhook.Name == "" {
+			webhook.Name = DefaultWebhook
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].Name < webhooks[j].Name })
+	return webhooks, nil
+}
+
+// GetWebhook returns the named webhook, or false when it does not exist
+func (s *AccountService) GetWebhook(name string) (Webhook, bool, error) {
+	webhooks, err := s.Webhooks()
+	if err != nil {
+		return Webhook{}, false, err
+	}
+	for _, webhook := range webhooks {
+		if webhook.Name == name {
+			return webhook, true, nil
+		}
+	}
+	return Webhook{}, false, nil
+}
+
+// Webhook returns the URL of the default webhook, or an empty string when
+// none has been generated
+func (s *AccountService) Webhook() (string, error) {
+	webhook, _, err := s.GetWebhook(DefaultWebhook)
+	return webhook.URL, err
+}
+
+// AddWebhook creates or replaces the default webhook
+func (s *AccountService) AddWebhook() (string, error) {
+	return s.AddNamedWebhook(DefaultWebhook)
+}
+
+// AddNamedWebhook creates the named webhook with a new pin, replacing any
+// existing webhook of that name immediately
+func (s *AccountService) AddNamedWebhook(name string) (string, error) {
+	webhook, err := s.linkWebhook(name)
+	return webhook.URL, err
+}
+
+func (s *AccountService) linkWebhook(name string) (Webhook, error) {
+	pin := uuid.New().String()
+	err := s.Link("hook", hookValue(name), map[string]interface{}{"pin": pin})
+	if err != nil {
+		return Webhook{}, err
+	}
+	return Webhook{Name: name, Pin: pin, URL: s.hookURL(pin)}, nil
+}
+
+// RotateWebhook gives an existing webhook a new pin. With a grace period the
+// old URL keeps working as <name>.previous until the period ends, otherwise it
+// stops working immediately.
+func (s *AccountService) RotateWebhook(name string, grace time.Duration) (Webhook, error) {
+	if strings.HasSuffix(name, previousWebhook) {
+		return Webhook{}, fmt.Errorf("webhook %s is the old pin of a rotated webhook and cannot be rotated", name)
+	}
+	current, ok, err := s.GetWebhook(name)
+	if err != nil {
+		return Webhook{}, err
+	}
+	if !ok {
+		return Webhook{}, fmt.Errorf("webhook %s does not exist", name)
+	}
+
+	if grace > 0 {
+		config := map[string]interface{}{"pin": current.Pin}
+		expires := time.Now().Add(grace).Unix()
+		if err := s.linkHelper("POST", "hook", name+previousWebhook, config, expires); err != nil {
+			return Webhook{}, err
+		}
+	}
+	return s.linkWebhook(name)
+}
+
+// RevokeWebhook deletes the named webhook, invalidating its URL and the old
+// URL of a rotation still in its grace period
+func (s *AccountService) RevokeWebhook(name string) error {
+	if err := s.Unlink("hook", hookValue(name)); err != nil {
+		return err
+	}
+	if strings.HasSuffix(name, previousWebhook) {
+		return nil
+	}
+	_, ok, err := s.GetWebhook(name + previousWebhook)
+	if err != nil || !ok {
+		return err
+	}
+	return s.Unlink("hook", name+previousWebhook)
+}
@@ -0,0 +1,83 @@
+package sdk_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/chariot-client/pkg/sdk/model"
+)
+
+func TestRotateWebhook(t *testing.T) {
+	tests := []struct {
+		name  string
+		grace time.Duration
+		// want are the names of the webhooks left after the rotation
+		want []string
+	}{
+		{"immediately", 0, []string{"github-actions"}},
+		{"with a grace period", 24 * time.Hour, []string{"github-actions", "github-actions.previous"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, _ := newTestClient(t)
+			old, err := client.Accounts.AddNamedWebhook("github-actions")
+			if err != nil {
+				t.Fatalf("AddNamedWebhook: %v", err)
+			}
+
+			rotated, err := client.Accounts.RotateWebhook("github-actions", test.grace)
+			if err != nil {
+				t.Fatalf("RotateWebhook: %v", err)
+			}
+			if rotated.URL == old {
+				t.Fatalf("RotateWebhook kept the pin")
+			}
+
+			webhooks, err := client.Accounts.Webhooks()
+			if err != nil {
+				t.Fatalf("Webhooks: %v", err)
+			}
+			if len(webhooks) != len(test.want) {
+				t.Fatalf("got %d webhooks, want %v", len(webhooks), test.want)
+			}
+			for i, webhook := range webhooks {
+				if webhook.Name != test.want[i] {
+					t.Errorf("webhook %d is %s, want %s", i, webhook.Name, test.want[i])
+				}
+			}
+			if test.grace == 0 {
+				return
+			}
+
+			previous := webhooks[1]
+			if previous.URL != old {
+				t.Errorf("previous URL = %s, want %s", previous.URL, old)
+			}
+			if until := time.Until(previous.Expires); until <= 0 || until > test.grace {
+				t.Errorf("previous URL expires in %s, want within %s", until, test.grace)
+			}
+
+			if err := client.Accounts.RevokeWebhook("github-actions"); err != nil {
+				t.Fatalf("RevokeWebhook: %v", err)
+			}
+			if webhooks, _ := client.Accounts.Webhooks(); len(webhooks) != 0 {
+				t.Errorf("revoking left %d webhooks", len(webhooks))
+			}
+		})
+	}
+}
+
+func TestWebhooksSkipsExpired(t *testing.T) {
+	client, server := newTestClient(t)
+	expired := model.NewAccount(server.Username, "hook", "github-actions.previous", map[string]string{"pin": "old"})
+	expired.TTL = time.Now().Add(-time.Minute).Unix()
+	server.Seed("", expired)
+
+	webhooks, err := client.Accounts.Webhooks()
+	if err != nil {
+		t.Fatalf("Webhooks: %v", err)
+	}
+	if len(webhooks) != 0 {
+		t.Errorf("Webhooks() listed %d expired webhooks", len(webhooks))
+	}
+}